@@ -165,6 +165,10 @@ func (gc *garbageCollector) scan() {
 		for i, infoKey := range infoKeys {
 			total++
 			_, has := filesMap[infoKey]
+			// checksum sidecars live as long as their blobs
+			if blobKey, ok := storage.ParseBlobChecksumKey(infoKey); ok {
+				_, has = filesMap[blobKey]
+			}
 			if has {
 				valid++
 				continue
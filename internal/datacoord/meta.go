@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math"
 	"path"
+	"sort"
 	"sync"
 	"time"
 
@@ -1274,6 +1275,56 @@ func (m *meta) UpdateChannelCheckpoint(vChannel string, pos *msgpb.MsgPosition)
 	return nil
 }
 
+// UpdateChannelCheckpoints updates and saves the checkpoints of several channels atomically,
+// positions are keyed by their channel names. Positions not newer than the saved ones are skipped.
+func (m *meta) UpdateChannelCheckpoints(positions []*msgpb.MsgPosition) error {
+	latest := make(map[string]*msgpb.MsgPosition, len(positions))
+	for _, pos := range positions {
+		if pos == nil || pos.GetMsgID() == nil {
+			return fmt.Errorf("channelCP is nil, vChannel=%s", pos.GetChannelName())
+		}
+		if old, ok := latest[pos.GetChannelName()]; !ok || old.GetTimestamp() < pos.GetTimestamp() {
+			latest[pos.GetChannelName()] = pos
+		}
+	}
+	channels := lo.Keys(latest)
+	// lock in order to avoid deadlock with concurrent batches
+	sort.Strings(channels)
+	for _, vChannel := range channels {
+		m.channelCPLocks.Lock(vChannel)
+	}
+	defer func() {
+		for _, vChannel := range channels {
+			m.channelCPLocks.Unlock(vChannel)
+		}
+	}()
+
+	toSave := make([]*msgpb.MsgPosition, 0, len(channels))
+	for _, vChannel := range channels {
+		pos := latest[vChannel]
+		oldPosition, ok := m.channelCPs.Get(vChannel)
+		if !ok || oldPosition.Timestamp < pos.Timestamp {
+			toSave = append(toSave, pos)
+		}
+	}
+	if len(toSave) == 0 {
+		return nil
+	}
+	if err := m.catalog.SaveChannelCheckpoints(m.ctx, toSave); err != nil {
+		return err
+	}
+	for _, pos := range toSave {
+		m.channelCPs.Insert(pos.GetChannelName(), pos)
+		ts, _ := tsoutil.ParseTS(pos.Timestamp)
+		metrics.DataCoordCheckpointLag.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), pos.GetChannelName()).
+			Set(float64(time.Since(ts).Milliseconds()))
+	}
+	log.Info("UpdateChannelCheckpoints done", zap.Strings("vChannels", lo.Map(toSave, func(pos *msgpb.MsgPosition, _ int) string {
+		return pos.GetChannelName()
+	})))
+	return nil
+}
+
 func (m *meta) GetChannelCheckpoint(vChannel string) *msgpb.MsgPosition {
 	m.channelCPLocks.Lock(vChannel)
 	defer m.channelCPLocks.Unlock(vChannel)
@@ -1014,6 +1014,31 @@ func TestChannelCP(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("UpdateChannelCheckpoints", func(t *testing.T) {
+		meta, err := newMemoryMeta()
+		assert.NoError(t, err)
+
+		// nil position
+		err = meta.UpdateChannelCheckpoints([]*msgpb.MsgPosition{nil})
+		assert.Error(t, err)
+
+		err = meta.UpdateChannelCheckpoints([]*msgpb.MsgPosition{
+			{ChannelName: mockVChannel, MsgID: pos.GetMsgID(), Timestamp: 1000},
+			{ChannelName: mockVChannel, MsgID: pos.GetMsgID(), Timestamp: 2000},
+			{ChannelName: mockVChannel + "_1", MsgID: pos.GetMsgID(), Timestamp: 1000},
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2000, meta.GetChannelCheckpoint(mockVChannel).GetTimestamp())
+		assert.EqualValues(t, 1000, meta.GetChannelCheckpoint(mockVChannel+"_1").GetTimestamp())
+
+		// regressed position is skipped
+		err = meta.UpdateChannelCheckpoints([]*msgpb.MsgPosition{
+			{ChannelName: mockVChannel, MsgID: pos.GetMsgID(), Timestamp: 1500},
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2000, meta.GetChannelCheckpoint(mockVChannel).GetTimestamp())
+	})
+
 	t.Run("GetChannelCheckpoint", func(t *testing.T) {
 		meta, err := newMemoryMeta()
 		assert.NoError(t, err)
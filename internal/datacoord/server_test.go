@@ -4197,6 +4197,43 @@ func TestDataCoordServer_UpdateChannelCheckpoint(t *testing.T) {
 		assert.NoError(t, err)
 		assert.EqualValues(t, commonpb.ErrorCode_UnexpectedError, resp.ErrorCode)
 	})
+
+	t.Run("UpdateChannelCheckpoints", func(t *testing.T) {
+		svr := newTestServer(t, nil)
+		defer closeTestServer(t, svr)
+
+		req := &datapb.UpdateChannelCheckpointRequest{
+			Base: &commonpb.MsgBase{
+				SourceID: paramtable.GetNodeID(),
+			},
+			ChannelCheckpoints: []*msgpb.MsgPosition{
+				{
+					ChannelName: mockVChannel,
+					Timestamp:   1000,
+					MsgID:       []byte{0, 0, 0, 0, 0, 0, 0, 0},
+				},
+				{
+					ChannelName: mockVChannel + "_1",
+					Timestamp:   2000,
+					MsgID:       []byte{0, 0, 0, 0, 0, 0, 0, 0},
+				},
+			},
+		}
+
+		resp, err := svr.UpdateChannelCheckpoint(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.EqualValues(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+		assert.EqualValues(t, 1000, svr.meta.GetChannelCheckpoint(mockVChannel).GetTimestamp())
+		assert.EqualValues(t, 2000, svr.meta.GetChannelCheckpoint(mockVChannel+"_1").GetTimestamp())
+
+		// the batch is rejected as a whole if any position is invalid
+		req.ChannelCheckpoints[0].Timestamp = 3000
+		req.ChannelCheckpoints[1] = &msgpb.MsgPosition{ChannelName: mockVChannel + "_1"}
+		resp, err = svr.UpdateChannelCheckpoint(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.EqualValues(t, commonpb.ErrorCode_UnexpectedError, resp.ErrorCode)
+		assert.EqualValues(t, 1000, svr.meta.GetChannelCheckpoint(mockVChannel).GetTimestamp())
+	})
 }
 
 var globalTestTikv = tikv.SetupLocalTxn()
@@ -1400,6 +1400,16 @@ func (s *Server) UpdateChannelCheckpoint(ctx context.Context, req *datapb.Update
 		return merr.Status(err), nil
 	}
 
+	// channel checkpoints in batch are saved atomically, so the status applies to every channel of the request
+	if len(req.GetChannelCheckpoints()) > 0 {
+		err := s.meta.UpdateChannelCheckpoints(req.GetChannelCheckpoints())
+		if err != nil {
+			log.Warn("failed to UpdateChannelCheckpoints", zap.Int("channelNum", len(req.GetChannelCheckpoints())), zap.Error(err))
+			return merr.Status(err), nil
+		}
+		return merr.Success(), nil
+	}
+
 	err := s.meta.UpdateChannelCheckpoint(req.GetVChannel(), req.GetPosition())
 	if err != nil {
 		log.Warn("failed to UpdateChannelCheckpoint", zap.String("vChannel", req.GetVChannel()), zap.Error(err))
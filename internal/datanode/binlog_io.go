@@ -95,7 +95,7 @@ func (b *binlogIO) download(ctx context.Context, paths []string) ([]*Blob, error
 					vs, err = b.Read(ctx, localPath)
 				}
 			}
-			return vs, nil
+			return storage.DecodeBlob(ctx, b.ChunkManager, localPath, vs)
 		})
 		futures[i] = future
 	}
@@ -19,6 +19,7 @@ package datanode
 import (
 	"context"
 	"fmt"
+	"io"
 	"path"
 	"testing"
 	"time"
@@ -381,6 +382,10 @@ func (mk *mockCm) MultiWrite(ctx context.Context, contents map[string][]byte) er
 	return nil
 }
 
+func (mk *mockCm) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	return nil
+}
+
 func (mk *mockCm) Read(ctx context.Context, filePath string) ([]byte, error) {
 	if mk.errRead {
 		return nil, errors.New("mockKv read error")
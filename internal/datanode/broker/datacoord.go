@@ -104,6 +104,25 @@ func (dc *dataCoordBroker) UpdateChannelCheckpoint(ctx context.Context, channelN
 	return nil
 }
 
+// UpdateChannelCheckpoints updates the checkpoints of several channels in a single request,
+// positions are keyed by their channel name. The checkpoints are saved atomically,
+// so the returned error applies to every channel.
+func (dc *dataCoordBroker) UpdateChannelCheckpoints(ctx context.Context, cps []*msgpb.MsgPosition) error {
+	req := &datapb.UpdateChannelCheckpointRequest{
+		Base: commonpbutil.NewMsgBase(
+			commonpbutil.WithSourceID(paramtable.GetNodeID()),
+		),
+		ChannelCheckpoints: cps,
+	}
+
+	resp, err := dc.client.UpdateChannelCheckpoint(ctx, req)
+	if err := merr.CheckRPCCall(resp, err); err != nil {
+		log.Ctx(ctx).Warn("failed to update channel checkpoints", zap.Int("channelNum", len(cps)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (dc *dataCoordBroker) SaveBinlogPaths(ctx context.Context, req *datapb.SaveBinlogPathsRequest) error {
 	log := log.Ctx(ctx)
 
@@ -210,6 +210,37 @@ func (s *dataCoordSuite) TestUpdateChannelCheckpoint() {
 	})
 }
 
+func (s *dataCoordSuite) TestUpdateChannelCheckpoints() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checkpoints := []*msgpb.MsgPosition{
+		{ChannelName: "dml_0", Timestamp: tsoutil.ComposeTSByTime(time.Now(), 0)},
+		{ChannelName: "dml_1", Timestamp: tsoutil.ComposeTSByTime(time.Now(), 1)},
+	}
+
+	s.Run("normal_case", func() {
+		s.dc.EXPECT().UpdateChannelCheckpoint(mock.Anything, mock.Anything).
+			Run(func(_ context.Context, req *datapb.UpdateChannelCheckpointRequest, _ ...grpc.CallOption) {
+				s.Equal(checkpoints, req.GetChannelCheckpoints())
+			}).
+			Return(merr.Status(nil), nil).Once()
+
+		err := s.broker.UpdateChannelCheckpoints(ctx, checkpoints)
+		s.NoError(err)
+		s.resetMock()
+	})
+
+	s.Run("datacoord_return_error", func() {
+		s.dc.EXPECT().UpdateChannelCheckpoint(mock.Anything, mock.Anything).
+			Return(nil, errors.New("mock")).Once()
+
+		err := s.broker.UpdateChannelCheckpoints(ctx, checkpoints)
+		s.Error(err)
+		s.resetMock()
+	})
+}
+
 func (s *dataCoordSuite) TestSaveBinlogPaths() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
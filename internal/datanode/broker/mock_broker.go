@@ -595,6 +595,49 @@ func (_c *MockBroker_UpdateChannelCheckpoint_Call) RunAndReturn(run func(context
 	return _c
 }
 
+// UpdateChannelCheckpoints provides a mock function with given fields: ctx, cps
+func (_m *MockBroker) UpdateChannelCheckpoints(ctx context.Context, cps []*msgpb.MsgPosition) error {
+	ret := _m.Called(ctx, cps)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*msgpb.MsgPosition) error); ok {
+		r0 = rf(ctx, cps)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBroker_UpdateChannelCheckpoints_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateChannelCheckpoints'
+type MockBroker_UpdateChannelCheckpoints_Call struct {
+	*mock.Call
+}
+
+// UpdateChannelCheckpoints is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cps []*msgpb.MsgPosition
+func (_e *MockBroker_Expecter) UpdateChannelCheckpoints(ctx interface{}, cps interface{}) *MockBroker_UpdateChannelCheckpoints_Call {
+	return &MockBroker_UpdateChannelCheckpoints_Call{Call: _e.mock.On("UpdateChannelCheckpoints", ctx, cps)}
+}
+
+func (_c *MockBroker_UpdateChannelCheckpoints_Call) Run(run func(ctx context.Context, cps []*msgpb.MsgPosition)) *MockBroker_UpdateChannelCheckpoints_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]*msgpb.MsgPosition))
+	})
+	return _c
+}
+
+func (_c *MockBroker_UpdateChannelCheckpoints_Call) Return(_a0 error) *MockBroker_UpdateChannelCheckpoints_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBroker_UpdateChannelCheckpoints_Call) RunAndReturn(run func(context.Context, []*msgpb.MsgPosition) error) *MockBroker_UpdateChannelCheckpoints_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateSegmentStatistics provides a mock function with given fields: ctx, req
 func (_m *MockBroker) UpdateSegmentStatistics(ctx context.Context, req *datapb.UpdateSegmentStatisticsRequest) error {
 	ret := _m.Called(ctx, req)
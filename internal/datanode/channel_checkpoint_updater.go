@@ -18,9 +18,17 @@ package datanode
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
 	"github.com/milvus-io/milvus-proto/go-api/v2/msgpb"
+	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/metrics"
 	"github.com/milvus-io/milvus/pkg/util/conc"
 	"github.com/milvus-io/milvus/pkg/util/paramtable"
 )
@@ -28,12 +36,32 @@ import (
 const (
 	updateChanCPInterval           = 1 * time.Minute
 	updateChanCPTimeout            = 10 * time.Second
+	updateChanCPBatchWindow        = 10 * time.Millisecond
 	defaultUpdateChanCPMaxParallel = 1000
+	// updateChanCPMaxBatchSize bounds the channels saved by one request, which datacoord persists in one etcd transaction
+	updateChanCPMaxBatchSize = 128
 )
 
+// channelCPRequest is a channel checkpoint update waiting to be persisted in batch.
+type channelCPRequest struct {
+	pos      *msgpb.MsgPosition
+	callback func() error
+	done     func()
+}
+
 type channelCheckpointUpdater struct {
 	dn         *DataNode
 	workerPool *conc.Pool[any]
+
+	// batchWindow is how long enqueued updates are collected before being persisted together
+	batchWindow time.Duration
+	mu          sync.Mutex
+	pending     []*channelCPRequest
+	timer       *time.Timer
+	// closed is set by close, updates enqueued afterwards are persisted synchronously
+	closed bool
+	// flushing tracks the batches being submitted to workerPool, close waits for them before releasing it
+	flushing sync.WaitGroup
 }
 
 func newChannelCheckpointUpdater(dn *DataNode) *channelCheckpointUpdater {
@@ -42,25 +70,145 @@ func newChannelCheckpointUpdater(dn *DataNode) *channelCheckpointUpdater {
 		updateChanCPMaxParallel = defaultUpdateChanCPMaxParallel
 	}
 	return &channelCheckpointUpdater{
-		dn:         dn,
-		workerPool: conc.NewPool[any](updateChanCPMaxParallel, conc.WithPreAlloc(true)),
+		dn:          dn,
+		workerPool:  conc.NewPool[any](updateChanCPMaxParallel, conc.WithPreAlloc(true)),
+		batchWindow: updateChanCPBatchWindow,
 	}
 }
 
-func (ccu *channelCheckpointUpdater) updateChannelCP(channelPos *msgpb.MsgPosition, callback func() error) error {
-	ccu.workerPool.Submit(func() (any, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), updateChanCPTimeout)
+// updateChannelCP persists channelPos asynchronously and returns the future of the update, callback is invoked
+// after success, done is invoked after the update finishes no matter whether it succeeds.
+// The update is aborted once ctx is done.
+func (ccu *channelCheckpointUpdater) updateChannelCP(ctx context.Context, channelPos *msgpb.MsgPosition, callback func() error, done func()) *conc.Future[any] {
+	return ccu.workerPool.Submit(func() (any, error) {
+		if done != nil {
+			defer done()
+		}
+		ctx, cancel := context.WithTimeout(ctx, updateChanCPTimeout)
 		defer cancel()
 		err := ccu.dn.broker.UpdateChannelCheckpoint(ctx, channelPos.GetChannelName(), channelPos)
 		if err != nil {
+			metrics.DataNodeUpdateChannelCheckpointCount.WithLabelValues(
+				fmt.Sprint(paramtable.GetNodeID()), channelPos.GetChannelName(), metrics.FailLabel).Inc()
 			return nil, err
 		}
 		err = callback()
 		return nil, err
 	})
-	return nil
 }
 
+// enqueueChannelCP persists channelPos together with the other updates enqueued within the batch window,
+// only the latest position of each channel is saved, in a single broker call.
+// callback is invoked after success and done after the batch finishes, like updateChannelCP.
+// Updates enqueued after close are persisted synchronously since there is no worker left.
+func (ccu *channelCheckpointUpdater) enqueueChannelCP(channelPos *msgpb.MsgPosition, callback func() error, done func()) {
+	req := &channelCPRequest{pos: channelPos, callback: callback, done: done}
+	ccu.mu.Lock()
+	if ccu.closed {
+		ccu.mu.Unlock()
+		ccu.persistBatch([]*channelCPRequest{req})
+		return
+	}
+	defer ccu.mu.Unlock()
+	ccu.pending = append(ccu.pending, req)
+	if ccu.timer == nil {
+		ccu.timer = time.AfterFunc(ccu.batchWindow, ccu.flushBatch)
+	}
+}
+
+func (ccu *channelCheckpointUpdater) flushBatch() {
+	ccu.mu.Lock()
+	requests := ccu.pending
+	ccu.pending = nil
+	ccu.timer = nil
+	if len(requests) == 0 {
+		ccu.mu.Unlock()
+		return
+	}
+	ccu.flushing.Add(1)
+	ccu.mu.Unlock()
+	defer ccu.flushing.Done()
+
+	ccu.workerPool.Submit(func() (any, error) {
+		ccu.persistBatch(requests)
+		return nil, nil
+	})
+}
+
+// persistBatch saves the latest position of each channel in requests, at most updateChanCPMaxBatchSize channels
+// per broker call and each call with its own timeout. If a batch call fails, e.g. rejected by a datacoord
+// which predates batch update, its channels are saved one by one instead.
+// The result is reported per channel: callbacks of the channels saved are invoked in enqueue order,
+// so positions of the same channel are reported ascending, while the channels not saved are counted as failed.
+func (ccu *channelCheckpointUpdater) persistBatch(requests []*channelCPRequest) {
+	latest := make(map[string]*msgpb.MsgPosition)
+	for _, req := range requests {
+		channel := req.pos.GetChannelName()
+		if pos, ok := latest[channel]; !ok || req.pos.GetTimestamp() > pos.GetTimestamp() {
+			latest[channel] = req.pos
+		}
+	}
+	positions := lo.Values(latest)
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].GetChannelName() < positions[j].GetChannelName()
+	})
+
+	failed := make(map[string]error)
+	for _, chunk := range lo.Chunk(positions, updateChanCPMaxBatchSize) {
+		ctx, cancel := context.WithTimeout(context.Background(), updateChanCPTimeout)
+		err := ccu.dn.broker.UpdateChannelCheckpoints(ctx, chunk)
+		cancel()
+		if err != nil {
+			log.Warn("failed to update channel checkpoints in batch, fallback to update one by one",
+				zap.Int("channelNum", len(chunk)), zap.Error(err))
+			for channel, err := range ccu.persistOneByOne(chunk) {
+				failed[channel] = err
+			}
+		}
+	}
+
+	for _, req := range requests {
+		if _, ok := failed[req.pos.GetChannelName()]; ok {
+			metrics.DataNodeUpdateChannelCheckpointCount.WithLabelValues(
+				fmt.Sprint(paramtable.GetNodeID()), req.pos.GetChannelName(), metrics.FailLabel).Inc()
+		} else if err := req.callback(); err != nil {
+			log.Warn("channel checkpoint callback failed", zap.String("channel", req.pos.GetChannelName()), zap.Error(err))
+		}
+		if req.done != nil {
+			req.done()
+		}
+	}
+}
+
+// persistOneByOne saves positions with a broker call per channel, sharing one timeout,
+// it returns the error of each channel failed.
+func (ccu *channelCheckpointUpdater) persistOneByOne(positions []*msgpb.MsgPosition) map[string]error {
+	ctx, cancel := context.WithTimeout(context.Background(), updateChanCPTimeout)
+	defer cancel()
+	failed := make(map[string]error)
+	for _, pos := range positions {
+		if err := ccu.dn.broker.UpdateChannelCheckpoint(ctx, pos.GetChannelName(), pos); err != nil {
+			failed[pos.GetChannelName()] = err
+		}
+	}
+	return failed
+}
+
+// close persists the updates still waiting in the batch window and then releases the workers.
 func (ccu *channelCheckpointUpdater) close() {
+	ccu.mu.Lock()
+	ccu.closed = true
+	if ccu.timer != nil {
+		ccu.timer.Stop()
+		ccu.timer = nil
+	}
+	requests := ccu.pending
+	ccu.pending = nil
+	ccu.mu.Unlock()
+	if len(requests) > 0 {
+		ccu.persistBatch(requests)
+	}
+	// batches taken by a fired timer are submitted before the workers are released
+	ccu.flushing.Wait()
 	ccu.workerPool.Release()
 }
@@ -0,0 +1,202 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/atomic"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/msgpb"
+	"github.com/milvus-io/milvus/internal/datanode/broker"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
+)
+
+func TestChannelCheckpointUpdater_Batch(t *testing.T) {
+	paramtable.Init()
+	mockBroker := broker.NewMockBroker(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	cpUpdater.batchWindow = 200 * time.Millisecond
+	defer cpUpdater.close()
+
+	const channelNum, updateNum = 100, 5
+	now := time.Now()
+	positions := make([][]*msgpb.MsgPosition, channelNum)
+	latest := make([]*msgpb.MsgPosition, 0, channelNum)
+	for i := range positions {
+		channel := fmt.Sprintf("by-dev-rootcoord-dml_%d_100v0", i)
+		for j := 0; j < updateNum; j++ {
+			positions[i] = append(positions[i], &msgpb.MsgPosition{ChannelName: channel, Timestamp: tsoutil.ComposeTSByTime(now, int64(j))})
+		}
+		latest = append(latest, positions[i][updateNum-1])
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].GetChannelName() < latest[j].GetChannelName()
+	})
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, latest).Return(nil).Once()
+
+	persisted := atomic.NewInt32(0)
+	var done sync.WaitGroup
+	done.Add(channelNum * updateNum)
+	var wg sync.WaitGroup
+	for i := range positions {
+		wg.Add(1)
+		go func(channelPositions []*msgpb.MsgPosition) {
+			defer wg.Done()
+			for _, pos := range channelPositions {
+				cpUpdater.enqueueChannelCP(pos, func() error {
+					persisted.Inc()
+					return nil
+				}, done.Done)
+			}
+		}(positions[i])
+	}
+	wg.Wait()
+	done.Wait()
+	assert.EqualValues(t, channelNum*updateNum, persisted.Load())
+	mockBroker.AssertNumberOfCalls(t, "UpdateChannelCheckpoints", 1)
+
+	// callbacks are skipped if both the batch and the fallback fail
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, latest[:1]).Return(fmt.Errorf("mock error")).Once()
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, latest[0].GetChannelName(), latest[0]).Return(fmt.Errorf("mock error")).Once()
+	finished := make(chan struct{})
+	cpUpdater.enqueueChannelCP(latest[0], func() error {
+		persisted.Inc()
+		return nil
+	}, func() { close(finished) })
+	<-finished
+	assert.EqualValues(t, channelNum*updateNum, persisted.Load())
+}
+
+func TestChannelCheckpointUpdater_BatchPerChannel(t *testing.T) {
+	paramtable.Init()
+	mockBroker := broker.NewMockBroker(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+
+	const channelNum = updateChanCPMaxBatchSize + 1
+	requests := make([]*channelCPRequest, 0, channelNum)
+	persisted := make(map[string]bool)
+	done := atomic.NewInt32(0)
+	for i := 0; i < channelNum; i++ {
+		pos := &msgpb.MsgPosition{ChannelName: fmt.Sprintf("by-dev-rootcoord-dml_%03d_100v0", i), Timestamp: 100}
+		requests = append(requests, &channelCPRequest{
+			pos: pos,
+			callback: func() error {
+				persisted[pos.GetChannelName()] = true
+				return nil
+			},
+			done: func() { done.Inc() },
+		})
+	}
+	// the channels are split into two calls, the failure of the second one only fails its own channel
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, cps []*msgpb.MsgPosition) error {
+			if len(cps) == 1 {
+				return fmt.Errorf("mock error")
+			}
+			assert.Equal(t, updateChanCPMaxBatchSize, len(cps))
+			return nil
+		}).Twice()
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, requests[channelNum-1].pos.GetChannelName(), requests[channelNum-1].pos).
+		Return(fmt.Errorf("mock error")).Once()
+
+	cpUpdater.persistBatch(requests)
+	assert.EqualValues(t, channelNum, done.Load())
+	assert.Equal(t, updateChanCPMaxBatchSize, len(persisted))
+	assert.False(t, persisted[requests[channelNum-1].pos.GetChannelName()])
+}
+
+func TestChannelCheckpointUpdater_BatchFallback(t *testing.T) {
+	paramtable.Init()
+	mockBroker := broker.NewMockBroker(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+
+	// datacoord before batch update rejects the request without position
+	positions := []*msgpb.MsgPosition{
+		{ChannelName: "by-dev-rootcoord-dml_0_100v0", Timestamp: 100},
+		{ChannelName: "by-dev-rootcoord-dml_1_100v0", Timestamp: 100},
+	}
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, positions).Return(fmt.Errorf("channelCP is nil")).Once()
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, positions[0].GetChannelName(), positions[0]).Return(nil).Once()
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, positions[1].GetChannelName(), positions[1]).Return(fmt.Errorf("mock error")).Once()
+
+	persisted := make(map[string]bool)
+	requests := lo.Map(positions, func(pos *msgpb.MsgPosition, _ int) *channelCPRequest {
+		return &channelCPRequest{pos: pos, callback: func() error {
+			persisted[pos.GetChannelName()] = true
+			return nil
+		}}
+	})
+	cpUpdater.persistBatch(requests)
+	assert.Equal(t, map[string]bool{positions[0].GetChannelName(): true}, persisted)
+}
+
+func TestChannelCheckpointUpdater_CloseFlush(t *testing.T) {
+	paramtable.Init()
+	mockBroker := broker.NewMockBroker(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	cpUpdater.batchWindow = time.Hour
+
+	pos := &msgpb.MsgPosition{ChannelName: "by-dev-rootcoord-dml_0_100v0", Timestamp: 100}
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).Return(nil).Once()
+	persisted := atomic.NewBool(false)
+	finished := atomic.NewBool(false)
+	cpUpdater.enqueueChannelCP(pos, func() error {
+		persisted.Store(true)
+		return nil
+	}, func() { finished.Store(true) })
+
+	// the pending update is persisted by close rather than dropped
+	cpUpdater.close()
+	assert.True(t, persisted.Load())
+	assert.True(t, finished.Load())
+}
+
+func TestChannelCheckpointUpdater_EnqueueAfterClose(t *testing.T) {
+	paramtable.Init()
+	mockBroker := broker.NewMockBroker(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	cpUpdater.close()
+
+	pos := &msgpb.MsgPosition{ChannelName: "by-dev-rootcoord-dml_0_100v0", Timestamp: 100}
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).Return(nil).Once()
+	persisted := atomic.NewBool(false)
+	finished := atomic.NewBool(false)
+	cpUpdater.enqueueChannelCP(pos, func() error {
+		persisted.Store(true)
+		return nil
+	}, func() { finished.Store(true) })
+
+	// the update is persisted synchronously instead of waiting for a released worker
+	assert.True(t, persisted.Load())
+	assert.True(t, finished.Load())
+	cpUpdater.mu.Lock()
+	defer cpUpdater.mu.Unlock()
+	assert.Empty(t, cpUpdater.pending)
+	assert.Nil(t, cpUpdater.timer)
+}
@@ -273,14 +273,28 @@ func (node *DataNode) Init() error {
 		}
 
 		node.chunkManager = chunkManager
+		blobCodec, err := storage.ParseBlobCodec(paramtable.Get().DataNodeCfg.SyncBlobCodec.GetValue())
+		if err != nil {
+			initError = err
+			log.Error("invalid sync blob codec", zap.Error(err))
+			return
+		}
 		syncMgr, err := syncmgr.NewSyncManager(paramtable.Get().DataNodeCfg.MaxParallelSyncTaskNum.GetAsInt(),
-			node.chunkManager, node.allocator)
+			node.chunkManager, node.allocator,
+			syncmgr.WithScheduledFlush(paramtable.Get().DataNodeCfg.ScheduledFlushInterval.GetAsDuration(time.Second),
+				paramtable.Get().DataNodeCfg.SyncPeriod.GetAsDuration(time.Second)),
+			syncmgr.WithMultipartThreshold(paramtable.Get().DataNodeCfg.MultipartUploadThreshold.GetAsInt64()),
+			syncmgr.WithBlobCodec(blobCodec),
+			syncmgr.WithBlobChecksum(paramtable.Get().DataNodeCfg.SyncBlobChecksum.GetAsBool()),
+			syncmgr.WithSerializeParallelism(paramtable.Get().DataNodeCfg.SyncSerializeParallelism.GetAsInt()),
+			syncmgr.WithTaskDedup(paramtable.Get().DataNodeCfg.SyncTaskDedup.GetAsBool()))
 		if err != nil {
 			initError = err
 			log.Error("failed to create sync manager", zap.Error(err))
 			return
 		}
 		node.syncMgr = syncMgr
+		storage.SetBlobChecksumVerification(paramtable.Get().DataNodeCfg.SyncBlobChecksum.GetAsBool())
 
 		node.writeBufferManager = writebuffer.NewManager(syncMgr)
 
@@ -425,6 +439,15 @@ func (node *DataNode) Stop() error {
 			return true
 		})
 
+		// wait in-flight syncs before closing the allocator they depend on
+		if node.syncMgr != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), paramtable.Get().DataNodeCfg.SyncDrainTimeout.GetAsDuration(time.Second))
+			if err := node.syncMgr.Close(ctx); err != nil {
+				log.Warn("failed to drain sync tasks on stop", zap.Error(err))
+			}
+			cancel()
+		}
+
 		if node.allocator != nil {
 			log.Info("close id allocator", zap.String("role", typeutil.DataNodeRole))
 			node.allocator.Close()
@@ -84,6 +84,12 @@ type nodeConfig struct {
 	metacache    metacache.MetaCache
 	allocator    allocator.Allocator
 	serverID     UniqueID
+	// forceUpdateTimeout is the deadline of persisting the channel checkpoint when the flowgraph closes,
+	// zero means DataNodeCfg.CloseFlushTimeout.
+	forceUpdateTimeout time.Duration
+	// onCPAdvanced is invoked after the channel checkpoint is persisted with a timestamp
+	// greater than all persisted before, optional.
+	onCPAdvanced func(channel string, ts uint64)
 }
 
 // start the flow graph in dataSyncService
@@ -120,6 +126,7 @@ func (dsService *dataSyncService) close() {
 		}
 
 		dsService.cancelFn()
+		metrics.CleanupDataNodeChannelMetrics(paramtable.GetNodeID(), dsService.vchannelName)
 
 		log.Info("dataSyncService closed")
 	})
@@ -291,7 +298,7 @@ func loadStats(ctx context.Context, chunkManager storage.ChunkManager, schema *s
 	}
 
 	// read historical PK filter
-	values, err := chunkManager.MultiRead(ctx, bloomFilterFiles)
+	values, err := storage.ReadBlobs(ctx, chunkManager, bloomFilterFiles)
 	if err != nil {
 		log.Warn("failed to load bloom filter files", zap.Error(err))
 		return nil, err
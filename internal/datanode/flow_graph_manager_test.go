@@ -60,6 +60,7 @@ func TestFlowGraphManager(t *testing.T) {
 	broker.EXPECT().GetSegmentInfo(mock.Anything, mock.Anything).Return([]*datapb.SegmentInfo{}, nil).Maybe()
 	broker.EXPECT().DropVirtualChannel(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 	broker.EXPECT().UpdateChannelCheckpoint(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	broker.EXPECT().UpdateChannelCheckpoints(mock.Anything, mock.Anything).Return(nil).Maybe()
 	broker.EXPECT().DescribeCollection(mock.Anything, mock.Anything, mock.Anything).
 		Return(&milvuspb.DescribeCollectionResponse{
 			Status:         merr.Status(nil),
@@ -17,6 +17,7 @@
 package datanode
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"time"
@@ -29,6 +30,9 @@ import (
 	"github.com/milvus-io/milvus/internal/datanode/writebuffer"
 	"github.com/milvus-io/milvus/internal/util/flowgraph"
 	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/metrics"
+	"github.com/milvus-io/milvus/pkg/util/conc"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
 	"github.com/milvus-io/milvus/pkg/util/tsoutil"
 )
 
@@ -42,6 +46,16 @@ type ttNode struct {
 	writeBufferManager writebuffer.BufferManager
 	lastUpdateTime     *atomic.Time
 	cpUpdater          *channelCheckpointUpdater
+	closeMsgCount      *atomic.Int64
+	// closeCPPersisted records whether the final checkpoint is persisted when the flowgraph closes
+	closeCPPersisted *atomic.Bool
+	// pendingUpdates is the number of submitted channel checkpoint updates not finished yet
+	pendingUpdates *atomic.Int64
+	// forceUpdateTimeout is the deadline of persisting the checkpoint on close, see nodeConfig
+	forceUpdateTimeout time.Duration
+	// cpTs is the largest channel checkpoint timestamp persisted by the node
+	cpTs         *atomic.Uint64
+	onCPAdvanced func(channel string, ts uint64)
 }
 
 // Name returns node name, implementing flowgraph.Node
@@ -64,11 +78,38 @@ func (ttn *ttNode) IsValidInMsg(in []Msg) bool {
 func (ttn *ttNode) Close() {
 }
 
+// CloseCheckpointPersisted returns whether the final channel checkpoint is persisted
+// within timeout when the flowgraph closes.
+func (ttn *ttNode) CloseCheckpointPersisted() bool {
+	return ttn.closeCPPersisted.Load()
+}
+
+// CloseMsgCount returns the number of close messages handled by the node.
+func (ttn *ttNode) CloseMsgCount() int64 {
+	return ttn.closeMsgCount.Load()
+}
+
+// HasPendingUpdate returns whether any channel checkpoint update is in flight,
+// so that the shutdown path could wait for it to clear.
+func (ttn *ttNode) HasPendingUpdate() bool {
+	return ttn.pendingUpdates.Load() > 0
+}
+
 // Operate handles input messages, implementing flowgraph.Node
 func (ttn *ttNode) Operate(in []Msg) []Msg {
 	fgMsg := in[0].(*flowGraphMsg)
 	curTs, _ := tsoutil.ParseTS(fgMsg.timeRange.timestampMax)
+	if maxQueueLength := ttn.MaxQueueLength(); maxQueueLength > 0 {
+		metrics.DataNodeFlowGraphQueueSaturation.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), ttn.vChannelName).
+			Set(float64(ttn.InputQueueLength()) / float64(maxQueueLength))
+	}
+	if fgMsg.timeRange.timestampMin > 0 && fgMsg.timeRange.timestampMin <= fgMsg.timeRange.timestampMax {
+		minTs, _ := tsoutil.ParseTS(fgMsg.timeRange.timestampMin)
+		metrics.DataNodeFlowGraphBatchTimeRange.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), ttn.vChannelName).
+			Set(float64(curTs.Sub(minTs).Milliseconds()))
+	}
 	if fgMsg.IsCloseMsg() {
+		ttn.closeMsgCount.Inc()
 		if len(fgMsg.endPositions) > 0 {
 			channelPos, _, err := ttn.writeBufferManager.GetCheckpoint(ttn.vChannelName)
 			if err != nil {
@@ -78,7 +119,7 @@ func (ttn *ttNode) Operate(in []Msg) []Msg {
 			log.Info("flowgraph is closing, force update channel CP",
 				zap.Time("cpTs", tsoutil.PhysicalTime(channelPos.GetTimestamp())),
 				zap.String("channel", channelPos.GetChannelName()))
-			ttn.updateChannelCP(channelPos, curTs)
+			ttn.closeCPPersisted.Store(ttn.flushChannelCP(channelPos, curTs))
 		}
 		return in
 	}
@@ -101,20 +142,113 @@ func (ttn *ttNode) Operate(in []Msg) []Msg {
 	return []Msg{}
 }
 
-func (ttn *ttNode) updateChannelCP(channelPos *msgpb.MsgPosition, curTs time.Time) error {
-	callBack := func() error {
+// flushChannelCP updates the channel checkpoint and waits until it's persisted or timeout,
+// the update is aborted after timeout so that the shutdown could proceed.
+func (ttn *ttNode) flushChannelCP(channelPos *msgpb.MsgPosition, curTs time.Time) bool {
+	timeout := ttn.forceUpdateTimeout
+	if timeout <= 0 {
+		timeout = paramtable.Get().DataNodeCfg.CloseFlushTimeout.GetAsDuration(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if ttn.isCPRegressed(channelPos) {
+		// a newer checkpoint is persisted already
+		return true
+	}
+	future := ttn.submitChannelCP(ctx, channelPos, curTs)
+	if future == nil {
+		return true
+	}
+
+	select {
+	case <-future.Inner():
+		if err := future.Err(); err != nil {
+			log.Warn("failed to update channel CP on close", zap.String("channel", ttn.vChannelName), zap.Error(err))
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		log.Warn("wait channel CP persisted on close timeout, proceed closing",
+			zap.String("channel", ttn.vChannelName),
+			zap.Uint64("cpTs", channelPos.GetTimestamp()),
+			zap.Duration("timeout", timeout))
+		return false
+	}
+}
+
+// updateChannelCP enqueues the update of channel checkpoint into the batch of cpUpdater,
+// the update is skipped if channelPos is older than the persisted checkpoint.
+func (ttn *ttNode) updateChannelCP(channelPos *msgpb.MsgPosition, curTs time.Time, onPersisted ...func()) error {
+	if ttn.isCPRegressed(channelPos) {
+		return nil
+	}
+	ttn.pendingUpdates.Inc()
+	ttn.cpUpdater.enqueueChannelCP(channelPos, ttn.cpPersistedCallback(channelPos, curTs, onPersisted...), func() { ttn.pendingUpdates.Dec() })
+	return nil
+}
+
+// submitChannelCP submits the update of channel checkpoint immediately without batching and returns the future
+// of the update, which fails if the checkpoint is not persisted. The update is skipped and nil is returned
+// if channelPos is older than the persisted checkpoint, in which case onPersisted is not invoked.
+func (ttn *ttNode) submitChannelCP(ctx context.Context, channelPos *msgpb.MsgPosition, curTs time.Time, onPersisted ...func()) *conc.Future[any] {
+	if ttn.isCPRegressed(channelPos) {
+		return nil
+	}
+
+	ttn.pendingUpdates.Inc()
+	return ttn.cpUpdater.updateChannelCP(ctx, channelPos, ttn.cpPersistedCallback(channelPos, curTs, onPersisted...), func() { ttn.pendingUpdates.Dec() })
+}
+
+// cpPersistedCallback returns the callback invoked after channelPos is persisted.
+func (ttn *ttNode) cpPersistedCallback(channelPos *msgpb.MsgPosition, curTs time.Time, onPersisted ...func()) func() error {
+	return func() error {
 		channelCPTs, _ := tsoutil.ParseTS(channelPos.GetTimestamp())
 		ttn.lastUpdateTime.Store(curTs)
 		ttn.writeBufferManager.NotifyCheckpointUpdated(ttn.vChannelName, channelPos.GetTimestamp())
+		metrics.DataNodeUpdateChannelCheckpointCount.WithLabelValues(
+			fmt.Sprint(paramtable.GetNodeID()), ttn.vChannelName, metrics.SuccessLabel).Inc()
 		log.Debug("UpdateChannelCheckpoint success",
 			zap.String("channel", ttn.vChannelName),
 			zap.Uint64("cpTs", channelPos.GetTimestamp()),
 			zap.Time("cpTime", channelCPTs))
+		ttn.advanceCP(channelPos.GetTimestamp())
+		for _, fn := range onPersisted {
+			fn()
+		}
 		return nil
 	}
+}
+
+// isCPRegressed returns whether channelPos is older than the persisted checkpoint,
+// which could be caused by out of order messages and shall never be persisted.
+func (ttn *ttNode) isCPRegressed(channelPos *msgpb.MsgPosition) bool {
+	persisted := ttn.cpTs.Load()
+	if channelPos.GetTimestamp() >= persisted {
+		return false
+	}
+	log.Warn("channel checkpoint regressed, skip updating",
+		zap.String("channel", ttn.vChannelName),
+		zap.Uint64("cpTs", channelPos.GetTimestamp()),
+		zap.Uint64("persistedTs", persisted))
+	return true
+}
 
-	err := ttn.cpUpdater.updateChannelCP(channelPos, callBack)
-	return err
+// advanceCP records ts as the persisted checkpoint and fires onCPAdvanced if it moves forward,
+// updates finished out of order with a stale position are ignored.
+func (ttn *ttNode) advanceCP(ts uint64) {
+	for {
+		prev := ttn.cpTs.Load()
+		if ts <= prev {
+			return
+		}
+		if ttn.cpTs.CompareAndSwap(prev, ts) {
+			break
+		}
+	}
+	if ttn.onCPAdvanced != nil {
+		ttn.onCPAdvanced(ttn.vChannelName, ts)
+	}
 }
 
 func newTTNode(config *nodeConfig, wbManager writebuffer.BufferManager, cpUpdater *channelCheckpointUpdater) (*ttNode, error) {
@@ -129,6 +263,12 @@ func newTTNode(config *nodeConfig, wbManager writebuffer.BufferManager, cpUpdate
 		writeBufferManager: wbManager,
 		lastUpdateTime:     atomic.NewTime(time.Time{}), // set to Zero to update channel checkpoint immediately after fg started
 		cpUpdater:          cpUpdater,
+		closeMsgCount:      atomic.NewInt64(0),
+		closeCPPersisted:   atomic.NewBool(false),
+		pendingUpdates:     atomic.NewInt64(0),
+		forceUpdateTimeout: config.forceUpdateTimeout,
+		cpTs:               atomic.NewUint64(0),
+		onCPAdvanced:       config.onCPAdvanced,
 	}
 
 	return tt, nil
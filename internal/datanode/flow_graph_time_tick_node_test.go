@@ -0,0 +1,354 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/atomic"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/msgpb"
+	"github.com/milvus-io/milvus/internal/datanode/broker"
+	"github.com/milvus-io/milvus/internal/datanode/writebuffer"
+	"github.com/milvus-io/milvus/internal/util/flowgraph"
+	"github.com/milvus-io/milvus/pkg/metrics"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
+)
+
+func TestTTNode_UpdateChannelCPMetrics(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+	nodeID := fmt.Sprint(paramtable.GetNodeID())
+
+	mockBroker := broker.NewMockBroker(t)
+	wbManager := writebuffer.NewMockBufferManager(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel}, wbManager, cpUpdater)
+	assert.NoError(t, err)
+
+	pos := &msgpb.MsgPosition{
+		ChannelName: channel,
+		Timestamp:   tsoutil.ComposeTSByTime(time.Now(), 0),
+	}
+
+	failCounter := metrics.DataNodeUpdateChannelCheckpointCount.WithLabelValues(nodeID, channel, metrics.FailLabel)
+	successCounter := metrics.DataNodeUpdateChannelCheckpointCount.WithLabelValues(nodeID, channel, metrics.SuccessLabel)
+	failed := testutil.ToFloat64(failCounter)
+	succeeded := testutil.ToFloat64(successCounter)
+
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).Return(fmt.Errorf("mock error")).Once()
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, channel, pos).Return(fmt.Errorf("mock error")).Once()
+	err = ttn.updateChannelCP(pos, time.Now())
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(failCounter) == failed+1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, succeeded, testutil.ToFloat64(successCounter))
+
+	notified := make(chan struct{})
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).Return(nil).Once()
+	wbManager.EXPECT().NotifyCheckpointUpdated(channel, pos.GetTimestamp()).Run(func(_ string, _ uint64) {
+		close(notified)
+	}).Once()
+	err = ttn.updateChannelCP(pos, time.Now())
+	assert.NoError(t, err)
+	<-notified
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(successCounter) == succeeded+1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, failed+1, testutil.ToFloat64(failCounter))
+}
+
+func TestTTNode_QueueSaturation(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+	saturation := metrics.DataNodeFlowGraphQueueSaturation.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), channel)
+
+	wbManager := writebuffer.NewMockBufferManager(t)
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel}, wbManager, nil)
+	assert.NoError(t, err)
+	ttn.SetMaxQueueLength(8)
+	ttn.lastUpdateTime.Store(time.Now())
+
+	now := time.Now()
+	pos := &msgpb.MsgPosition{ChannelName: channel, Timestamp: tsoutil.ComposeTSByTime(now, 0)}
+	wbManager.EXPECT().GetCheckpoint(channel).Return(pos, false, nil)
+
+	queue := make(chan []Msg, ttn.MaxQueueLength())
+	ttn.SetInputQueue(queue)
+	for i := 0; i < 5; i++ {
+		queue <- []Msg{&flowGraphMsg{timeRange: TimeRange{timestampMax: tsoutil.ComposeTSByTime(now, int64(i))}}}
+	}
+
+	// messages are consumed one by one, the metric reflects the messages left in queue
+	for left := 4; left >= 0; left-- {
+		ttn.Operate(<-queue)
+		assert.Equal(t, float64(left)/8, testutil.ToFloat64(saturation))
+	}
+}
+
+func TestTTNode_CloseMsgCount(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+	wbManager := writebuffer.NewMockBufferManager(t)
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel}, wbManager, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, ttn.CloseMsgCount())
+
+	in := []Msg{&flowGraphMsg{
+		BaseMsg:   flowgraph.NewBaseMsg(true),
+		timeRange: TimeRange{timestampMax: tsoutil.ComposeTSByTime(time.Now(), 0)},
+	}}
+	out := ttn.Operate(in)
+	assert.Equal(t, in, out)
+	assert.EqualValues(t, 1, ttn.CloseMsgCount())
+}
+
+func TestTTNode_CloseFlushTimeout(t *testing.T) {
+	paramtable.Init()
+	paramtable.Get().Save(Params.DataNodeCfg.CloseFlushTimeout.Key, "100")
+	defer paramtable.Get().Reset(Params.DataNodeCfg.CloseFlushTimeout.Key)
+	channel := "by-dev-rootcoord-dml_0_100v0"
+
+	mockBroker := broker.NewMockBroker(t)
+	wbManager := writebuffer.NewMockBufferManager(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel}, wbManager, cpUpdater)
+	assert.NoError(t, err)
+
+	pos := &msgpb.MsgPosition{
+		ChannelName: channel,
+		Timestamp:   tsoutil.ComposeTSByTime(time.Now(), 0),
+	}
+	wbManager.EXPECT().GetCheckpoint(channel).Return(pos, false, nil)
+	wbManager.EXPECT().NotifyCheckpointUpdated(channel, pos.GetTimestamp()).Return().Maybe()
+	closeMsg := []Msg{&flowGraphMsg{
+		BaseMsg:      flowgraph.NewBaseMsg(true),
+		timeRange:    TimeRange{timestampMax: pos.GetTimestamp()},
+		endPositions: []*msgpb.MsgPosition{pos},
+	}}
+
+	// slow updater
+	release := make(chan struct{})
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, channel, pos).RunAndReturn(func(_ context.Context, _ string, _ *msgpb.MsgPosition) error {
+		<-release
+		return nil
+	}).Once()
+	start := time.Now()
+	out := ttn.Operate(closeMsg)
+	assert.Equal(t, closeMsg, out)
+	assert.False(t, ttn.CloseCheckpointPersisted())
+	assert.Less(t, time.Since(start), 5*time.Second)
+	close(release)
+
+	// failure of the update is reported by the future
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, channel, pos).Return(errors.New("mock")).Once()
+	ttn.Operate(closeMsg)
+	assert.False(t, ttn.CloseCheckpointPersisted())
+
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, channel, pos).Return(nil).Once()
+	ttn.Operate(closeMsg)
+	assert.True(t, ttn.CloseCheckpointPersisted())
+}
+
+func TestTTNode_ForceUpdateTimeout(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+
+	mockBroker := broker.NewMockBroker(t)
+	wbManager := writebuffer.NewMockBufferManager(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel, forceUpdateTimeout: 100 * time.Millisecond}, wbManager, cpUpdater)
+	assert.NoError(t, err)
+
+	pos := &msgpb.MsgPosition{
+		ChannelName: channel,
+		Timestamp:   tsoutil.ComposeTSByTime(time.Now(), 0),
+	}
+	wbManager.EXPECT().GetCheckpoint(channel).Return(pos, false, nil)
+	closeMsg := []Msg{&flowGraphMsg{
+		BaseMsg:      flowgraph.NewBaseMsg(true),
+		timeRange:    TimeRange{timestampMax: pos.GetTimestamp()},
+		endPositions: []*msgpb.MsgPosition{pos},
+	}}
+
+	// updater blocks until the deadline
+	aborted := make(chan error, 1)
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, channel, pos).RunAndReturn(func(ctx context.Context, _ string, _ *msgpb.MsgPosition) error {
+		<-ctx.Done()
+		aborted <- ctx.Err()
+		return ctx.Err()
+	}).Once()
+	start := time.Now()
+	out := ttn.Operate(closeMsg)
+	assert.Equal(t, closeMsg, out)
+	assert.False(t, ttn.CloseCheckpointPersisted())
+	assert.Less(t, time.Since(start), time.Second)
+	select {
+	case err := <-aborted:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("update is not aborted after deadline")
+	}
+}
+
+func TestTTNode_OnCPAdvanced(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+
+	mockBroker := broker.NewMockBroker(t)
+	wbManager := writebuffer.NewMockBufferManager(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+
+	advanced := make(chan uint64, 10)
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel, onCPAdvanced: func(ch string, ts uint64) {
+		assert.Equal(t, channel, ch)
+		advanced <- ts
+	}}, wbManager, cpUpdater)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	pos := &msgpb.MsgPosition{ChannelName: channel, Timestamp: tsoutil.ComposeTSByTime(now, 0)}
+	stale := &msgpb.MsgPosition{ChannelName: channel, Timestamp: tsoutil.ComposeTSByTime(now.Add(-time.Second), 0)}
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, mock.Anything).Return(nil)
+	wbManager.EXPECT().NotifyCheckpointUpdated(channel, mock.Anything).Return()
+
+	for _, p := range []*msgpb.MsgPosition{pos, pos} {
+		persisted := make(chan struct{})
+		assert.NoError(t, ttn.updateChannelCP(p, now, func() { close(persisted) }))
+		<-persisted
+	}
+	// updates finished out of order are not reported
+	ttn.advanceCP(stale.GetTimestamp())
+	assert.Len(t, advanced, 1)
+	assert.Equal(t, pos.GetTimestamp(), <-advanced)
+}
+
+func TestTTNode_SkipRegressedCP(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+
+	mockBroker := broker.NewMockBroker(t)
+	wbManager := writebuffer.NewMockBufferManager(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+
+	advanced := make(chan uint64, 10)
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel, onCPAdvanced: func(_ string, ts uint64) {
+		advanced <- ts
+	}}, wbManager, cpUpdater)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	pos := &msgpb.MsgPosition{ChannelName: channel, Timestamp: tsoutil.ComposeTSByTime(now, 0)}
+	stale := &msgpb.MsgPosition{ChannelName: channel, Timestamp: tsoutil.ComposeTSByTime(now.Add(-time.Second), 0)}
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).Return(nil).Once()
+	wbManager.EXPECT().NotifyCheckpointUpdated(channel, pos.GetTimestamp()).Return().Once()
+
+	persisted := make(chan struct{})
+	assert.NoError(t, ttn.updateChannelCP(pos, now, func() { close(persisted) }))
+	<-persisted
+	assert.Equal(t, pos.GetTimestamp(), <-advanced)
+
+	// the regressed position is neither written nor reported
+	callbackFired := atomic.NewBool(false)
+	assert.NoError(t, ttn.updateChannelCP(stale, now.Add(time.Minute), func() { callbackFired.Store(true) }))
+	assert.False(t, ttn.HasPendingUpdate())
+	assert.True(t, ttn.flushChannelCP(stale, now.Add(time.Minute)))
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, callbackFired.Load())
+	assert.Empty(t, advanced)
+	assert.Equal(t, now, ttn.lastUpdateTime.Load())
+	mockBroker.AssertNumberOfCalls(t, "UpdateChannelCheckpoints", 1)
+}
+
+func TestTTNode_HasPendingUpdate(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+
+	mockBroker := broker.NewMockBroker(t)
+	wbManager := writebuffer.NewMockBufferManager(t)
+	cpUpdater := newChannelCheckpointUpdater(&DataNode{broker: mockBroker})
+	defer cpUpdater.close()
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel}, wbManager, cpUpdater)
+	assert.NoError(t, err)
+	assert.False(t, ttn.HasPendingUpdate())
+
+	pos := &msgpb.MsgPosition{
+		ChannelName: channel,
+		Timestamp:   tsoutil.ComposeTSByTime(time.Now(), 0),
+	}
+	wbManager.EXPECT().NotifyCheckpointUpdated(channel, pos.GetTimestamp()).Return().Once()
+
+	// slow persister
+	release := make(chan struct{})
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).RunAndReturn(func(_ context.Context, _ []*msgpb.MsgPosition) error {
+		<-release
+		return nil
+	}).Once()
+	assert.NoError(t, ttn.updateChannelCP(pos, time.Now()))
+	assert.True(t, ttn.HasPendingUpdate())
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, ttn.HasPendingUpdate())
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return !ttn.HasPendingUpdate()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// failed update clears the flag as well
+	mockBroker.EXPECT().UpdateChannelCheckpoints(mock.Anything, []*msgpb.MsgPosition{pos}).Return(fmt.Errorf("mock error")).Once()
+	mockBroker.EXPECT().UpdateChannelCheckpoint(mock.Anything, channel, pos).Return(fmt.Errorf("mock error")).Once()
+	assert.NoError(t, ttn.updateChannelCP(pos, time.Now()))
+	assert.Eventually(t, func() bool {
+		return !ttn.HasPendingUpdate()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTTNode_BatchTimeRangeMetrics(t *testing.T) {
+	paramtable.Init()
+	channel := "by-dev-rootcoord-dml_0_100v0"
+	wbManager := writebuffer.NewMockBufferManager(t)
+	ttn, err := newTTNode(&nodeConfig{vChannelName: channel}, wbManager, nil)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	in := []Msg{&flowGraphMsg{
+		BaseMsg: flowgraph.NewBaseMsg(true),
+		timeRange: TimeRange{
+			timestampMin: tsoutil.ComposeTSByTime(now.Add(-1500*time.Millisecond), 0),
+			timestampMax: tsoutil.ComposeTSByTime(now, 0),
+		},
+	}}
+	ttn.Operate(in)
+
+	gauge := metrics.DataNodeFlowGraphBatchTimeRange.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), channel)
+	assert.EqualValues(t, 1500, testutil.ToFloat64(gauge))
+}
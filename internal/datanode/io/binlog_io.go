@@ -50,8 +50,18 @@ func (b *BinlogIoImpl) Download(ctx context.Context, paths []string) ([][]byte,
 			vs, err = b.MultiRead(ctx, paths)
 			return err
 		})
+		if err != nil {
+			return nil, err
+		}
 
-		return vs, err
+		// blobs written by sync task are verified and decompressed before deserialization
+		for i, path := range paths {
+			vs[i], err = storage.DecodeBlob(ctx, b.ChunkManager, path, vs[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return vs, nil
 	})
 
 	vs, err := future.Await()
@@ -53,6 +53,52 @@ func (s *BinlogIOSuite) TestUploadDownload() {
 	s.ElementsMatch(lo.Values(kvs), vs)
 }
 
+func (s *BinlogIOSuite) TestDownloadCompressed() {
+	value := []byte{1, 255, 255, 255, 255}
+	kvs := make(map[string][]byte)
+	for _, codec := range []storage.BlobCodec{storage.BlobCodecNone, storage.BlobCodecZstd, storage.BlobCodecGzip} {
+		key, compressed, err := storage.CompressBlob(path.Join(binlogIOTestDir, "compressed", string(codec)), value, codec)
+		s.Require().NoError(err)
+		kvs[key] = compressed
+	}
+
+	ctx := context.Background()
+	err := s.b.Upload(ctx, kvs)
+	s.NoError(err)
+
+	vs, err := s.b.Download(ctx, lo.Keys(kvs))
+	s.NoError(err)
+	s.Equal([][]byte{value, value, value}, vs)
+}
+
+func (s *BinlogIOSuite) TestDownloadCorrupted() {
+	storage.SetBlobChecksumVerification(true)
+	defer storage.SetBlobChecksumVerification(false)
+	value := []byte{1, 255, 255, 255, 255}
+	key := path.Join(binlogIOTestDir, "checksum", "a")
+	kvs := map[string][]byte{
+		key:                          value,
+		storage.BlobChecksumKey(key): storage.BlobChecksum(value),
+	}
+
+	ctx := context.Background()
+	err := s.b.Upload(ctx, kvs)
+	s.NoError(err)
+
+	vs, err := s.b.Download(ctx, []string{key})
+	s.NoError(err)
+	s.Equal([][]byte{value}, vs)
+
+	// flip a byte in the stored blob
+	corrupted := append([]byte(nil), value...)
+	corrupted[2] ^= 0xff
+	err = s.b.Upload(ctx, map[string][]byte{key: corrupted})
+	s.NoError(err)
+
+	_, err = s.b.Download(ctx, []string{key})
+	s.ErrorIs(err, storage.ErrBlobChecksumMismatch)
+}
+
 func (s *BinlogIOSuite) TestJoinFullPath() {
 	tests := []struct {
 		description string
@@ -0,0 +1,129 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"sort"
+
+	"github.com/milvus-io/milvus/pkg/util/lock"
+	"github.com/milvus-io/milvus/pkg/util/typeutil"
+)
+
+// completion is the buffered completion of a submitted task.
+type completion struct {
+	ts   uint64
+	done bool
+	err  error
+	emit func(err error)
+}
+
+// segmentCompletions is the registered completions of a segment sorted by ts.
+type segmentCompletions struct {
+	queue []*completion
+	// emitting is set while a goroutine is emitting the completions of the segment,
+	// which emits the completions finished meanwhile as well.
+	emitting bool
+}
+
+// popReady removes and returns the leading finished completions.
+func (sc *segmentCompletions) popReady() []*completion {
+	var n int
+	for n < len(sc.queue) && sc.queue[n].done {
+		n++
+	}
+	ready := sc.queue[:n:n]
+	sc.queue = sc.queue[n:]
+	return ready
+}
+
+// completionSequencer emits the completions of the same segment in ascending checkpoint timestamp order.
+// Completion finished before its predecessors is buffered until all of them are emitted.
+type completionSequencer struct {
+	locks   *lock.KeyLock[int64]
+	pending *typeutil.ConcurrentMap[int64, *segmentCompletions] // segment id => registered completions
+}
+
+func newCompletionSequencer() *completionSequencer {
+	return &completionSequencer{
+		locks:   lock.NewKeyLock[int64](),
+		pending: typeutil.NewConcurrentMap[int64, *segmentCompletions](),
+	}
+}
+
+// register records a task of segmentID with checkpoint ts, emit will be invoked in order after complete called.
+func (s *completionSequencer) register(segmentID int64, ts uint64, emit func(err error)) *completion {
+	s.locks.Lock(segmentID)
+	defer s.locks.Unlock(segmentID)
+
+	c := &completion{ts: ts, emit: emit}
+	segment, _ := s.pending.GetOrInsert(segmentID, &segmentCompletions{})
+	queue := segment.queue
+	// insert after completions with the same ts to keep submit order
+	idx := sort.Search(len(queue), func(i int) bool { return queue[i].ts > ts })
+	queue = append(queue, nil)
+	copy(queue[idx+1:], queue[idx:])
+	queue[idx] = c
+	segment.queue = queue
+	return c
+}
+
+// complete marks c finished and emits all leading finished completions of the segment.
+// emit functions are invoked without lock held, so they could register new completions,
+// while only one goroutine emits for a segment at a time so that the order holds among concurrent completions.
+func (s *completionSequencer) complete(segmentID int64, c *completion, err error) {
+	s.locks.Lock(segmentID)
+	c.done = true
+	c.err = err
+	segment, _ := s.pending.Get(segmentID)
+	if segment.emitting {
+		// the emitting goroutine emits c after its predecessors
+		s.locks.Unlock(segmentID)
+		return
+	}
+
+	segment.emitting = true
+	for ready := segment.popReady(); len(ready) > 0; ready = segment.popReady() {
+		s.locks.Unlock(segmentID)
+		for _, c := range ready {
+			c.emit(c.err)
+		}
+		s.locks.Lock(segmentID)
+	}
+	segment.emitting = false
+	if len(segment.queue) == 0 {
+		s.pending.Remove(segmentID)
+	}
+	s.locks.Unlock(segmentID)
+}
+
+// buffered returns the number of finished completions of the segment waiting for their predecessors.
+func (s *completionSequencer) buffered(segmentID int64) int {
+	s.locks.Lock(segmentID)
+	defer s.locks.Unlock(segmentID)
+
+	segment, ok := s.pending.Get(segmentID)
+	if !ok {
+		return 0
+	}
+	var count int
+	for _, c := range segment.queue {
+		if c.done {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,71 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"sync"
+)
+
+// payloadTask is implemented by tasks which know the size of their in-memory payload.
+type payloadTask interface {
+	PayloadSize() int64
+}
+
+func getTaskPayloadSize(task Task) int64 {
+	if t, ok := task.(payloadTask); ok {
+		return t.PayloadSize()
+	}
+	return 0
+}
+
+// inFlightLimiter tracks the total payload size of in-flight tasks and blocks acquiring beyond the cap.
+type inFlightLimiter struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	bytes int64
+	cap   int64 // non-positive means unlimited
+}
+
+func newInFlightLimiter(cap int64) *inFlightLimiter {
+	l := &inFlightLimiter{cap: cap}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until size could be added without exceeding the cap.
+// A task larger than the cap is admitted when nothing else is in flight, otherwise it would never run.
+func (l *inFlightLimiter) acquire(size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.cap > 0 && l.bytes > 0 && l.bytes+size > l.cap {
+		l.cond.Wait()
+	}
+	l.bytes += size
+}
+
+func (l *inFlightLimiter) release(size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bytes -= size
+	l.cond.Broadcast()
+}
+
+func (l *inFlightLimiter) inFlight() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bytes
+}
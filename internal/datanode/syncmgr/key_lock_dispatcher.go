@@ -14,28 +14,72 @@ type Task interface {
 	Run() error
 }
 
+// keyLockedTask is notified once the dispatcher acquires the key lock for it,
+// the task may still wait for a free worker then.
+type keyLockedTask interface {
+	onKeyLocked()
+}
+
 type keyLockDispatcher[K comparable] struct {
 	keyLock    *lock.KeyLock[K]
 	workerPool *conc.Pool[error]
+	queue      *dispatchQueue
 }
 
 func newKeyLockDispatcher[K comparable](maxParallel int) *keyLockDispatcher[K] {
 	return &keyLockDispatcher[K]{
 		workerPool: conc.NewPool[error](maxParallel, conc.WithPreAlloc(true)),
 		keyLock:    lock.NewKeyLock[K](),
+		queue:      newDispatchQueue(),
 	}
 }
 
+// Submit runs t once the key is unlocked and a worker is free,
+// when a worker frees up, the waiting task of the highest priority runs first.
+// Tasks of a channel running as many tasks as the channel limit wait and leave the worker to other channels.
 func (d *keyLockDispatcher[K]) Submit(key K, t Task, callbacks ...func(error)) *conc.Future[error] {
 	d.keyLock.Lock(key)
+	if lt, ok := t.(keyLockedTask); ok {
+		lt.onKeyLocked()
+	}
 
-	return d.workerPool.Submit(func() (error, error) {
-		defer d.keyLock.Unlock(key)
-		err := t.Run()
+	job := &dispatchJob{
+		priority: getTaskPriority(t),
+		channel:  t.ChannelName(),
+		run: func() error {
+			err := t.Run()
+			// callbacks run after the key is unlocked, so they could submit tasks of the same key
+			d.keyLock.Unlock(key)
+
+			for _, callback := range callbacks {
+				callback(err)
+			}
+			return err
+		},
+		done: make(chan struct{}),
+	}
+	d.queue.push(job)
 
-		for _, callback := range callbacks {
-			callback(err)
-		}
-		return err, nil
+	// each submission runs exactly one job, which is not necessarily the one pushed above
+	d.workerPool.Submit(d.runNext)
+	return conc.Go(func() (error, error) {
+		<-job.done
+		return job.err, nil
 	})
 }
+
+// runNext runs the next runnable job in queue, or nothing if all are held back by the channel limit,
+// in which case it's redone after a running job finishes.
+func (d *keyLockDispatcher[K]) runNext() (error, error) {
+	next := d.queue.pop()
+	if next == nil {
+		return nil, nil
+	}
+	next.err = next.run()
+	if d.queue.finish(next) {
+		// submit asynchronously since the pool may be full of workers doing the same
+		go d.workerPool.Submit(d.runNext)
+	}
+	close(next.done)
+	return next.err, nil
+}
@@ -1,6 +1,7 @@
 package syncmgr
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -11,8 +12,9 @@ import (
 )
 
 type mockTask struct {
-	ch  chan struct{}
-	err error
+	ch      chan struct{}
+	err     error
+	channel string
 }
 
 func (t *mockTask) done() {
@@ -22,7 +24,7 @@ func (t *mockTask) done() {
 func (t *mockTask) SegmentID() int64                  { panic("no implementation") }
 func (t *mockTask) Checkpoint() *msgpb.MsgPosition    { panic("no implementation") }
 func (t *mockTask) StartPosition() *msgpb.MsgPosition { panic("no implementation") }
-func (t *mockTask) ChannelName() string               { panic("no implementation") }
+func (t *mockTask) ChannelName() string               { return t.channel }
 
 func (t *mockTask) Run() error {
 	<-t.ch
@@ -87,6 +89,88 @@ func (s *KeyLockDispatcherSuite) TestCap() {
 	s.Eventually(sig.Load, time.Second, time.Millisecond*100)
 }
 
+func (s *KeyLockDispatcherSuite) TestPriority() {
+	d := newKeyLockDispatcher[int64](1)
+
+	t1 := newMockTask(nil)
+	d.Submit(1, t1)
+
+	var order []int64
+	mu := sync.Mutex{}
+	record := func(key int64) func(error) {
+		return func(error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, key)
+		}
+	}
+	t2 := newMockTask(nil)
+	t3 := &priorityMockTask{mockTask: newMockTask(nil), priority: 1}
+	close(t2.ch)
+	close(t3.ch)
+	go d.Submit(2, t2, record(2))
+	s.Eventually(func() bool { return d.queue.len() == 1 }, time.Second, time.Millisecond*10)
+	go d.Submit(3, t3, record(3))
+	s.Eventually(func() bool { return d.queue.len() == 2 }, time.Second, time.Millisecond*10)
+
+	t1.done()
+	s.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(order) == 2
+	}, time.Second, time.Millisecond*10)
+	s.Equal([]int64{3, 2}, order)
+}
+
+func (s *KeyLockDispatcherSuite) TestChannelLimit() {
+	d := newKeyLockDispatcher[int64](2)
+	d.queue.channelLimit = 1
+
+	var order []int64
+	mu := sync.Mutex{}
+	record := func(key int64) func(error) {
+		return func(error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, key)
+		}
+	}
+	getOrder := func() []int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int64{}, order...)
+	}
+
+	t1 := newMockTask(nil)
+	t1.channel = "channel_1"
+	t2 := newMockTask(nil)
+	t2.channel = "channel_1"
+	t3 := newMockTask(nil)
+	t3.channel = "channel_2"
+	close(t2.ch)
+	close(t3.ch)
+
+	d.Submit(1, t1, record(1))
+	d.Submit(2, t2, record(2))
+	d.Submit(3, t3, record(3))
+
+	// t2 is held back by t1 of the same channel, while t3 of another channel takes the free worker
+	s.Eventually(func() bool { return len(getOrder()) == 1 }, time.Second, time.Millisecond*10)
+	s.Equal([]int64{3}, getOrder())
+	s.Equal(1, d.queue.len())
+
+	t1.done()
+	s.Eventually(func() bool { return len(getOrder()) == 3 }, time.Second, time.Millisecond*10)
+	s.Equal([]int64{3, 1, 2}, getOrder())
+}
+
+type priorityMockTask struct {
+	*mockTask
+	priority int
+}
+
+func (t *priorityMockTask) Priority() int { return t.priority }
+
 func TestKeyLockDispatcher(t *testing.T) {
 	suite.Run(t, new(KeyLockDispatcherSuite))
 }
@@ -118,11 +118,7 @@ func (b *brokerMetaWriter) UpdateSync(pack *SyncTask) error {
 			return nil
 		}
 
-		if err != nil {
-			return err
-		}
-
-		return nil
+		return classifyRetryError(err)
 	}, b.opts...)
 	if err != nil {
 		log.Warn("failed to SaveBinlogPaths",
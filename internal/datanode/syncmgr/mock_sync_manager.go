@@ -25,6 +25,47 @@ func (_m *MockSyncManager) EXPECT() *MockSyncManager_Expecter {
 	return &MockSyncManager_Expecter{mock: &_m.Mock}
 }
 
+// AverageUtilization provides a mock function with given fields:
+func (_m *MockSyncManager) AverageUtilization() float64 {
+	ret := _m.Called()
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func() float64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	return r0
+}
+
+// MockSyncManager_AverageUtilization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AverageUtilization'
+type MockSyncManager_AverageUtilization_Call struct {
+	*mock.Call
+}
+
+// AverageUtilization is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) AverageUtilization() *MockSyncManager_AverageUtilization_Call {
+	return &MockSyncManager_AverageUtilization_Call{Call: _e.mock.On("AverageUtilization")}
+}
+
+func (_c *MockSyncManager_AverageUtilization_Call) Run(run func()) *MockSyncManager_AverageUtilization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_AverageUtilization_Call) Return(_a0 float64) *MockSyncManager_AverageUtilization_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_AverageUtilization_Call) RunAndReturn(run func() float64) *MockSyncManager_AverageUtilization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Block provides a mock function with given fields: segmentID
 func (_m *MockSyncManager) Block(segmentID int64) {
 	_m.Called(segmentID)
@@ -58,6 +99,255 @@ func (_c *MockSyncManager_Block_Call) RunAndReturn(run func(int64)) *MockSyncMan
 	return _c
 }
 
+// CancelChannel provides a mock function with given fields: channel
+func (_m *MockSyncManager) CancelChannel(channel string) {
+	_m.Called(channel)
+}
+
+// MockSyncManager_CancelChannel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelChannel'
+type MockSyncManager_CancelChannel_Call struct {
+	*mock.Call
+}
+
+// CancelChannel is a helper method to define mock.On call
+//   - channel string
+func (_e *MockSyncManager_Expecter) CancelChannel(channel interface{}) *MockSyncManager_CancelChannel_Call {
+	return &MockSyncManager_CancelChannel_Call{Call: _e.mock.On("CancelChannel", channel)}
+}
+
+func (_c *MockSyncManager_CancelChannel_Call) Run(run func(channel string)) *MockSyncManager_CancelChannel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_CancelChannel_Call) Return() *MockSyncManager_CancelChannel_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSyncManager_CancelChannel_Call) RunAndReturn(run func(string)) *MockSyncManager_CancelChannel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelSegment provides a mock function with given fields: segmentID
+func (_m *MockSyncManager) CancelSegment(segmentID int64) {
+	_m.Called(segmentID)
+}
+
+// MockSyncManager_CancelSegment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelSegment'
+type MockSyncManager_CancelSegment_Call struct {
+	*mock.Call
+}
+
+// CancelSegment is a helper method to define mock.On call
+//   - segmentID int64
+func (_e *MockSyncManager_Expecter) CancelSegment(segmentID interface{}) *MockSyncManager_CancelSegment_Call {
+	return &MockSyncManager_CancelSegment_Call{Call: _e.mock.On("CancelSegment", segmentID)}
+}
+
+func (_c *MockSyncManager_CancelSegment_Call) Run(run func(segmentID int64)) *MockSyncManager_CancelSegment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_CancelSegment_Call) Return() *MockSyncManager_CancelSegment_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSyncManager_CancelSegment_Call) RunAndReturn(run func(int64)) *MockSyncManager_CancelSegment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Close provides a mock function with given fields: ctx
+func (_m *MockSyncManager) Close(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSyncManager_Close_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Close'
+type MockSyncManager_Close_Call struct {
+	*mock.Call
+}
+
+// Close is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSyncManager_Expecter) Close(ctx interface{}) *MockSyncManager_Close_Call {
+	return &MockSyncManager_Close_Call{Call: _e.mock.On("Close", ctx)}
+}
+
+func (_c *MockSyncManager_Close_Call) Run(run func(ctx context.Context)) *MockSyncManager_Close_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_Close_Call) Return(_a0 error) *MockSyncManager_Close_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_Close_Call) RunAndReturn(run func(context.Context) error) *MockSyncManager_Close_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Export provides a mock function with given fields:
+func (_m *MockSyncManager) Export() []TaskInfo {
+	ret := _m.Called()
+
+	var r0 []TaskInfo
+	if rf, ok := ret.Get(0).(func() []TaskInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]TaskInfo)
+		}
+	}
+
+	return r0
+}
+
+// MockSyncManager_Export_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Export'
+type MockSyncManager_Export_Call struct {
+	*mock.Call
+}
+
+// Export is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) Export() *MockSyncManager_Export_Call {
+	return &MockSyncManager_Export_Call{Call: _e.mock.On("Export")}
+}
+
+func (_c *MockSyncManager_Export_Call) Run(run func()) *MockSyncManager_Export_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_Export_Call) Return(_a0 []TaskInfo) *MockSyncManager_Export_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_Export_Call) RunAndReturn(run func() []TaskInfo) *MockSyncManager_Export_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FlushBarrier provides a mock function with given fields: ctx, channel
+func (_m *MockSyncManager) FlushBarrier(ctx context.Context, channel string) error {
+	ret := _m.Called(ctx, channel)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, channel)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSyncManager_FlushBarrier_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FlushBarrier'
+type MockSyncManager_FlushBarrier_Call struct {
+	*mock.Call
+}
+
+// FlushBarrier is a helper method to define mock.On call
+//   - ctx context.Context
+//   - channel string
+func (_e *MockSyncManager_Expecter) FlushBarrier(ctx interface{}, channel interface{}) *MockSyncManager_FlushBarrier_Call {
+	return &MockSyncManager_FlushBarrier_Call{Call: _e.mock.On("FlushBarrier", ctx, channel)}
+}
+
+func (_c *MockSyncManager_FlushBarrier_Call) Run(run func(ctx context.Context, channel string)) *MockSyncManager_FlushBarrier_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_FlushBarrier_Call) Return(_a0 error) *MockSyncManager_FlushBarrier_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_FlushBarrier_Call) RunAndReturn(run func(context.Context, string) error) *MockSyncManager_FlushBarrier_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetEarliestPartitionPosition provides a mock function with given fields: channel, partitionID
+func (_m *MockSyncManager) GetEarliestPartitionPosition(channel string, partitionID int64) (int64, *msgpb.MsgPosition) {
+	ret := _m.Called(channel, partitionID)
+
+	var r0 int64
+	var r1 *msgpb.MsgPosition
+	if rf, ok := ret.Get(0).(func(string, int64) (int64, *msgpb.MsgPosition)); ok {
+		return rf(channel, partitionID)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) int64); ok {
+		r0 = rf(channel, partitionID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) *msgpb.MsgPosition); ok {
+		r1 = rf(channel, partitionID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*msgpb.MsgPosition)
+		}
+	}
+
+	return r0, r1
+}
+
+// MockSyncManager_GetEarliestPartitionPosition_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetEarliestPartitionPosition'
+type MockSyncManager_GetEarliestPartitionPosition_Call struct {
+	*mock.Call
+}
+
+// GetEarliestPartitionPosition is a helper method to define mock.On call
+//   - channel string
+//   - partitionID int64
+func (_e *MockSyncManager_Expecter) GetEarliestPartitionPosition(channel interface{}, partitionID interface{}) *MockSyncManager_GetEarliestPartitionPosition_Call {
+	return &MockSyncManager_GetEarliestPartitionPosition_Call{Call: _e.mock.On("GetEarliestPartitionPosition", channel, partitionID)}
+}
+
+func (_c *MockSyncManager_GetEarliestPartitionPosition_Call) Run(run func(channel string, partitionID int64)) *MockSyncManager_GetEarliestPartitionPosition_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_GetEarliestPartitionPosition_Call) Return(_a0 int64, _a1 *msgpb.MsgPosition) *MockSyncManager_GetEarliestPartitionPosition_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSyncManager_GetEarliestPartitionPosition_Call) RunAndReturn(run func(string, int64) (int64, *msgpb.MsgPosition)) *MockSyncManager_GetEarliestPartitionPosition_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetEarliestPosition provides a mock function with given fields: channel
 func (_m *MockSyncManager) GetEarliestPosition(channel string) (int64, *msgpb.MsgPosition) {
 	ret := _m.Called(channel)
@@ -112,80 +402,625 @@ func (_c *MockSyncManager_GetEarliestPosition_Call) RunAndReturn(run func(string
 	return _c
 }
 
-// SyncData provides a mock function with given fields: ctx, task
-func (_m *MockSyncManager) SyncData(ctx context.Context, task Task) *conc.Future[error] {
-	ret := _m.Called(ctx, task)
+// GetEarliestRunningPosition provides a mock function with given fields: channel
+func (_m *MockSyncManager) GetEarliestRunningPosition(channel string) (int64, *msgpb.MsgPosition) {
+	ret := _m.Called(channel)
 
-	var r0 *conc.Future[error]
-	if rf, ok := ret.Get(0).(func(context.Context, Task) *conc.Future[error]); ok {
-		r0 = rf(ctx, task)
+	var r0 int64
+	var r1 *msgpb.MsgPosition
+	if rf, ok := ret.Get(0).(func(string) (int64, *msgpb.MsgPosition)); ok {
+		return rf(channel)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(channel)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*conc.Future[error])
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *msgpb.MsgPosition); ok {
+		r1 = rf(channel)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*msgpb.MsgPosition)
 		}
 	}
 
-	return r0
+	return r0, r1
 }
 
-// MockSyncManager_SyncData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncData'
-type MockSyncManager_SyncData_Call struct {
+// MockSyncManager_GetEarliestRunningPosition_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetEarliestRunningPosition'
+type MockSyncManager_GetEarliestRunningPosition_Call struct {
 	*mock.Call
 }
 
-// SyncData is a helper method to define mock.On call
-//   - ctx context.Context
-//   - task Task
-func (_e *MockSyncManager_Expecter) SyncData(ctx interface{}, task interface{}) *MockSyncManager_SyncData_Call {
-	return &MockSyncManager_SyncData_Call{Call: _e.mock.On("SyncData", ctx, task)}
+// GetEarliestRunningPosition is a helper method to define mock.On call
+//   - channel string
+func (_e *MockSyncManager_Expecter) GetEarliestRunningPosition(channel interface{}) *MockSyncManager_GetEarliestRunningPosition_Call {
+	return &MockSyncManager_GetEarliestRunningPosition_Call{Call: _e.mock.On("GetEarliestRunningPosition", channel)}
 }
 
-func (_c *MockSyncManager_SyncData_Call) Run(run func(ctx context.Context, task Task)) *MockSyncManager_SyncData_Call {
+func (_c *MockSyncManager_GetEarliestRunningPosition_Call) Run(run func(channel string)) *MockSyncManager_GetEarliestRunningPosition_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(Task))
+		run(args[0].(string))
 	})
 	return _c
 }
 
-func (_c *MockSyncManager_SyncData_Call) Return(_a0 *conc.Future[error]) *MockSyncManager_SyncData_Call {
-	_c.Call.Return(_a0)
+func (_c *MockSyncManager_GetEarliestRunningPosition_Call) Return(_a0 int64, _a1 *msgpb.MsgPosition) *MockSyncManager_GetEarliestRunningPosition_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockSyncManager_SyncData_Call) RunAndReturn(run func(context.Context, Task) *conc.Future[error]) *MockSyncManager_SyncData_Call {
+func (_c *MockSyncManager_GetEarliestRunningPosition_Call) RunAndReturn(run func(string) (int64, *msgpb.MsgPosition)) *MockSyncManager_GetEarliestRunningPosition_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Unblock provides a mock function with given fields: segmentID
-func (_m *MockSyncManager) Unblock(segmentID int64) {
-	_m.Called(segmentID)
+// Import provides a mock function with given fields: tasks
+func (_m *MockSyncManager) Import(tasks []TaskInfo) error {
+	ret := _m.Called(tasks)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]TaskInfo) error); ok {
+		r0 = rf(tasks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-// MockSyncManager_Unblock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unblock'
-type MockSyncManager_Unblock_Call struct {
+// MockSyncManager_Import_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Import'
+type MockSyncManager_Import_Call struct {
 	*mock.Call
 }
 
-// Unblock is a helper method to define mock.On call
-//   - segmentID int64
-func (_e *MockSyncManager_Expecter) Unblock(segmentID interface{}) *MockSyncManager_Unblock_Call {
-	return &MockSyncManager_Unblock_Call{Call: _e.mock.On("Unblock", segmentID)}
+// Import is a helper method to define mock.On call
+//   - tasks []TaskInfo
+func (_e *MockSyncManager_Expecter) Import(tasks interface{}) *MockSyncManager_Import_Call {
+	return &MockSyncManager_Import_Call{Call: _e.mock.On("Import", tasks)}
 }
 
-func (_c *MockSyncManager_Unblock_Call) Run(run func(segmentID int64)) *MockSyncManager_Unblock_Call {
+func (_c *MockSyncManager_Import_Call) Run(run func(tasks []TaskInfo)) *MockSyncManager_Import_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64))
+		run(args[0].([]TaskInfo))
 	})
 	return _c
 }
 
-func (_c *MockSyncManager_Unblock_Call) Return() *MockSyncManager_Unblock_Call {
-	_c.Call.Return()
+func (_c *MockSyncManager_Import_Call) Return(_a0 error) *MockSyncManager_Import_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockSyncManager_Unblock_Call) RunAndReturn(run func(int64)) *MockSyncManager_Unblock_Call {
+func (_c *MockSyncManager_Import_Call) RunAndReturn(run func([]TaskInfo) error) *MockSyncManager_Import_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InFlightBytes provides a mock function with given fields:
+func (_m *MockSyncManager) InFlightBytes() int64 {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	return r0
+}
+
+// MockSyncManager_InFlightBytes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InFlightBytes'
+type MockSyncManager_InFlightBytes_Call struct {
+	*mock.Call
+}
+
+// InFlightBytes is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) InFlightBytes() *MockSyncManager_InFlightBytes_Call {
+	return &MockSyncManager_InFlightBytes_Call{Call: _e.mock.On("InFlightBytes")}
+}
+
+func (_c *MockSyncManager_InFlightBytes_Call) Run(run func()) *MockSyncManager_InFlightBytes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_InFlightBytes_Call) Return(_a0 int64) *MockSyncManager_InFlightBytes_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_InFlightBytes_Call) RunAndReturn(run func() int64) *MockSyncManager_InFlightBytes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LastError provides a mock function with given fields: segmentID
+func (_m *MockSyncManager) LastError(segmentID int64) error {
+	ret := _m.Called(segmentID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(segmentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSyncManager_LastError_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LastError'
+type MockSyncManager_LastError_Call struct {
+	*mock.Call
+}
+
+// LastError is a helper method to define mock.On call
+//   - segmentID int64
+func (_e *MockSyncManager_Expecter) LastError(segmentID interface{}) *MockSyncManager_LastError_Call {
+	return &MockSyncManager_LastError_Call{Call: _e.mock.On("LastError", segmentID)}
+}
+
+func (_c *MockSyncManager_LastError_Call) Run(run func(segmentID int64)) *MockSyncManager_LastError_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_LastError_Call) Return(_a0 error) *MockSyncManager_LastError_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_LastError_Call) RunAndReturn(run func(int64) error) *MockSyncManager_LastError_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTasks provides a mock function with given fields:
+func (_m *MockSyncManager) ListTasks() []TaskInfo {
+	ret := _m.Called()
+
+	var r0 []TaskInfo
+	if rf, ok := ret.Get(0).(func() []TaskInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]TaskInfo)
+		}
+	}
+
+	return r0
+}
+
+// MockSyncManager_ListTasks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTasks'
+type MockSyncManager_ListTasks_Call struct {
+	*mock.Call
+}
+
+// ListTasks is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) ListTasks() *MockSyncManager_ListTasks_Call {
+	return &MockSyncManager_ListTasks_Call{Call: _e.mock.On("ListTasks")}
+}
+
+func (_c *MockSyncManager_ListTasks_Call) Run(run func()) *MockSyncManager_ListTasks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_ListTasks_Call) Return(_a0 []TaskInfo) *MockSyncManager_ListTasks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_ListTasks_Call) RunAndReturn(run func() []TaskInfo) *MockSyncManager_ListTasks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegisterFlushSource provides a mock function with given fields: channel, source
+func (_m *MockSyncManager) RegisterFlushSource(channel string, source FlushSource) {
+	_m.Called(channel, source)
+}
+
+// MockSyncManager_RegisterFlushSource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterFlushSource'
+type MockSyncManager_RegisterFlushSource_Call struct {
+	*mock.Call
+}
+
+// RegisterFlushSource is a helper method to define mock.On call
+//   - channel string
+//   - source FlushSource
+func (_e *MockSyncManager_Expecter) RegisterFlushSource(channel interface{}, source interface{}) *MockSyncManager_RegisterFlushSource_Call {
+	return &MockSyncManager_RegisterFlushSource_Call{Call: _e.mock.On("RegisterFlushSource", channel, source)}
+}
+
+func (_c *MockSyncManager_RegisterFlushSource_Call) Run(run func(channel string, source FlushSource)) *MockSyncManager_RegisterFlushSource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(FlushSource))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_RegisterFlushSource_Call) Return() *MockSyncManager_RegisterFlushSource_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSyncManager_RegisterFlushSource_Call) RunAndReturn(run func(string, FlushSource)) *MockSyncManager_RegisterFlushSource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReorderBufferDepth provides a mock function with given fields: segmentID
+func (_m *MockSyncManager) ReorderBufferDepth(segmentID int64) int {
+	ret := _m.Called(segmentID)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int64) int); ok {
+		r0 = rf(segmentID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// MockSyncManager_ReorderBufferDepth_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReorderBufferDepth'
+type MockSyncManager_ReorderBufferDepth_Call struct {
+	*mock.Call
+}
+
+// ReorderBufferDepth is a helper method to define mock.On call
+//   - segmentID int64
+func (_e *MockSyncManager_Expecter) ReorderBufferDepth(segmentID interface{}) *MockSyncManager_ReorderBufferDepth_Call {
+	return &MockSyncManager_ReorderBufferDepth_Call{Call: _e.mock.On("ReorderBufferDepth", segmentID)}
+}
+
+func (_c *MockSyncManager_ReorderBufferDepth_Call) Run(run func(segmentID int64)) *MockSyncManager_ReorderBufferDepth_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_ReorderBufferDepth_Call) Return(_a0 int) *MockSyncManager_ReorderBufferDepth_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_ReorderBufferDepth_Call) RunAndReturn(run func(int64) int) *MockSyncManager_ReorderBufferDepth_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RunningPartitionSegments provides a mock function with given fields:
+func (_m *MockSyncManager) RunningPartitionSegments() []SegmentKey {
+	ret := _m.Called()
+
+	var r0 []SegmentKey
+	if rf, ok := ret.Get(0).(func() []SegmentKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SegmentKey)
+		}
+	}
+
+	return r0
+}
+
+// MockSyncManager_RunningPartitionSegments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RunningPartitionSegments'
+type MockSyncManager_RunningPartitionSegments_Call struct {
+	*mock.Call
+}
+
+// RunningPartitionSegments is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) RunningPartitionSegments() *MockSyncManager_RunningPartitionSegments_Call {
+	return &MockSyncManager_RunningPartitionSegments_Call{Call: _e.mock.On("RunningPartitionSegments")}
+}
+
+func (_c *MockSyncManager_RunningPartitionSegments_Call) Run(run func()) *MockSyncManager_RunningPartitionSegments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_RunningPartitionSegments_Call) Return(_a0 []SegmentKey) *MockSyncManager_RunningPartitionSegments_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_RunningPartitionSegments_Call) RunAndReturn(run func() []SegmentKey) *MockSyncManager_RunningPartitionSegments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RunningSegments provides a mock function with given fields:
+func (_m *MockSyncManager) RunningSegments() []int64 {
+	ret := _m.Called()
+
+	var r0 []int64
+	if rf, ok := ret.Get(0).(func() []int64); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	return r0
+}
+
+// MockSyncManager_RunningSegments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RunningSegments'
+type MockSyncManager_RunningSegments_Call struct {
+	*mock.Call
+}
+
+// RunningSegments is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) RunningSegments() *MockSyncManager_RunningSegments_Call {
+	return &MockSyncManager_RunningSegments_Call{Call: _e.mock.On("RunningSegments")}
+}
+
+func (_c *MockSyncManager_RunningSegments_Call) Run(run func()) *MockSyncManager_RunningSegments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_RunningSegments_Call) Return(_a0 []int64) *MockSyncManager_RunningSegments_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_RunningSegments_Call) RunAndReturn(run func() []int64) *MockSyncManager_RunningSegments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SyncData provides a mock function with given fields: ctx, task
+func (_m *MockSyncManager) SyncData(ctx context.Context, task Task) *conc.Future[error] {
+	ret := _m.Called(ctx, task)
+
+	var r0 *conc.Future[error]
+	if rf, ok := ret.Get(0).(func(context.Context, Task) *conc.Future[error]); ok {
+		r0 = rf(ctx, task)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*conc.Future[error])
+		}
+	}
+
+	return r0
+}
+
+// MockSyncManager_SyncData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncData'
+type MockSyncManager_SyncData_Call struct {
+	*mock.Call
+}
+
+// SyncData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - task Task
+func (_e *MockSyncManager_Expecter) SyncData(ctx interface{}, task interface{}) *MockSyncManager_SyncData_Call {
+	return &MockSyncManager_SyncData_Call{Call: _e.mock.On("SyncData", ctx, task)}
+}
+
+func (_c *MockSyncManager_SyncData_Call) Run(run func(ctx context.Context, task Task)) *MockSyncManager_SyncData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(Task))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_SyncData_Call) Return(_a0 *conc.Future[error]) *MockSyncManager_SyncData_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_SyncData_Call) RunAndReturn(run func(context.Context, Task) *conc.Future[error]) *MockSyncManager_SyncData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SyncDataWithStats provides a mock function with given fields: ctx, task
+func (_m *MockSyncManager) SyncDataWithStats(ctx context.Context, task Task) *conc.Future[SyncResult] {
+	ret := _m.Called(ctx, task)
+
+	var r0 *conc.Future[SyncResult]
+	if rf, ok := ret.Get(0).(func(context.Context, Task) *conc.Future[SyncResult]); ok {
+		r0 = rf(ctx, task)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*conc.Future[SyncResult])
+		}
+	}
+
+	return r0
+}
+
+// MockSyncManager_SyncDataWithStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncDataWithStats'
+type MockSyncManager_SyncDataWithStats_Call struct {
+	*mock.Call
+}
+
+// SyncDataWithStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - task Task
+func (_e *MockSyncManager_Expecter) SyncDataWithStats(ctx interface{}, task interface{}) *MockSyncManager_SyncDataWithStats_Call {
+	return &MockSyncManager_SyncDataWithStats_Call{Call: _e.mock.On("SyncDataWithStats", ctx, task)}
+}
+
+func (_c *MockSyncManager_SyncDataWithStats_Call) Run(run func(ctx context.Context, task Task)) *MockSyncManager_SyncDataWithStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(Task))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_SyncDataWithStats_Call) Return(_a0 *conc.Future[SyncResult]) *MockSyncManager_SyncDataWithStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_SyncDataWithStats_Call) RunAndReturn(run func(context.Context, Task) *conc.Future[SyncResult]) *MockSyncManager_SyncDataWithStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TaskStats provides a mock function with given fields:
+func (_m *MockSyncManager) TaskStats() SyncStats {
+	ret := _m.Called()
+
+	var r0 SyncStats
+	if rf, ok := ret.Get(0).(func() SyncStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(SyncStats)
+	}
+
+	return r0
+}
+
+// MockSyncManager_TaskStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TaskStats'
+type MockSyncManager_TaskStats_Call struct {
+	*mock.Call
+}
+
+// TaskStats is a helper method to define mock.On call
+func (_e *MockSyncManager_Expecter) TaskStats() *MockSyncManager_TaskStats_Call {
+	return &MockSyncManager_TaskStats_Call{Call: _e.mock.On("TaskStats")}
+}
+
+func (_c *MockSyncManager_TaskStats_Call) Run(run func()) *MockSyncManager_TaskStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_TaskStats_Call) Return(_a0 SyncStats) *MockSyncManager_TaskStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_TaskStats_Call) RunAndReturn(run func() SyncStats) *MockSyncManager_TaskStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unblock provides a mock function with given fields: segmentID
+func (_m *MockSyncManager) Unblock(segmentID int64) {
+	_m.Called(segmentID)
+}
+
+// MockSyncManager_Unblock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unblock'
+type MockSyncManager_Unblock_Call struct {
+	*mock.Call
+}
+
+// Unblock is a helper method to define mock.On call
+//   - segmentID int64
+func (_e *MockSyncManager_Expecter) Unblock(segmentID interface{}) *MockSyncManager_Unblock_Call {
+	return &MockSyncManager_Unblock_Call{Call: _e.mock.On("Unblock", segmentID)}
+}
+
+func (_c *MockSyncManager_Unblock_Call) Run(run func(segmentID int64)) *MockSyncManager_Unblock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_Unblock_Call) Return() *MockSyncManager_Unblock_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSyncManager_Unblock_Call) RunAndReturn(run func(int64)) *MockSyncManager_Unblock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnregisterFlushSource provides a mock function with given fields: channel
+func (_m *MockSyncManager) UnregisterFlushSource(channel string) {
+	_m.Called(channel)
+}
+
+// MockSyncManager_UnregisterFlushSource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnregisterFlushSource'
+type MockSyncManager_UnregisterFlushSource_Call struct {
+	*mock.Call
+}
+
+// UnregisterFlushSource is a helper method to define mock.On call
+//   - channel string
+func (_e *MockSyncManager_Expecter) UnregisterFlushSource(channel interface{}) *MockSyncManager_UnregisterFlushSource_Call {
+	return &MockSyncManager_UnregisterFlushSource_Call{Call: _e.mock.On("UnregisterFlushSource", channel)}
+}
+
+func (_c *MockSyncManager_UnregisterFlushSource_Call) Run(run func(channel string)) *MockSyncManager_UnregisterFlushSource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_UnregisterFlushSource_Call) Return() *MockSyncManager_UnregisterFlushSource_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSyncManager_UnregisterFlushSource_Call) RunAndReturn(run func(string)) *MockSyncManager_UnregisterFlushSource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Validate provides a mock function with given fields: task
+func (_m *MockSyncManager) Validate(task Task) error {
+	ret := _m.Called(task)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Task) error); ok {
+		r0 = rf(task)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSyncManager_Validate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Validate'
+type MockSyncManager_Validate_Call struct {
+	*mock.Call
+}
+
+// Validate is a helper method to define mock.On call
+//   - task Task
+func (_e *MockSyncManager_Expecter) Validate(task interface{}) *MockSyncManager_Validate_Call {
+	return &MockSyncManager_Validate_Call{Call: _e.mock.On("Validate", task)}
+}
+
+func (_c *MockSyncManager_Validate_Call) Run(run func(task Task)) *MockSyncManager_Validate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Task))
+	})
+	return _c
+}
+
+func (_c *MockSyncManager_Validate_Call) Return(_a0 error) *MockSyncManager_Validate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSyncManager_Validate_Call) RunAndReturn(run func(Task) error) *MockSyncManager_Validate_Call {
 	_c.Call.Return(run)
 	return _c
 }
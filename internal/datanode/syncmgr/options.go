@@ -86,6 +86,16 @@ func (t *SyncTask) WithFlush() *SyncTask {
 	return t
 }
 
+func (t *SyncTask) WithOrigin(origin TaskOrigin) *SyncTask {
+	t.origin = origin
+	return t
+}
+
+func (t *SyncTask) WithPriority(priority int) *SyncTask {
+	t.priority = priority
+	return t
+}
+
 func (t *SyncTask) WithDrop() *SyncTask {
 	t.isDrop = true
 	return t
@@ -106,6 +116,26 @@ func (t *SyncTask) WithWriteRetryOptions(opts ...retry.Option) *SyncTask {
 	return t
 }
 
+func (t *SyncTask) WithMultipartThreshold(threshold int64) *SyncTask {
+	t.multipartThreshold = threshold
+	return t
+}
+
+func (t *SyncTask) WithBlobCodec(codec storage.BlobCodec) *SyncTask {
+	t.blobCodec = codec
+	return t
+}
+
+func (t *SyncTask) WithBlobChecksum(enabled bool) *SyncTask {
+	t.blobChecksum = enabled
+	return t
+}
+
+func (t *SyncTask) WithSerializeParallelism(parallelism int) *SyncTask {
+	t.serializeParallelism = parallelism
+	return t
+}
+
 func (t *SyncTask) WithFailureCallback(callback func(error)) *SyncTask {
 	t.failureCallback = callback
 	return t
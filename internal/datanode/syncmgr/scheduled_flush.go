@@ -0,0 +1,111 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/util/typeutil"
+)
+
+// FlushSource is the buffer of a channel which could be flushed by the scheduled sweep,
+// normally implemented by the write buffer.
+type FlushSource interface {
+	// StaleSegments returns the segments whose buffered data is older than maxAge.
+	StaleSegments(maxAge time.Duration) []int64
+	// SyncSegments submits sync tasks for provided segments.
+	SyncSegments(ctx context.Context, segmentIDs []int64) error
+}
+
+// flushScheduler periodically flushes stale buffers of registered channels,
+// so that channels without enough traffic to hit other triggers are still flushed.
+type flushScheduler struct {
+	interval time.Duration
+	maxAge   time.Duration
+	sources  *typeutil.ConcurrentMap[string, FlushSource]
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	done     chan struct{}
+}
+
+func newFlushScheduler() *flushScheduler {
+	return &flushScheduler{
+		sources: typeutil.NewConcurrentMap[string, FlushSource](),
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start sweeps the registered channels in background until stop is called,
+// does nothing if schedule is not configured.
+func (s *flushScheduler) start(mgr syncManager) {
+	if s.interval <= 0 || s.maxAge <= 0 {
+		close(s.done)
+		return
+	}
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				s.sweep(mgr)
+			}
+		}
+	}()
+}
+
+// stop stops the background sweep started by start and waits for the ongoing sweep to finish.
+func (s *flushScheduler) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.done
+	})
+}
+
+// sweep submits flush of stale segments for each channel,
+// the segment already being synced (the one holding earliest position) is skipped.
+func (s *flushScheduler) sweep(mgr syncManager) {
+	s.sources.Range(func(channel string, source FlushSource) bool {
+		syncingSegment, syncingPos := mgr.GetEarliestPosition(channel)
+		segmentIDs := lo.Filter(source.StaleSegments(s.maxAge), func(segmentID int64, _ int) bool {
+			return syncingPos == nil || segmentID != syncingSegment
+		})
+		if len(segmentIDs) == 0 {
+			return true
+		}
+
+		log.Info("scheduled flush stale segments",
+			zap.String("channel", channel),
+			zap.Int64s("segmentIDs", segmentIDs),
+			zap.Duration("maxAge", s.maxAge))
+		if err := source.SyncSegments(context.Background(), segmentIDs); err != nil {
+			log.Warn("failed to flush stale segments", zap.String("channel", channel), zap.Error(err))
+		}
+		return true
+	})
+}
@@ -3,15 +3,26 @@ package syncmgr
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/msgpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
 	"github.com/milvus-io/milvus/internal/allocator"
 	"github.com/milvus-io/milvus/internal/datanode/metacache"
 	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/metrics"
 	"github.com/milvus-io/milvus/pkg/util/conc"
 	"github.com/milvus-io/milvus/pkg/util/merr"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/util/retry"
 	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
@@ -21,6 +32,147 @@ type SyncManagerOption struct {
 	parallelTask int
 }
 
+// TaskKeyFunc derives the key to track submitted task with,
+// seq is the monotonic submit sequence of the sync manager.
+type TaskKeyFunc func(task Task, seq int64) string
+
+// DefaultTaskKey uses collection id, partition id, segment id, checkpoint timestamp and submit sequence as task key,
+// so that keys are unique even if tasks share the same segment and checkpoint, and the tasks could be accounted
+// per partition by key. Tasks without partition use zero collection and partition ids.
+func DefaultTaskKey(task Task, seq int64) string {
+	collectionID, partitionID := getTaskPartition(task)
+	return fmt.Sprintf("%d-%d-%d-%d-%d", collectionID, partitionID, task.SegmentID(), task.Checkpoint().GetTimestamp(), seq)
+}
+
+// SyncManagerOpt is the optional parameter of NewSyncManager.
+type SyncManagerOpt func(mgr *syncManager)
+
+// WithTaskKeyFunc sets the key strategy used to track submitted tasks.
+func WithTaskKeyFunc(fn TaskKeyFunc) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.taskKey = fn
+	}
+}
+
+// WithTaskDedup makes a task re-submitted while an unfinished task of the same segment and checkpoint is in flight
+// share the result of the unfinished one instead of running again, e.g. to absorb the retries of upstream.
+// Disabled by default, in which case every submitted task runs.
+func WithTaskDedup(enabled bool) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.dedup = nil
+		if enabled {
+			mgr.dedup = newTaskDeduper()
+		}
+	}
+}
+
+// WithCompletionCallback sets the callback invoked after each task finishes,
+// callbacks of the same segment are invoked in ascending checkpoint timestamp order.
+func WithCompletionCallback(fn func(task Task, err error)) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.completionCallback = fn
+	}
+}
+
+// WithFirstSyncCallback sets the callback invoked once per segment after its first successful sync,
+// later syncs of the same segment do not trigger it again.
+func WithFirstSyncCallback(fn func(segmentID int64)) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.firstSyncCallback = fn
+	}
+}
+
+// WithInFlightBytesCap caps the total payload size of in-flight tasks,
+// SyncData blocks until the new task fits. Non-positive value means unlimited.
+func WithInFlightBytesCap(cap int64) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.inFlight = newInFlightLimiter(cap)
+	}
+}
+
+// WithMaxQueueDepth makes SyncData reject new tasks with ErrServiceUnavailable
+// once n tasks are pending, so that the caller could apply backpressure. Non-positive n means unlimited.
+func WithMaxQueueDepth(n int) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.maxQueueDepth = n
+	}
+}
+
+// WithScheduledFlush makes the manager check registered channels every interval,
+// and flush the segments whose buffered data is older than maxAge.
+func WithScheduledFlush(interval, maxAge time.Duration) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.scheduler.interval = interval
+		mgr.scheduler.maxAge = maxAge
+	}
+}
+
+// WithChannelParallelism caps the number of running tasks of each channel,
+// so that a channel with many segments could not take all the workers. Non-positive n means unlimited.
+func WithChannelParallelism(n int) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.queue.channelLimit = n
+	}
+}
+
+// WithMultipartThreshold makes tasks without their own threshold upload the blobs larger than threshold bytes
+// via ChunkManager.MultipartWrite. Non-positive threshold means disabled.
+func WithMultipartThreshold(threshold int64) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.multipartThreshold = threshold
+	}
+}
+
+// WithBlobCodec makes tasks without their own codec compress the serialized delta and stats logs with codec before uploading,
+// insert binlogs are uploaded uncompressed.
+func WithBlobCodec(codec storage.BlobCodec) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.blobCodec = codec
+	}
+}
+
+// WithBlobChecksum makes all tasks upload the CRC32C of each blob as a sidecar object, which is verified on read.
+func WithBlobChecksum(enabled bool) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.blobChecksum = enabled
+	}
+}
+
+// WithSerializeParallelism makes tasks without their own parallelism serialize at most parallelism columns concurrently.
+func WithSerializeParallelism(parallelism int) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.serializeParallelism = parallelism
+	}
+}
+
+// TaskHooks observes the lifecycle of sync tasks, nil hooks are skipped.
+// Tasks rejected by SyncData fire no hook, cancelled tasks fire OnComplete without OnStart.
+type TaskHooks struct {
+	// OnEnqueue is invoked once the task is tracked, before it waits for the segment lock.
+	OnEnqueue func(segmentID int64, taskKey string)
+	// OnStart is invoked in the worker right before the task runs.
+	OnStart func(segmentID int64, taskKey string)
+	// OnComplete is invoked after the task finishes and releases the segment lock.
+	OnComplete func(segmentID int64, taskKey string, err error)
+}
+
+// WithTaskHooks sets the hooks invoked when tasks are queued, start running and finish,
+// e.g. to trace the tasks.
+func WithTaskHooks(hooks TaskHooks) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.hooks = hooks
+	}
+}
+
+// WithWriteRetry makes tasks without their own write retry options retry failed uploads
+// at most maxRetry times, the interval starts from backoff and doubles after each retry.
+// Retries upload the same serialized logs, so that no log id is allocated again.
+func WithWriteRetry(maxRetry uint, backoff time.Duration) SyncManagerOpt {
+	return func(mgr *syncManager) {
+		mgr.writeRetryOpts = []retry.Option{retry.Attempts(maxRetry + 1), retry.Sleep(backoff)}
+	}
+}
+
 type SyncMeta struct {
 	collectionID int64
 	partitionID  int64
@@ -38,15 +190,88 @@ type SyncMeta struct {
 // it processes the sync tasks inside and changes the meta.
 type SyncManager interface {
 	// SyncData is the method to submit sync task.
+	// If dedup is enabled by WithTaskDedup, task of the same segment and checkpoint as an unfinished one is not run,
+	// it shares the result of the unfinished one.
 	SyncData(ctx context.Context, task Task) *conc.Future[error]
+	// SyncDataWithStats submits sync task like SyncData,
+	// the result also carries the size and number of objects persisted by the task.
+	SyncDataWithStats(ctx context.Context, task Task) *conc.Future[SyncResult]
 	// GetEarliestPosition returns the earliest position (normally start position) of the processing sync task of provided channel.
+	// It is evaluated from the tracked tasks on each call, so blocked and pending tasks are always included
+	// while finished and cancelled ones are not.
 	GetEarliestPosition(channel string) (int64, *msgpb.MsgPosition)
+	// GetEarliestRunningPosition is like GetEarliestPosition, but only takes the tasks which hold the segment lock,
+	// including the ones waiting for a free worker, tasks still waiting for the segment lock are excluded.
+	GetEarliestRunningPosition(channel string) (int64, *msgpb.MsgPosition)
+	// GetEarliestPartitionPosition is like GetEarliestPosition, but only takes the tasks of provided partition.
+	GetEarliestPartitionPosition(channel string, partitionID int64) (int64, *msgpb.MsgPosition)
 	// Block allows caller to block tasks of provided segment id.
 	// normally used by compaction task.
 	// if levelzero delta policy is enabled, this shall be an empty operation.
 	Block(segmentID int64)
 	// Unblock is the reverse method for `Block`.
 	Unblock(segmentID int64)
+	// CancelSegment cancels all tasks of provided segment, pending tasks finish with context.Canceled
+	// without running, running tasks observe the cancellation via their context and skip the upload if possible.
+	CancelSegment(segmentID int64)
+	// CancelChannel cancels all pending tasks of provided channel,
+	// normally used when the channel is handed off to another datanode.
+	// Running tasks are not affected.
+	CancelChannel(channel string)
+	// FlushBarrier waits until all tasks of provided channel submitted before the call finish.
+	// Tasks submitted after the call are not waited on.
+	FlushBarrier(ctx context.Context, channel string) error
+	// AverageUtilization returns the average ratio of busy workers in the latest minute, ranges in [0, 1].
+	AverageUtilization() float64
+	// Export cancels all pending tasks and returns them, so that another manager could take them over with Import.
+	// Running tasks are not exported and keep running in current manager, since a half done upload could not
+	// be handed over, callers shall wait for them with FlushBarrier before the channel is released.
+	Export() []TaskInfo
+	// Import submits the tasks exported from another manager, the tasks run asynchronously.
+	// It returns the combined errors of tasks rejected on submit, e.g. by a closed or full manager,
+	// the other tasks are submitted anyway.
+	Import(tasks []TaskInfo) error
+	// ListTasks returns the tracked tasks, including pending and running ones.
+	ListTasks() []TaskInfo
+	// RunningSegments returns the ids of segments which have pending or running tasks, in ascending order.
+	RunningSegments() []int64
+	// RunningPartitionSegments is like RunningSegments, but segments of the same id under different partitions
+	// are returned separately, ordered by collection, partition and segment id.
+	RunningPartitionSegments() []SegmentKey
+	// LastError returns the error of the latest failed task of provided segment,
+	// nil if no task failed or the latest task succeeded.
+	LastError(segmentID int64) error
+	// ReorderBufferDepth returns the number of finished tasks of provided segment whose completions are held back
+	// waiting for an earlier task, a growing depth indicates the earlier task is stuck.
+	ReorderBufferDepth(segmentID int64) int
+	// TaskStats returns the numbers of pending and running tasks and the oldest checkpoint of each channel.
+	TaskStats() SyncStats
+	// InFlightBytes returns the total payload size of the submitted tasks which are not finished yet.
+	InFlightBytes() int64
+	// RegisterFlushSource adds the buffer of provided channel to the scheduled flush.
+	RegisterFlushSource(channel string, source FlushSource)
+	// UnregisterFlushSource removes the buffer of provided channel from the scheduled flush.
+	UnregisterFlushSource(channel string)
+	// Validate runs the serialization of task without uploading anything,
+	// returns the error which a real sync would hit before the upload.
+	Validate(task Task) error
+	// Close stops accepting new tasks and waits until all tracked tasks finish or ctx is done,
+	// the returned error reports the number of unfinished tasks on timeout.
+	// Tasks submitted after Close finish with ErrServiceUnavailable without running.
+	Close(ctx context.Context) error
+}
+
+// TaskInfo describes a task exported from sync manager.
+type TaskInfo struct {
+	Key          string
+	TaskID       string
+	CollectionID int64
+	PartitionID  int64
+	SegmentID    int64
+	Channel      string
+	Origin       TaskOrigin
+	SubmitTs     time.Time
+	Task         Task
 }
 
 type syncManager struct {
@@ -54,45 +279,269 @@ type syncManager struct {
 	chunkManager storage.ChunkManager
 	allocator    allocator.Interface
 
-	tasks *typeutil.ConcurrentMap[string, Task]
+	tasks    *typeutil.ConcurrentMap[string, *trackedTask]
+	dedup    *taskDeduper
+	taskKey  TaskKeyFunc
+	seq      *atomic.Int64
+	idPrefix string
+	// submitMu orders the submit sequence with the tracking of tasks,
+	// so that a barrier sees every task with a sequence up to the one it observed
+	submitMu *sync.Mutex
+	// admitting holds the tasks waiting for the in-flight cap by submit sequence, they are not in tasks yet
+	admitting   *typeutil.ConcurrentMap[int64, *trackedTask]
+	utilization *utilizationSampler
+
+	completions        *completionSequencer
+	completionCallback func(task Task, err error)
+
+	// synced records segments which have been synced successfully
+	synced            *typeutil.ConcurrentSet[int64]
+	firstSyncCallback func(segmentID int64)
+
+	lastErrors *typeutil.ConcurrentMap[int64, error]
+	inFlight   *inFlightLimiter
+
+	// pending is the number of submitted tasks not running yet
+	pending       *atomic.Int64
+	maxQueueDepth int
+
+	scheduler     *flushScheduler
+	statsReporter *statsReporter
+
+	// writeRetryOpts is the default write retry options of tasks
+	writeRetryOpts []retry.Option
+	// multipartThreshold is the default multipart upload threshold of tasks
+	multipartThreshold int64
+	// blobCodec is the default blob compression of tasks
+	blobCodec storage.BlobCodec
+	// blobChecksum enables the checksum sidecar of all tasks
+	blobChecksum bool
+	// serializeParallelism is the default column serialization parallelism of tasks
+	serializeParallelism int
+
+	hooks TaskHooks
+
+	closed *atomic.Bool
 }
 
-func NewSyncManager(parallelTask int, chunkManager storage.ChunkManager, allocator allocator.Interface) (SyncManager, error) {
+func NewSyncManager(parallelTask int, chunkManager storage.ChunkManager, allocator allocator.Interface, opts ...SyncManagerOpt) (SyncManager, error) {
 	if parallelTask < 1 {
 		return nil, merr.WrapErrParameterInvalid("positive parallel task number", strconv.FormatInt(int64(parallelTask), 10))
 	}
-	return &syncManager{
+	mgr := &syncManager{
 		keyLockDispatcher: newKeyLockDispatcher[int64](parallelTask),
 		chunkManager:      chunkManager,
 		allocator:         allocator,
-		tasks:             typeutil.NewConcurrentMap[string, Task](),
-	}, nil
+		tasks:             typeutil.NewConcurrentMap[string, *trackedTask](),
+		taskKey:           DefaultTaskKey,
+		seq:               atomic.NewInt64(0),
+		submitMu:          &sync.Mutex{},
+		admitting:         typeutil.NewConcurrentMap[int64, *trackedTask](),
+		idPrefix:          strconv.FormatInt(time.Now().UnixNano(), 36),
+		utilization:       newUtilizationSampler(),
+		completions:       newCompletionSequencer(),
+		synced:            typeutil.NewConcurrentSet[int64](),
+		lastErrors:        typeutil.NewConcurrentMap[int64, error](),
+		inFlight:          newInFlightLimiter(0),
+		pending:           atomic.NewInt64(0),
+		scheduler:         newFlushScheduler(),
+		statsReporter:     newStatsReporter(),
+		closed:            atomic.NewBool(false),
+	}
+	for _, opt := range opts {
+		opt(mgr)
+	}
+	mgr.utilization.start(mgr.workerPool)
+	mgr.scheduler.start(*mgr)
+	mgr.statsReporter.start(*mgr)
+	return mgr, nil
 }
 
 func (mgr syncManager) SyncData(ctx context.Context, task Task) *conc.Future[error] {
+	future, err := mgr.submit(ctx, task)
+	if err != nil {
+		return conc.Go(func() (error, error) { return err, nil })
+	}
+	return future
+}
+
+// submit submits task like SyncData, but returns the error directly if task is rejected.
+func (mgr syncManager) submit(ctx context.Context, task Task) (*conc.Future[error], error) {
 	switch t := task.(type) {
 	case *SyncTask:
 		t.WithAllocator(mgr.allocator).WithChunkManager(mgr.chunkManager)
+		if t.writeRetryOpts == nil {
+			t.WithWriteRetryOptions(mgr.writeRetryOpts...)
+		}
+		if t.multipartThreshold == 0 {
+			t.WithMultipartThreshold(mgr.multipartThreshold)
+		}
+		if t.blobCodec == "" {
+			t.WithBlobCodec(mgr.blobCodec)
+		}
+		if mgr.blobChecksum {
+			t.WithBlobChecksum(true)
+		}
+		if t.serializeParallelism == 0 {
+			t.WithSerializeParallelism(mgr.serializeParallelism)
+		}
 	case *SyncTaskV2:
 		t.WithAllocator(mgr.allocator)
+		if t.writeRetryOpts == nil {
+			t.WithWriteRetryOptions(mgr.writeRetryOpts...)
+		}
 	}
 
-	taskKey := fmt.Sprintf("%d-%d", task.SegmentID(), task.Checkpoint().GetTimestamp())
-	mgr.tasks.Insert(taskKey, task)
+	if mgr.closed.Load() {
+		err := merr.WrapErrServiceUnavailable("sync manager closed")
+		log.Warn("sync task rejected", zap.Int64("segmentID", task.SegmentID()), zap.Error(err))
+		return nil, err
+	}
 
-	// make sync for same segment execute in sequence
-	// if previous sync task is not finished, block here
-	return mgr.Submit(task.SegmentID(), task, func(err error) {
+	if pending := mgr.pending.Inc(); mgr.maxQueueDepth > 0 && pending > int64(mgr.maxQueueDepth) {
+		mgr.pending.Dec()
+		err := merr.WrapErrServiceUnavailable(fmt.Sprintf("sync queue is full, max depth %d", mgr.maxQueueDepth))
+		log.Warn("sync task rejected", zap.Int64("segmentID", task.SegmentID()), zap.Error(err))
+		return nil, err
+	}
+
+	// task re-submitted while the previous one is in flight shares its result instead of writing again
+	dedupKey := getTaskDedupKey(task)
+	tracked := newTrackedTask(task, "")
+	if existing, loaded := mgr.dedup.getOrAdd(dedupKey, tracked); loaded {
+		mgr.pending.Dec()
+		log.Info("duplicate sync task, wait for the in-flight one",
+			zap.Int64("segmentID", task.SegmentID()),
+			zap.Uint64("checkpointTs", task.Checkpoint().GetTimestamp()))
+		return conc.Go(func() (error, error) {
+			<-existing.done
+			return existing.err, nil
+		}), nil
+	}
+
+	mgr.submitMu.Lock()
+	seq := mgr.seq.Inc()
+	taskID := mgr.idPrefix + "-" + strconv.FormatInt(seq, 10)
+	tracked.taskID = taskID
+	tracked.seq = seq
+	tracked.onLeavePending = func() { mgr.pending.Dec() }
+	mgr.admitting.Insert(seq, tracked)
+	mgr.submitMu.Unlock()
+
+	// block until the payload fits in the in-flight cap
+	payloadSize := getTaskPayloadSize(task)
+	mgr.inFlight.acquire(payloadSize)
+
+	ctx, cancel := context.WithCancel(log.WithFields(ctx, zap.String("taskID", taskID)))
+	if t, ok := task.(contextualTask); ok {
+		t.setContext(ctx)
+	}
+	log := log.Ctx(ctx).With(zap.Int64("segmentID", task.SegmentID()))
+
+	taskKey := mgr.taskKey(task, seq)
+	tracked.cancelCtx = cancel
+	if mgr.hooks.OnStart != nil {
+		tracked.onStart = func() { mgr.hooks.OnStart(task.SegmentID(), taskKey) }
+	}
+	mgr.submitMu.Lock()
+	_, loaded := mgr.tasks.GetOrInsert(taskKey, tracked)
+	mgr.admitting.Remove(seq)
+	mgr.submitMu.Unlock()
+	if loaded {
+		// overwriting would hide the tracked task from the lookups, reject the new one instead
+		err := merr.WrapErrParameterInvalidMsg("sync task key %s conflicts with a tracked task", taskKey)
+		log.Warn("sync task rejected", zap.String("taskKey", taskKey), zap.Error(err))
+		mgr.dedup.remove(dedupKey, tracked)
+		// leaves pending unless the task is already cancelled
+		tracked.cancel(err)
+		tracked.finish(err)
+		cancel()
+		mgr.inFlight.release(payloadSize)
+		return nil, err
+	}
+	log.Debug("sync task submitted",
+		zap.String("taskKey", taskKey),
+		zap.String("origin", string(getTaskOrigin(task))))
+
+	// completions of the same segment are emitted in checkpoint order,
+	// even if tasks are submitted out of order
+	c := mgr.completions.register(task.SegmentID(), task.Checkpoint().GetTimestamp(), func(err error) {
 		// remove task from records
 		mgr.tasks.Remove(taskKey)
+		mgr.dedup.remove(dedupKey, tracked)
+		tracked.finish(err)
+		cancel()
+
+		status := metrics.SuccessLabel
+		if err != nil {
+			status = metrics.FailLabel
+			mgr.lastErrors.Insert(task.SegmentID(), err)
+		} else {
+			mgr.lastErrors.Remove(task.SegmentID())
+			if mgr.synced.Insert(task.SegmentID()) && mgr.firstSyncCallback != nil {
+				mgr.firstSyncCallback(task.SegmentID())
+			}
+		}
+		metrics.DataNodeSyncTaskCount.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), string(getTaskOrigin(task)), status).Inc()
+		log.Debug("sync task finished", zap.Error(err))
+		if mgr.completionCallback != nil {
+			mgr.completionCallback(task, err)
+		}
+	})
+
+	if mgr.hooks.OnEnqueue != nil {
+		mgr.hooks.OnEnqueue(task.SegmentID(), taskKey)
+	}
+
+	// make sync for same segment execute in sequence
+	// if previous sync task is not finished, block here
+	future := mgr.Submit(task.SegmentID(), tracked, func(err error) {
+		mgr.inFlight.release(payloadSize)
+		mgr.completions.complete(task.SegmentID(), c, err)
+	})
+	if mgr.hooks.OnComplete == nil {
+		return future, nil
+	}
+	// the future is done after the segment lock is released
+	return conc.Go(func() (error, error) {
+		err, _ := future.Await()
+		mgr.hooks.OnComplete(task.SegmentID(), taskKey, err)
+		return err, nil
+	}), nil
+}
+
+func (mgr syncManager) SyncDataWithStats(ctx context.Context, task Task) *conc.Future[SyncResult] {
+	future := mgr.SyncData(ctx, task)
+	return conc.Go(func() (SyncResult, error) {
+		err, _ := future.Await()
+		bytesWritten, filesWritten := getTaskWrittenStats(task)
+		return SyncResult{Err: err, BytesWritten: bytesWritten, FilesWritten: filesWritten}, nil
 	})
 }
 
 func (mgr syncManager) GetEarliestPosition(channel string) (int64, *msgpb.MsgPosition) {
+	return mgr.earliestPosition(channel, func(*trackedTask) bool { return true })
+}
+
+func (mgr syncManager) GetEarliestRunningPosition(channel string) (int64, *msgpb.MsgPosition) {
+	return mgr.earliestPosition(channel, func(task *trackedTask) bool {
+		return task.locked.Load() && task.state.Load() != taskCancelled
+	})
+}
+
+func (mgr syncManager) GetEarliestPartitionPosition(channel string, partitionID int64) (int64, *msgpb.MsgPosition) {
+	return mgr.earliestPosition(channel, func(task *trackedTask) bool {
+		_, taskPartitionID := getTaskPartition(task.Task)
+		return taskPartitionID == partitionID
+	})
+}
+
+// earliestPosition returns the earliest start position among the tracked tasks of channel accepted by filter.
+func (mgr syncManager) earliestPosition(channel string, filter func(*trackedTask) bool) (int64, *msgpb.MsgPosition) {
 	var cp *msgpb.MsgPosition
 	var segmentID int64
-	mgr.tasks.Range(func(_ string, task Task) bool {
-		if task.StartPosition() == nil {
+	mgr.tasks.Range(func(_ string, task *trackedTask) bool {
+		if task.StartPosition() == nil || !filter(task) {
 			return true
 		}
 		if task.ChannelName() == channel {
@@ -113,3 +562,240 @@ func (mgr syncManager) Block(segmentID int64) {
 func (mgr syncManager) Unblock(segmentID int64) {
 	mgr.keyLock.Unlock(segmentID)
 }
+
+func (mgr syncManager) CancelSegment(segmentID int64) {
+	mgr.tasks.Range(func(key string, task *trackedTask) bool {
+		if task.SegmentID() != segmentID {
+			return true
+		}
+		if task.cancel(errors.Wrapf(context.Canceled, "sync of segment %d cancelled", segmentID)) {
+			mgr.tasks.Remove(key)
+			log.Info("pending sync task cancelled",
+				zap.Int64("segmentID", segmentID),
+				zap.String("taskKey", key),
+				zap.String("taskID", task.taskID))
+			return true
+		}
+		// task is running, it could only be aborted via its context
+		task.cancelCtx()
+		log.Info("running sync task cancelled",
+			zap.Int64("segmentID", segmentID),
+			zap.String("taskKey", key),
+			zap.String("taskID", task.taskID))
+		return true
+	})
+}
+
+func (mgr syncManager) CancelChannel(channel string) {
+	mgr.tasks.Range(func(key string, task *trackedTask) bool {
+		if task.ChannelName() == channel &&
+			task.cancel(merr.WrapErrChannelNotAvailable(channel, "channel handed off")) {
+			mgr.tasks.Remove(key)
+			log.Info("pending sync task cancelled",
+				zap.String("channel", channel),
+				zap.Int64("segmentID", task.SegmentID()),
+				zap.String("origin", string(getTaskOrigin(task.Task))),
+				zap.String("taskKey", key),
+				zap.String("taskID", task.taskID))
+		}
+		return true
+	})
+}
+
+func (mgr syncManager) FlushBarrier(ctx context.Context, channel string) error {
+	// submit sequence is monotonic while the wall clock may step backwards,
+	// tasks still waiting for the in-flight cap are submitted before the barrier as well
+	mgr.submitMu.Lock()
+	barrierSeq := mgr.seq.Load()
+	var pending []*trackedTask
+	collect := func(task *trackedTask) {
+		if task.ChannelName() == channel && task.seq <= barrierSeq {
+			pending = append(pending, task)
+		}
+	}
+	mgr.admitting.Range(func(_ int64, task *trackedTask) bool {
+		collect(task)
+		return true
+	})
+	mgr.tasks.Range(func(_ string, task *trackedTask) bool {
+		collect(task)
+		return true
+	})
+	mgr.submitMu.Unlock()
+
+	var errs []error
+	for _, task := range pending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-task.done:
+			if task.err != nil {
+				errs = append(errs, task.err)
+			}
+		}
+	}
+	return merr.Combine(errs...)
+}
+
+func (mgr syncManager) Close(ctx context.Context) error {
+	mgr.closed.Store(true)
+	// stop the background loops first, so that no task is submitted by the scheduled flush while draining
+	mgr.utilization.stop()
+	mgr.scheduler.stop()
+	mgr.statsReporter.stop()
+	// tasks submitted concurrently with Close may be tracked after the first round,
+	// tasks waiting for the in-flight cap are not tracked yet but unfinished either
+	unfinished := func() int { return mgr.tasks.Len() + mgr.admitting.Len() }
+	for unfinished() > 0 {
+		var tracked []*trackedTask
+		mgr.admitting.Range(func(_ int64, task *trackedTask) bool {
+			tracked = append(tracked, task)
+			return true
+		})
+		mgr.tasks.Range(func(_ string, task *trackedTask) bool {
+			tracked = append(tracked, task)
+			return true
+		})
+		for _, task := range tracked {
+			select {
+			case <-ctx.Done():
+				unfinished := unfinished()
+				log.Warn("sync manager closed with unfinished tasks", zap.Int("unfinished", unfinished))
+				return errors.Wrapf(ctx.Err(), "%d sync tasks unfinished", unfinished)
+			case <-task.done:
+			}
+		}
+	}
+	log.Info("sync manager closed")
+	return nil
+}
+
+func (mgr syncManager) AverageUtilization() float64 {
+	return mgr.utilization.average()
+}
+
+func (mgr syncManager) Export() []TaskInfo {
+	var infos []TaskInfo
+	mgr.tasks.Range(func(key string, task *trackedTask) bool {
+		if task.cancel(merr.WrapErrServiceUnavailable("sync task exported")) {
+			mgr.tasks.Remove(key)
+			infos = append(infos, task.info(key))
+		}
+		return true
+	})
+	// tasks waiting for the in-flight cap are pending as well, they finish with the cancellation once admitted
+	mgr.admitting.Range(func(seq int64, task *trackedTask) bool {
+		if task.cancel(merr.WrapErrServiceUnavailable("sync task exported")) {
+			infos = append(infos, task.info(mgr.taskKey(task.Task, seq)))
+		}
+		return true
+	})
+	// keep the submit order of the same segment
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].SubmitTs.Before(infos[j].SubmitTs)
+	})
+	return infos
+}
+
+func (mgr syncManager) Import(tasks []TaskInfo) error {
+	for _, info := range tasks {
+		if info.Task == nil {
+			return merr.WrapErrParameterInvalidMsg("task of %s not provided", info.Key)
+		}
+	}
+	var errs []error
+	for _, info := range tasks {
+		log.Info("import sync task",
+			zap.String("taskKey", info.Key),
+			zap.String("taskID", info.TaskID),
+			zap.Int64("segmentID", info.SegmentID),
+			zap.String("channel", info.Channel),
+			zap.String("origin", string(info.Origin)))
+		if _, err := mgr.submit(context.Background(), info.Task); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to import sync task %s", info.Key))
+		}
+	}
+	return merr.Combine(errs...)
+}
+
+func (mgr syncManager) ListTasks() []TaskInfo {
+	var infos []TaskInfo
+	mgr.tasks.Range(func(key string, task *trackedTask) bool {
+		infos = append(infos, task.info(key))
+		return true
+	})
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].SubmitTs.Before(infos[j].SubmitTs)
+	})
+	return infos
+}
+
+func (mgr syncManager) RunningSegments() []int64 {
+	segments := typeutil.NewUniqueSet()
+	mgr.tasks.Range(func(_ string, task *trackedTask) bool {
+		segments.Insert(task.SegmentID())
+		return true
+	})
+	ids := segments.Collect()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (mgr syncManager) RunningPartitionSegments() []SegmentKey {
+	segments := typeutil.NewSet[SegmentKey]()
+	mgr.tasks.Range(func(_ string, task *trackedTask) bool {
+		collectionID, partitionID := getTaskPartition(task.Task)
+		segments.Insert(SegmentKey{CollectionID: collectionID, PartitionID: partitionID, SegmentID: task.SegmentID()})
+		return true
+	})
+	keys := segments.Collect()
+	sortSegmentKeys(keys)
+	return keys
+}
+
+func (mgr syncManager) ReorderBufferDepth(segmentID int64) int {
+	return mgr.completions.buffered(segmentID)
+}
+
+func (mgr syncManager) InFlightBytes() int64 {
+	return mgr.inFlight.inFlight()
+}
+
+func (mgr syncManager) LastError(segmentID int64) error {
+	err, _ := mgr.lastErrors.Get(segmentID)
+	return err
+}
+
+func (mgr syncManager) RegisterFlushSource(channel string, source FlushSource) {
+	mgr.scheduler.sources.Insert(channel, source)
+}
+
+func (mgr syncManager) UnregisterFlushSource(channel string) {
+	mgr.scheduler.sources.Remove(channel)
+}
+
+// contextualTask is implemented by tasks which log with the context provided at submit,
+// so that the log lines of the task carry the task id and the task could observe the cancellation.
+type contextualTask interface {
+	setContext(ctx context.Context)
+}
+
+// dryRunTask is implemented by tasks which could be validated without side effects.
+type dryRunTask interface {
+	dryRun() error
+}
+
+func (mgr syncManager) Validate(task Task) error {
+	switch t := task.(type) {
+	case *SyncTask:
+		t.WithAllocator(mgr.allocator).WithChunkManager(mgr.chunkManager)
+	case *SyncTaskV2:
+		t.WithAllocator(mgr.allocator)
+	}
+
+	dryRun, ok := task.(dryRunTask)
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("task type %T does not support validation", task)
+	}
+	return dryRun.dryRun()
+}
@@ -2,14 +2,22 @@ package syncmgr
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/msgpb"
@@ -21,8 +29,14 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/datapb"
 	"github.com/milvus-io/milvus/internal/storage"
 	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/metrics"
+	"github.com/milvus-io/milvus/pkg/util/conc"
+	"github.com/milvus-io/milvus/pkg/util/merr"
 	"github.com/milvus-io/milvus/pkg/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/util/retry"
 	"github.com/milvus-io/milvus/pkg/util/tsoutil"
+	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
 type SyncManagerSuite struct {
@@ -253,6 +267,1556 @@ func (s *SyncManagerSuite) TestBlock() {
 	<-sig
 }
 
+func (s *SyncManagerSuite) TestCancelChannel() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	manager.Block(1)
+	manager.Block(2)
+
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	t2 := newMockSyncTask(2, "channel_2", 100)
+	f1 := s.asyncSyncData(manager, t1)
+	f2 := s.asyncSyncData(manager, t2)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 2
+	}, time.Second, time.Millisecond*10)
+
+	manager.CancelChannel("channel_1")
+	s.Equal(1, manager.(*syncManager).tasks.Len())
+
+	manager.Unblock(1)
+	manager.Unblock(2)
+
+	r, err := (<-f1).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrChannelNotAvailable)
+	s.EqualValues(0, t1.runCount.Load())
+
+	r, err = (<-f2).Await()
+	s.NoError(err)
+	s.NoError(r)
+	s.EqualValues(1, t2.runCount.Load())
+}
+
+func (s *SyncManagerSuite) TestCancelSegment() {
+	chunkManager := mocks.NewChunkManager(s.T())
+	chunkManager.EXPECT().RootPath().Return("files").Maybe()
+	bfs := metacache.NewBloomFilterSet()
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, bfs)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true).Maybe()
+
+	manager, err := NewSyncManager(10, chunkManager, s.allocator)
+	s.NoError(err)
+
+	s.Run("pending", func() {
+		manager.Block(s.segmentID)
+		task := s.getSuiteSyncTask()
+		task.WithInsertData(s.getInsertBuffer()).WithTimeRange(50, 100)
+		task.WithCheckpoint(&msgpb.MsgPosition{ChannelName: s.channelName, Timestamp: 100})
+		f := s.asyncSyncData(manager, task)
+		s.Eventually(func() bool {
+			return len(manager.ListTasks()) == 1
+		}, time.Second, time.Millisecond*10)
+
+		manager.CancelSegment(s.segmentID)
+		s.Empty(manager.ListTasks())
+		manager.Unblock(s.segmentID)
+
+		r, err := (<-f).Await()
+		s.NoError(err)
+		s.ErrorIs(r, context.Canceled)
+		chunkManager.AssertNotCalled(s.T(), "MultiWrite", mock.Anything, mock.Anything)
+	})
+
+	s.Run("running", func() {
+		started := make(chan struct{})
+		chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, _ map[string][]byte) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}).Once()
+
+		task := s.getSuiteSyncTask()
+		task.WithInsertData(s.getInsertBuffer()).WithTimeRange(50, 100)
+		task.WithCheckpoint(&msgpb.MsgPosition{ChannelName: s.channelName, Timestamp: 100})
+		task.WithFailureCallback(func(err error) {
+			s.Fail("cancellation shall not be reported as failure", err.Error())
+		})
+		f := manager.SyncData(context.Background(), task)
+		<-started
+
+		manager.CancelSegment(s.segmentID)
+		r, err := f.Await()
+		s.NoError(err)
+		s.ErrorIs(r, context.Canceled)
+	})
+
+	// tasks of other segments are not affected
+	manager.CancelSegment(s.segmentID + 1)
+}
+
+func (s *SyncManagerSuite) TestTaskStats() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	release := make(chan struct{})
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	t1.release = release
+	t2 := newMockSyncTask(1, "channel_1", 200)
+	t3 := newMockSyncTask(2, "channel_2", 50)
+	t3.release = release
+
+	f1 := manager.SyncData(context.Background(), t1)
+	s.Eventually(func() bool { return t1.runCount.Load() == 1 }, time.Second, time.Millisecond*10)
+	// t2 waits for t1 of the same segment
+	f2 := s.asyncSyncData(manager, t2)
+	f3 := manager.SyncData(context.Background(), t3)
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 3 && t3.runCount.Load() == 1
+	}, time.Second, time.Millisecond*10)
+
+	stats := manager.TaskStats()
+	s.Equal(1, stats.Pending)
+	s.Equal(2, stats.Running)
+	s.Equal(map[string]typeutil.Timestamp{"channel_1": 100, "channel_2": 50}, stats.OldestCheckpoints)
+
+	// task numbers are not checked since the reporters of other managers share the same metrics
+	nodeID := fmt.Sprint(paramtable.GetNodeID())
+	reported := manager.(*syncManager).reportStats(typeutil.NewSet[string]())
+	s.ElementsMatch([]string{"channel_1", "channel_2"}, reported.Collect())
+	s.EqualValues(tsoutil.PhysicalTime(50).UnixMilli(),
+		testutil.ToFloat64(metrics.DataNodeSyncOldestCheckpoint.WithLabelValues(nodeID, "channel_2")))
+
+	close(release)
+	for _, f := range []*conc.Future[error]{f1, <-f2, f3} {
+		r, err := f.Await()
+		s.NoError(err)
+		s.NoError(r)
+	}
+	stats = manager.TaskStats()
+	s.Equal(0, stats.Pending)
+	s.Equal(0, stats.Running)
+	s.Empty(stats.OldestCheckpoints)
+
+	s.Empty(manager.(*syncManager).reportStats(reported))
+}
+
+func (s *SyncManagerSuite) TestWriteRetry() {
+	bfs := metacache.NewBloomFilterSet()
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, bfs)
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().GetSegmentsBy(mock.Anything).Return([]*metacache.SegmentInfo{seg}).Maybe()
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return().Maybe()
+
+	newTask := func() *SyncTask {
+		task := s.getSuiteSyncTask()
+		task.WithInsertData(s.getInsertBuffer()).WithTimeRange(50, 100)
+		task.WithCheckpoint(&msgpb.MsgPosition{ChannelName: s.channelName, Timestamp: 100})
+		return task
+	}
+	storageErr := merr.WrapErrServiceUnavailable("mock 503")
+
+	s.Run("succeed after retry", func() {
+		chunkManager := mocks.NewChunkManager(s.T())
+		chunkManager.EXPECT().RootPath().Return("files").Maybe()
+		var keys [][]string
+		chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Run(func(_ context.Context, contents map[string][]byte) {
+			keys = append(keys, lo.Keys(contents))
+		}).Return(storageErr).Times(2)
+		chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Run(func(_ context.Context, contents map[string][]byte) {
+			keys = append(keys, lo.Keys(contents))
+		}).Return(nil).Once()
+
+		manager, err := NewSyncManager(10, chunkManager, s.allocator, WithWriteRetry(2, time.Millisecond))
+		s.NoError(err)
+		r, err := manager.SyncData(context.Background(), newTask()).Await()
+		s.NoError(err)
+		s.NoError(r)
+
+		// retries upload the same logs
+		s.Len(keys, 3)
+		s.ElementsMatch(keys[0], keys[1])
+		s.ElementsMatch(keys[0], keys[2])
+	})
+
+	s.Run("retry exhausted", func() {
+		chunkManager := mocks.NewChunkManager(s.T())
+		chunkManager.EXPECT().RootPath().Return("files").Maybe()
+		chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Return(storageErr).Times(2)
+
+		manager, err := NewSyncManager(10, chunkManager, s.allocator, WithWriteRetry(1, time.Millisecond))
+		s.NoError(err)
+		r, err := manager.SyncData(context.Background(), newTask()).Await()
+		s.NoError(err)
+		s.ErrorIs(r, merr.ErrServiceUnavailable)
+		s.ErrorContains(r, "mock 503")
+	})
+
+	s.Run("task options take precedence", func() {
+		chunkManager := mocks.NewChunkManager(s.T())
+		chunkManager.EXPECT().RootPath().Return("files").Maybe()
+		chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Return(storageErr).Once()
+
+		manager, err := NewSyncManager(10, chunkManager, s.allocator, WithWriteRetry(5, time.Millisecond))
+		s.NoError(err)
+		task := newTask()
+		task.WithWriteRetryOptions(retry.Attempts(1))
+		r, err := manager.SyncData(context.Background(), task).Await()
+		s.NoError(err)
+		s.ErrorIs(r, merr.ErrServiceUnavailable)
+	})
+}
+
+func (s *SyncManagerSuite) TestSyncDataWithStats() {
+	bfs := metacache.NewBloomFilterSet()
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, bfs)
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return().Maybe()
+
+	chunkManager := mocks.NewChunkManager(s.T())
+	chunkManager.EXPECT().RootPath().Return("files").Maybe()
+	// the failed attempt is not counted
+	chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Return(merr.WrapErrServiceUnavailable("mock 503")).Once()
+	var bytesWritten int64
+	var filesWritten int
+	chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Run(func(_ context.Context, contents map[string][]byte) {
+		for _, value := range contents {
+			bytesWritten += int64(len(value))
+			filesWritten++
+		}
+	}).Return(nil).Once()
+
+	manager, err := NewSyncManager(10, chunkManager, s.allocator, WithWriteRetry(1, time.Millisecond))
+	s.NoError(err)
+	task := s.getSuiteSyncTask()
+	task.WithInsertData(s.getInsertBuffer()).WithTimeRange(50, 100)
+	task.WithCheckpoint(&msgpb.MsgPosition{ChannelName: s.channelName, Timestamp: 100})
+
+	result, err := manager.SyncDataWithStats(context.Background(), task).Await()
+	s.NoError(err)
+	s.NoError(result.Err)
+	s.Greater(bytesWritten, int64(0))
+	s.Equal(bytesWritten, result.BytesWritten)
+	s.Equal(filesWritten, result.FilesWritten)
+
+	// tasks without written stats report zero
+	result, err = manager.SyncDataWithStats(context.Background(), newMockSyncTask(2, s.channelName, 100)).Await()
+	s.NoError(err)
+	s.NoError(result.Err)
+	s.Zero(result.BytesWritten)
+	s.Zero(result.FilesWritten)
+}
+
+func (s *SyncManagerSuite) TestClose() {
+	bfs := metacache.NewBloomFilterSet()
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, bfs)
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(mock.Anything).Return(seg, true)
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return().Maybe()
+
+	// slow chunk manager
+	release := make(chan struct{})
+	chunkManager := mocks.NewChunkManager(s.T())
+	chunkManager.EXPECT().RootPath().Return("files").Maybe()
+	chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, _ map[string][]byte) error {
+		<-release
+		return nil
+	}).Times(3)
+
+	manager, err := NewSyncManager(10, chunkManager, s.allocator)
+	s.NoError(err)
+	futures := make([]*conc.Future[error], 0, 3)
+	for i := 0; i < 3; i++ {
+		task := s.getSuiteSyncTask().WithSegmentID(int64(i + 1))
+		task.WithInsertData(s.getInsertBuffer()).WithTimeRange(50, 100)
+		task.WithCheckpoint(&msgpb.MsgPosition{ChannelName: s.channelName, Timestamp: 100})
+		futures = append(futures, manager.SyncData(context.Background(), task))
+	}
+
+	closed := atomic.NewBool(false)
+	closeErr := make(chan error, 1)
+	go func() {
+		closeErr <- manager.Close(context.Background())
+		closed.Store(true)
+	}()
+	s.Never(closed.Load, time.Millisecond*200, time.Millisecond*10)
+
+	// rejected after close
+	r, err := manager.SyncData(context.Background(), newMockSyncTask(4, s.channelName, 100)).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrServiceUnavailable)
+
+	close(release)
+	s.NoError(<-closeErr)
+	for _, f := range futures {
+		s.True(f.OK())
+		s.NoError(f.Value())
+	}
+	s.Empty(manager.ListTasks())
+}
+
+func (s *SyncManagerSuite) TestCloseTimeout() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+	task := newMockSyncTask(1, s.channelName, 100)
+	task.release = make(chan struct{})
+	f := manager.SyncData(context.Background(), task)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	err = manager.Close(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.ErrorContains(err, "1 sync tasks unfinished")
+
+	close(task.release)
+	r, err := f.Await()
+	s.NoError(err)
+	s.NoError(r)
+	s.NoError(manager.Close(context.Background()))
+
+	s.Run("waiting for in-flight cap", func() {
+		manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithInFlightBytesCap(100))
+		s.Require().NoError(err)
+		running := newMockSyncTask(1, s.channelName, 100)
+		running.payload = 60
+		running.release = make(chan struct{})
+		f1 := s.asyncSyncData(manager, running)
+		s.Eventually(func() bool {
+			return running.runCount.Load() == 1
+		}, time.Second, time.Millisecond*10)
+		admitting := newMockSyncTask(2, s.channelName, 200)
+		admitting.payload = 60
+		f2 := s.asyncSyncData(manager, admitting)
+		s.Eventually(func() bool {
+			return manager.(*syncManager).admitting.Len() == 1
+		}, time.Second, time.Millisecond*10)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		s.ErrorContains(manager.Close(ctx), "2 sync tasks unfinished")
+
+		close(running.release)
+		for _, f := range []<-chan *conc.Future[error]{f1, f2} {
+			r, err := (<-f).Await()
+			s.NoError(err)
+			s.NoError(r)
+		}
+		s.NoError(manager.Close(context.Background()))
+	})
+}
+
+func (s *SyncManagerSuite) TestCloseStopsBackgroundLoops() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithScheduledFlush(10*time.Millisecond, time.Millisecond))
+	s.NoError(err)
+	s.NoError(manager.Close(context.Background()))
+
+	mgr := manager.(*syncManager)
+	for _, done := range []chan struct{}{mgr.utilization.done, mgr.scheduler.done, mgr.statsReporter.done} {
+		select {
+		case <-done:
+		default:
+			s.FailNow("background loop not stopped after close")
+		}
+	}
+
+	// stale buffers are not flushed after close
+	source := &mockFlushSource{
+		manager:  manager,
+		channel:  "channel_1",
+		buffered: map[int64]time.Time{1: time.Now().Add(-time.Second)},
+	}
+	manager.RegisterFlushSource(source.channel, source)
+	defer manager.UnregisterFlushSource(source.channel)
+	time.Sleep(100 * time.Millisecond)
+	s.Empty(source.flushed())
+}
+
+func (s *SyncManagerSuite) TestChannelParallelism() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithChannelParallelism(1))
+	s.NoError(err)
+
+	channels := []string{"channel_1", "channel_2"}
+	tasks := make(map[string][]*mockSyncTask)
+	var futures []<-chan *conc.Future[error]
+	for i := 0; i < 3; i++ {
+		for j, channel := range channels {
+			task := newMockSyncTask(int64(i*len(channels)+j+1), channel, uint64(100+i))
+			task.release = make(chan struct{})
+			tasks[channel] = append(tasks[channel], task)
+			futures = append(futures, s.asyncSyncData(manager, task))
+		}
+	}
+
+	running := func(channel string) []*mockSyncTask {
+		return lo.Filter(tasks[channel], func(task *mockSyncTask, _ int) bool {
+			select {
+			case <-task.release:
+				return false
+			default:
+				return task.runCount.Load() == 1
+			}
+		})
+	}
+	// each round, both channels run exactly one task although there are free workers
+	for i := 0; i < 3; i++ {
+		s.Eventually(func() bool {
+			return len(running("channel_1")) == 1 && len(running("channel_2")) == 1
+		}, time.Second, time.Millisecond*10)
+		s.Never(func() bool {
+			return len(running("channel_1")) > 1 || len(running("channel_2")) > 1
+		}, time.Millisecond*50, time.Millisecond*10)
+		for _, channel := range channels {
+			close(running(channel)[0].release)
+		}
+	}
+	for _, f := range futures {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	s.Zero(manager.(*syncManager).queue.len())
+}
+
+func (s *SyncManagerSuite) TestPriorityDispatch() {
+	manager, err := NewSyncManager(1, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	blocking := newMockSyncTask(1, "channel_1", 100)
+	blocking.release = make(chan struct{})
+	f1 := s.asyncSyncData(manager, blocking)
+	s.Eventually(func() bool { return blocking.runCount.Load() == 1 }, time.Second, time.Millisecond*10)
+
+	// the low priority task is queued before the high one is submitted
+	low := newMockSyncTask(2, "channel_1", 200)
+	f2 := s.asyncSyncData(manager, low)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).queue.len() == 1
+	}, time.Second, time.Millisecond*10)
+	high := newMockSyncTask(3, "channel_1", 300)
+	high.priority = 10
+	high.release = make(chan struct{})
+	f3 := s.asyncSyncData(manager, high)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).queue.len() == 2
+	}, time.Second, time.Millisecond*10)
+
+	// the high priority task overtakes the low one submitted earlier
+	close(blocking.release)
+	s.Eventually(func() bool { return high.runCount.Load() == 1 }, time.Second, time.Millisecond*10)
+	s.EqualValues(0, low.runCount.Load())
+
+	close(high.release)
+	for _, f := range []<-chan *conc.Future[error]{f1, f2, f3} {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	s.EqualValues(1, low.runCount.Load())
+}
+
+func (s *SyncManagerSuite) TestFlushBarrier() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	manager.Block(1)
+	manager.Block(2)
+
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	t1.err = errors.New("mock error")
+	f1 := s.asyncSyncData(manager, t1)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 1
+	}, time.Second, time.Millisecond*10)
+	// the barrier does not rely on the wall clock, which may step backwards
+	manager.(*syncManager).tasks.Range(func(_ string, task *trackedTask) bool {
+		task.submitTs = task.submitTs.Add(time.Hour)
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	s.ErrorIs(manager.FlushBarrier(ctx, "channel_1"), context.DeadlineExceeded)
+
+	barrier := make(chan error, 1)
+	go func() {
+		barrier <- manager.FlushBarrier(context.Background(), "channel_1")
+	}()
+	// make sure t2 is submitted after the barrier
+	time.Sleep(time.Millisecond * 100)
+	t2 := newMockSyncTask(2, "channel_1", 200)
+	f2 := s.asyncSyncData(manager, t2)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 2
+	}, time.Second, time.Millisecond*10)
+
+	select {
+	case <-barrier:
+		s.FailNow("barrier returned before previous task finished")
+	default:
+	}
+
+	manager.Unblock(1)
+	s.ErrorIs(<-barrier, t1.err)
+	_, err = (<-f1).Await()
+	s.NoError(err)
+	s.EqualValues(0, t2.runCount.Load())
+
+	// no task to wait for other channels
+	s.NoError(manager.FlushBarrier(context.Background(), "channel_2"))
+
+	manager.Unblock(2)
+	r, err := (<-f2).Await()
+	s.NoError(err)
+	s.NoError(r)
+}
+
+func (s *SyncManagerSuite) TestFlushBarrierInFlightCap() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithInFlightBytesCap(100))
+	s.NoError(err)
+
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	t1.payload = 60
+	t1.release = make(chan struct{})
+	f1 := s.asyncSyncData(manager, t1)
+	s.Eventually(func() bool {
+		return t1.runCount.Load() == 1
+	}, time.Second, time.Millisecond*10)
+
+	// t2 waits for the in-flight cap, but is submitted before the barrier
+	t2 := newMockSyncTask(2, "channel_1", 200)
+	t2.payload = 60
+	t2.release = make(chan struct{})
+	f2 := s.asyncSyncData(manager, t2)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).seq.Load() == 2
+	}, time.Second, time.Millisecond*10)
+	s.EqualValues(1, manager.(*syncManager).tasks.Len())
+
+	barrier := make(chan error, 1)
+	go func() {
+		barrier <- manager.FlushBarrier(context.Background(), "channel_1")
+	}()
+
+	close(t1.release)
+	_, err = (<-f1).Await()
+	s.NoError(err)
+	s.Eventually(func() bool {
+		return t2.runCount.Load() == 1
+	}, time.Second, time.Millisecond*10)
+	select {
+	case <-barrier:
+		s.FailNow("barrier returned before the task waiting for in-flight cap finished")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(t2.release)
+	s.NoError(<-barrier)
+	_, err = (<-f2).Await()
+	s.NoError(err)
+}
+
+func (s *SyncManagerSuite) TestFlushBarrierConcurrentSubmit() {
+	var mu sync.Mutex
+	seqTasks := make(map[int64]*mockSyncTask)
+	manager, err := NewSyncManager(4, s.chunkManager, s.allocator, WithTaskKeyFunc(func(task Task, seq int64) string {
+		mu.Lock()
+		defer mu.Unlock()
+		seqTasks[seq] = task.(*mockSyncTask)
+		return DefaultTaskKey(task, seq)
+	}))
+	s.NoError(err)
+
+	const taskNum = 200
+	wg := sync.WaitGroup{}
+	for i := 0; i < taskNum; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			manager.SyncData(context.Background(), newMockSyncTask(int64(i%8), "channel_1", uint64(i+1)))
+		}(i)
+	}
+
+	// every task with a submit sequence up to the observed one finishes before the barrier returns
+	for i := 0; i < 20; i++ {
+		barrierSeq := manager.(*syncManager).seq.Load()
+		s.Require().NoError(manager.FlushBarrier(context.Background(), "channel_1"))
+		mu.Lock()
+		for seq := int64(1); seq <= barrierSeq; seq++ {
+			task, ok := seqTasks[seq]
+			s.Require().True(ok, "task of seq %d not tracked before the barrier returned", seq)
+			s.Require().EqualValues(1, task.runCount.Load(), "task of seq %d not finished before the barrier returned", seq)
+		}
+		mu.Unlock()
+	}
+	wg.Wait()
+	s.NoError(manager.FlushBarrier(context.Background(), "channel_1"))
+}
+
+func (s *SyncManagerSuite) TestAverageUtilization() {
+	manager, err := NewSyncManager(2, s.chunkManager, s.allocator)
+	s.NoError(err)
+	mgr := manager.(*syncManager)
+
+	t1 := newMockTask(nil)
+	t2 := newMockTask(nil)
+	mgr.Submit(1, t1)
+	mgr.Submit(2, t2)
+
+	s.Eventually(func() bool {
+		return manager.AverageUtilization() > 0.9
+	}, time.Second*5, utilizationSampleInterval)
+	s.LessOrEqual(manager.AverageUtilization(), 1.0)
+
+	t1.done()
+	t2.done()
+}
+
+func (s *SyncManagerSuite) TestTaskKey() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	manager.Block(1)
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	t2 := newMockSyncTask(1, "channel_1", 100)
+	f1 := s.asyncSyncData(manager, t1)
+	f2 := s.asyncSyncData(manager, t2)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 2
+	}, time.Second, time.Millisecond*10)
+
+	manager.Unblock(1)
+	for _, f := range []<-chan *conc.Future[error]{f1, f2} {
+		r, err := (<-f).Await()
+		s.NoError(err)
+		s.NoError(r)
+	}
+	s.EqualValues(1, t1.runCount.Load())
+	s.EqualValues(1, t2.runCount.Load())
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 0
+	}, time.Second, time.Millisecond*10)
+
+	// custom key strategy
+	manager, err = NewSyncManager(10, s.chunkManager, s.allocator, WithTaskKeyFunc(func(task Task, seq int64) string {
+		return task.ChannelName()
+	}))
+	s.NoError(err)
+	manager.Block(1)
+	tracked := newMockSyncTask(1, "channel_1", 100)
+	f1 = s.asyncSyncData(manager, tracked)
+	s.Eventually(func() bool {
+		_, ok := manager.(*syncManager).tasks.Get("channel_1")
+		return ok
+	}, time.Second, time.Millisecond*10)
+
+	// conflicting key is rejected and the tracked task is kept
+	conflicted := newMockSyncTask(2, "channel_1", 50)
+	r, err := manager.SyncData(context.Background(), conflicted).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrParameterInvalid)
+	s.Equal([]int64{1}, manager.RunningSegments())
+	segmentID, _ := manager.GetEarliestPosition("channel_1")
+	s.EqualValues(1, segmentID)
+	s.EqualValues(1, manager.(*syncManager).pending.Load())
+
+	manager.Unblock(1)
+	_, err = (<-f1).Await()
+	s.NoError(err)
+	s.EqualValues(1, tracked.runCount.Load())
+	s.EqualValues(0, conflicted.runCount.Load())
+}
+
+func (s *SyncManagerSuite) TestDedupResubmitted() {
+	s.broker.EXPECT().SaveBinlogPaths(mock.Anything, mock.Anything).Return(nil)
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, metacache.NewBloomFilterSet())
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().GetSegmentsBy(mock.Anything).Return([]*metacache.SegmentInfo{seg})
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return()
+
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithTaskDedup(true))
+	s.NoError(err)
+	newTask := func() *SyncTask {
+		task := s.getSuiteSyncTask()
+		task.WithMetaWriter(BrokerMetaWriter(s.broker))
+		task.WithTimeRange(50, 100)
+		task.WithCheckpoint(&msgpb.MsgPosition{
+			ChannelName: s.channelName,
+			MsgID:       []byte{1, 2, 3, 4},
+			Timestamp:   100,
+		})
+		return task
+	}
+
+	// the first task is in flight when the identical one is re-submitted
+	manager.Block(s.segmentID)
+	f1 := s.asyncSyncData(manager, newTask())
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 1
+	}, time.Second, time.Millisecond*10)
+	f2 := manager.SyncData(context.Background(), newTask())
+	s.Equal(1, manager.(*syncManager).tasks.Len())
+	manager.Unblock(s.segmentID)
+
+	r1, err := (<-f1).Await()
+	s.NoError(err)
+	r2, err := f2.Await()
+	s.NoError(err)
+	s.NoError(r1)
+	s.NoError(r2)
+	s.chunkManager.AssertNumberOfCalls(s.T(), "MultiWrite", 1)
+
+	// both callers get the same failure
+	manager.Block(1)
+	failed := newMockSyncTask(1, "channel_1", 100)
+	failed.err = errors.New("mocked")
+	resubmitted := newMockSyncTask(1, "channel_1", 100)
+	f1 = s.asyncSyncData(manager, failed)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).tasks.Len() == 1
+	}, time.Second, time.Millisecond*10)
+	f2 = manager.SyncData(context.Background(), resubmitted)
+	manager.Unblock(1)
+
+	r1, err = (<-f1).Await()
+	s.NoError(err)
+	r2, err = f2.Await()
+	s.NoError(err)
+	s.Error(r1)
+	s.Equal(r1, r2)
+	s.EqualValues(1, failed.runCount.Load())
+	s.EqualValues(0, resubmitted.runCount.Load())
+
+	// finished tasks are not deduplicated
+	r, err := manager.SyncData(context.Background(), resubmitted).Await()
+	s.NoError(err)
+	s.NoError(r)
+	s.EqualValues(1, resubmitted.runCount.Load())
+}
+
+func (s *SyncManagerSuite) TestExportImport() {
+	source, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+	target, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	source.Block(1)
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	f1 := s.asyncSyncData(source, t1)
+	s.Eventually(func() bool {
+		return source.(*syncManager).tasks.Len() == 1
+	}, time.Second, time.Millisecond*10)
+
+	infos := source.Export()
+	s.Require().Len(infos, 1)
+	s.EqualValues(1, infos[0].SegmentID)
+	s.Equal("channel_1", infos[0].Channel)
+	s.Equal(0, source.(*syncManager).tasks.Len())
+
+	s.NoError(target.Import(infos))
+	s.Eventually(func() bool {
+		return t1.runCount.Load() == 1
+	}, time.Second, time.Millisecond*10)
+
+	// exported task does not run in source manager
+	source.Unblock(1)
+	r, err := (<-f1).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrServiceUnavailable)
+	s.EqualValues(1, t1.runCount.Load())
+
+	s.ErrorIs(target.Import([]TaskInfo{{Key: "empty"}}), merr.ErrParameterInvalid)
+
+	// tasks rejected on submit are reported
+	s.NoError(target.Close(context.Background()))
+	s.ErrorIs(target.Import([]TaskInfo{{Key: "closed", Task: newMockSyncTask(3, "channel_1", 100)}}), merr.ErrServiceUnavailable)
+}
+
+func (s *SyncManagerSuite) TestExportRunningAndAdmitting() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithInFlightBytesCap(100))
+	s.NoError(err)
+
+	running := newMockSyncTask(1, "channel_1", 100)
+	running.payload = 60
+	running.release = make(chan struct{})
+	f1 := s.asyncSyncData(manager, running)
+	s.Eventually(func() bool {
+		return running.runCount.Load() == 1
+	}, time.Second, time.Millisecond*10)
+
+	admitting := newMockSyncTask(2, "channel_1", 200)
+	admitting.payload = 60
+	f2 := s.asyncSyncData(manager, admitting)
+	s.Eventually(func() bool {
+		return manager.(*syncManager).admitting.Len() == 1
+	}, time.Second, time.Millisecond*10)
+
+	// the running task is left in the manager, the one waiting for in-flight cap is exported
+	infos := manager.Export()
+	s.Require().Len(infos, 1)
+	s.EqualValues(2, infos[0].SegmentID)
+	s.Equal(1, manager.(*syncManager).tasks.Len())
+
+	close(running.release)
+	r, err := (<-f1).Await()
+	s.NoError(err)
+	s.NoError(r)
+	r, err = (<-f2).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrServiceUnavailable)
+	s.EqualValues(0, admitting.runCount.Load())
+	s.EqualValues(0, manager.(*syncManager).pending.Load())
+	s.NoError(manager.FlushBarrier(context.Background(), "channel_1"))
+}
+
+func (s *SyncManagerSuite) TestListTasks() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+	s.Empty(manager.ListTasks())
+
+	manager.Block(1)
+	t1 := newMockSyncTask(1, "channel_1", 100)
+	t1.origin = OriginCompaction
+	f1 := s.asyncSyncData(manager, t1)
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 1
+	}, time.Second, time.Millisecond*10)
+	t2 := newMockSyncTask(1, "channel_1", 200)
+	f2 := s.asyncSyncData(manager, t2)
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	infos := manager.ListTasks()
+	s.Equal(OriginCompaction, infos[0].Origin)
+	s.Same(t1, infos[0].Task)
+	s.Equal(OriginFlush, infos[1].Origin)
+	s.Same(t2, infos[1].Task)
+
+	manager.Unblock(1)
+	for _, f := range []<-chan *conc.Future[error]{f1, f2} {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 0
+	}, time.Second, time.Millisecond*10)
+
+	s.Equal(OriginFlush, NewSyncTask().Origin())
+	s.Equal(OriginImport, NewSyncTask().WithOrigin(OriginImport).Origin())
+	s.Equal(OriginImport, NewSyncTaskV2().WithOrigin(OriginImport).Origin())
+}
+
+func (s *SyncManagerSuite) TestRunningSegments() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+	s.Empty(manager.RunningSegments())
+
+	release := make(chan struct{})
+	var futures []<-chan *conc.Future[error]
+	for _, task := range []*mockSyncTask{
+		newMockSyncTask(2, "channel_1", 100),
+		newMockSyncTask(1, "channel_1", 200),
+		newMockSyncTask(2, "channel_1", 300),
+	} {
+		task.release = release
+		futures = append(futures, s.asyncSyncData(manager, task))
+	}
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 3
+	}, time.Second, time.Millisecond*10)
+	s.Equal([]int64{1, 2}, manager.RunningSegments())
+
+	close(release)
+	for _, f := range futures {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	s.Eventually(func() bool {
+		return len(manager.RunningSegments()) == 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func (s *SyncManagerSuite) TestPartitionTracking() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithTaskDedup(true))
+	s.NoError(err)
+
+	release := make(chan struct{})
+	// same segment id and checkpoint under different partitions
+	tasks := []*mockPartitionSyncTask{
+		newMockPartitionSyncTask(10, 1, "channel_1", 200),
+		newMockPartitionSyncTask(20, 1, "channel_1", 200),
+		newMockPartitionSyncTask(20, 2, "channel_1", 100),
+	}
+	var futures []<-chan *conc.Future[error]
+	for _, task := range tasks {
+		task.release = release
+		futures = append(futures, s.asyncSyncData(manager, task))
+	}
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 3
+	}, time.Second, time.Millisecond*10)
+
+	keys := lo.Map(manager.ListTasks(), func(info TaskInfo, _ int) string { return info.Key })
+	s.True(lo.SomeBy(keys, func(key string) bool { return strings.HasPrefix(key, "1-10-1-200-") }))
+	s.True(lo.SomeBy(keys, func(key string) bool { return strings.HasPrefix(key, "1-20-1-200-") }))
+	s.ElementsMatch([]int64{10, 20, 20}, lo.Map(manager.ListTasks(), func(info TaskInfo, _ int) int64 { return info.PartitionID }))
+
+	s.Equal([]int64{1, 2}, manager.RunningSegments())
+	s.Equal([]SegmentKey{
+		{CollectionID: 1, PartitionID: 10, SegmentID: 1},
+		{CollectionID: 1, PartitionID: 20, SegmentID: 1},
+		{CollectionID: 1, PartitionID: 20, SegmentID: 2},
+	}, manager.RunningPartitionSegments())
+
+	segmentID, pos := manager.GetEarliestPartitionPosition("channel_1", 10)
+	s.EqualValues(1, segmentID)
+	s.EqualValues(200, pos.GetTimestamp())
+	segmentID, pos = manager.GetEarliestPartitionPosition("channel_1", 20)
+	s.EqualValues(2, segmentID)
+	s.EqualValues(100, pos.GetTimestamp())
+	_, pos = manager.GetEarliestPartitionPosition("channel_1", 30)
+	s.Nil(pos)
+	segmentID, pos = manager.GetEarliestPosition("channel_1")
+	s.EqualValues(2, segmentID)
+	s.EqualValues(100, pos.GetTimestamp())
+
+	close(release)
+	for _, f := range futures {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	// tasks of the same segment and checkpoint under different partitions are not deduplicated
+	for _, task := range tasks {
+		s.EqualValues(1, task.runCount.Load())
+	}
+	s.Eventually(func() bool {
+		return len(manager.RunningPartitionSegments()) == 0
+	}, time.Second, time.Millisecond*10)
+}
+
+func (s *SyncManagerSuite) TestCompletionOrder() {
+	s.Run("sequencer", func() {
+		sequencer := newCompletionSequencer()
+		var emitted []uint64
+		emit := func(ts uint64) func(error) {
+			return func(error) { emitted = append(emitted, ts) }
+		}
+		c2 := sequencer.register(1, 200, emit(200))
+		c1 := sequencer.register(1, 100, emit(100))
+		c3 := sequencer.register(2, 300, emit(300))
+
+		sequencer.complete(1, c2, nil)
+		s.Empty(emitted)
+		sequencer.complete(2, c3, nil)
+		s.Equal([]uint64{300}, emitted)
+		sequencer.complete(1, c1, nil)
+		s.Equal([]uint64{300, 100, 200}, emitted)
+		s.Zero(sequencer.pending.Len())
+	})
+
+	s.Run("emit_registers", func() {
+		sequencer := newCompletionSequencer()
+		var emitted []uint64
+		var c3 *completion
+		c1 := sequencer.register(1, 100, func(error) {
+			emitted = append(emitted, 100)
+			// emit could register and complete a following task of the same segment
+			c3 = sequencer.register(1, 300, func(error) { emitted = append(emitted, 300) })
+			sequencer.complete(1, c3, nil)
+		})
+		c2 := sequencer.register(1, 200, func(error) { emitted = append(emitted, 200) })
+
+		sequencer.complete(1, c2, nil)
+		sequencer.complete(1, c1, nil)
+		s.Equal([]uint64{100, 200, 300}, emitted)
+		s.Zero(sequencer.buffered(1))
+		s.Zero(sequencer.pending.Len())
+	})
+
+	s.Run("reordered_submit", func() {
+		var mu sync.Mutex
+		var completed []uint64
+		manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithCompletionCallback(func(task Task, _ error) {
+			mu.Lock()
+			defer mu.Unlock()
+			completed = append(completed, task.Checkpoint().GetTimestamp())
+		}))
+		s.NoError(err)
+
+		// later checkpoint is submitted first
+		manager.Block(1)
+		t2 := newMockSyncTask(1, "channel_1", 200)
+		f2 := s.asyncSyncData(manager, t2)
+		s.Eventually(func() bool {
+			return len(manager.ListTasks()) == 1
+		}, time.Second, time.Millisecond*10)
+		t1 := newMockSyncTask(1, "channel_1", 100)
+		f1 := s.asyncSyncData(manager, t1)
+		s.Eventually(func() bool {
+			return len(manager.ListTasks()) == 2
+		}, time.Second, time.Millisecond*10)
+
+		manager.Unblock(1)
+		for _, f := range []<-chan *conc.Future[error]{f1, f2} {
+			_, err := (<-f).Await()
+			s.NoError(err)
+		}
+		s.Eventually(func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(completed) == 2
+		}, time.Second, time.Millisecond*10)
+		s.Equal([]uint64{100, 200}, completed)
+	})
+
+	s.Run("callback_submits", func() {
+		var mu sync.Mutex
+		var completed []uint64
+		var manager SyncManager
+		manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithCompletionCallback(func(task Task, _ error) {
+			mu.Lock()
+			completed = append(completed, task.Checkpoint().GetTimestamp())
+			mu.Unlock()
+			// a callback submitting the next task of the same segment shall not deadlock
+			if task.Checkpoint().GetTimestamp() == 100 {
+				manager.SyncData(context.Background(), newMockSyncTask(1, "channel_1", 200))
+			}
+		}))
+		s.NoError(err)
+
+		_, err = manager.SyncData(context.Background(), newMockSyncTask(1, "channel_1", 100)).Await()
+		s.NoError(err)
+		s.Eventually(func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(completed) == 2
+		}, time.Second, time.Millisecond*10)
+		s.Equal([]uint64{100, 200}, completed)
+	})
+}
+
+func (s *SyncManagerSuite) TestValidate() {
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, metacache.NewBloomFilterSet())
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID+1).Return(nil, false)
+	s.chunkManager.ExpectedCalls = nil
+	s.chunkManager.EXPECT().RootPath().Return("files").Maybe()
+
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	s.Run("well_formed", func() {
+		task := s.getSuiteSyncTask().
+			WithInsertData(s.getInsertBuffer()).
+			WithDeleteData(s.getDeleteBuffer()).
+			WithTimeRange(50, 100)
+		s.NoError(manager.Validate(task))
+		// nothing uploaded or recorded
+		s.chunkManager.AssertNotCalled(s.T(), "MultiWrite", mock.Anything, mock.Anything)
+		s.Empty(task.segmentData)
+		s.Empty(task.insertBinlogs)
+	})
+
+	s.Run("malformed_insert", func() {
+		task := s.getSuiteSyncTask().WithInsertData(s.getEmptyInsertBuffer())
+		s.Error(manager.Validate(task))
+	})
+
+	s.Run("malformed_delete", func() {
+		task := s.getSuiteSyncTask().WithDeleteData(s.getDeleteBufferZeroTs())
+		s.Error(manager.Validate(task))
+	})
+
+	s.Run("segment_not_found", func() {
+		task := s.getSuiteSyncTask().WithSegmentID(s.segmentID + 1).WithInsertData(s.getInsertBuffer())
+		s.ErrorIs(manager.Validate(task), merr.ErrSegmentNotFound)
+	})
+
+	s.Run("unsupported_task", func() {
+		s.ErrorIs(manager.Validate(newMockSyncTask(1, "channel_1", 100)), merr.ErrParameterInvalid)
+	})
+}
+
+func (s *SyncManagerSuite) TestLastError() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+	s.NoError(manager.LastError(1))
+
+	failed := newMockSyncTask(1, "channel_1", 100)
+	failed.err = merr.WrapErrServiceUnavailable("mocked")
+	r, err := manager.SyncData(context.Background(), failed).Await()
+	s.NoError(err)
+	s.Error(r)
+	s.ErrorIs(manager.LastError(1), merr.ErrServiceUnavailable)
+	s.NoError(manager.LastError(2))
+
+	r, err = manager.SyncData(context.Background(), newMockSyncTask(1, "channel_1", 200)).Await()
+	s.NoError(err)
+	s.NoError(r)
+	s.NoError(manager.LastError(1))
+}
+
+func (s *SyncManagerSuite) TestInFlightBytesCap() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithInFlightBytesCap(100))
+	s.NoError(err)
+	s.EqualValues(0, manager.InFlightBytes())
+
+	tasks := make([]*mockSyncTask, 0, 3)
+	futures := make([]<-chan *conc.Future[error], 0, 3)
+	for i := 0; i < 3; i++ {
+		task := newMockSyncTask(int64(i+1), "channel_1", 100)
+		task.payload = 60
+		task.release = make(chan struct{})
+		tasks = append(tasks, task)
+		futures = append(futures, s.asyncSyncData(manager, task))
+	}
+
+	// tasks are admitted one by one since two of them exceed the cap
+	for i := 0; i < 3; i++ {
+		runningTasks := func() []*mockSyncTask {
+			return lo.Filter(tasks, func(task *mockSyncTask, _ int) bool { return task.runCount.Load() == 1 })
+		}
+		s.Eventually(func() bool {
+			return manager.InFlightBytes() == 60 && len(manager.ListTasks()) == 1 && len(runningTasks()) == i+1
+		}, time.Second, time.Millisecond*10)
+		s.Never(func() bool {
+			return manager.InFlightBytes() > 100
+		}, time.Millisecond*100, time.Millisecond*10)
+
+		running := runningTasks()
+		s.Require().Len(running, i+1)
+		// release the task admitted latest
+		released := false
+		for _, task := range running {
+			select {
+			case <-task.release:
+			default:
+				if !released {
+					close(task.release)
+					released = true
+				}
+			}
+		}
+		s.True(released)
+	}
+	for _, f := range futures {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	s.EqualValues(0, manager.InFlightBytes())
+}
+
+func (s *SyncManagerSuite) TestTaskIDInLogs() {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := context.WithValue(context.Background(), log.CtxLogKey, &log.MLogger{Logger: zap.New(core)})
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(nil, false)
+
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	manager.Block(s.segmentID)
+	task := s.getSuiteSyncTask()
+	ch := make(chan *conc.Future[error], 1)
+	go func() {
+		ch <- manager.SyncData(ctx, task)
+	}()
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 1
+	}, time.Second, time.Millisecond*10)
+	taskID := manager.ListTasks()[0].TaskID
+	s.NotEmpty(taskID)
+
+	manager.Unblock(s.segmentID)
+	r, err := (<-ch).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrSegmentNotFound)
+
+	expected := []string{
+		"sync task submitted",
+		"failed to sync data, segment not found in metacache",
+		"sync task finished",
+	}
+	for _, msg := range expected {
+		entries := logs.FilterMessage(msg).All()
+		s.Require().Len(entries, 1, msg)
+		s.Equal(taskID, entries[0].ContextMap()["taskID"], msg)
+	}
+
+	// each task gets a unique id
+	r, err = manager.SyncData(ctx, s.getSuiteSyncTask()).Await()
+	s.NoError(err)
+	s.Error(r)
+	ids := lo.Uniq(lo.Map(logs.FilterMessage("sync task submitted").All(), func(entry observer.LoggedEntry, _ int) any {
+		return entry.ContextMap()["taskID"]
+	}))
+	s.Len(ids, 2)
+}
+
+func (s *SyncManagerSuite) TestMaxQueueDepth() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithMaxQueueDepth(2))
+	s.NoError(err)
+
+	manager.Block(1)
+	futures := []<-chan *conc.Future[error]{
+		s.asyncSyncData(manager, newMockSyncTask(1, "channel_1", 100)),
+		s.asyncSyncData(manager, newMockSyncTask(1, "channel_1", 200)),
+	}
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	// queue is full, rejected without blocking
+	rejected := newMockSyncTask(2, "channel_1", 300)
+	r, err := manager.SyncData(context.Background(), rejected).Await()
+	s.NoError(err)
+	s.ErrorIs(r, merr.ErrServiceUnavailable)
+	s.EqualValues(0, rejected.runCount.Load())
+	s.Len(manager.ListTasks(), 2)
+
+	manager.Unblock(1)
+	for _, f := range futures {
+		r, err := (<-f).Await()
+		s.NoError(err)
+		s.NoError(r)
+	}
+
+	// accepted again after the queue drains
+	r, err = manager.SyncData(context.Background(), rejected).Await()
+	s.NoError(err)
+	s.NoError(r)
+	s.EqualValues(1, rejected.runCount.Load())
+}
+
+func (s *SyncManagerSuite) TestScheduledFlush() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithScheduledFlush(10*time.Millisecond, 200*time.Millisecond))
+	s.NoError(err)
+
+	source := &mockFlushSource{
+		manager:  manager,
+		channel:  "channel_1",
+		buffered: map[int64]time.Time{1: time.Now(), 2: time.Now()},
+	}
+	manager.RegisterFlushSource(source.channel, source)
+	defer manager.UnregisterFlushSource(source.channel)
+
+	// segment 2 is being synced, the scheduled flush shall skip it
+	manager.Block(2)
+	defer manager.Unblock(2)
+	s.asyncSyncData(manager, newMockSyncTask(2, source.channel, 100))
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 1
+	}, time.Second, time.Millisecond*10)
+
+	// buffers are not flushed before the age threshold
+	time.Sleep(100 * time.Millisecond)
+	s.Empty(source.flushed())
+
+	s.Eventually(func() bool {
+		return lo.Contains(source.flushed(), int64(1))
+	}, time.Second, time.Millisecond*10)
+	s.NotContains(source.flushed(), int64(2))
+}
+
+func (s *SyncManagerSuite) TestEarliestPositionWithBlock() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	manager.Block(1)
+	manager.Block(2)
+	defer manager.Unblock(2)
+	blocked := s.asyncSyncData(manager, newMockSyncTask(1, "channel_1", 100))
+	s.asyncSyncData(manager, newMockSyncTask(2, "channel_1", 200))
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	// tasks of other segments finish, the blocked one still holds the checkpoint
+	r, err := manager.SyncData(context.Background(), newMockSyncTask(3, "channel_1", 50)).Await()
+	s.NoError(err)
+	s.NoError(r)
+	segmentID, pos := manager.GetEarliestPosition("channel_1")
+	s.EqualValues(1, segmentID)
+	s.EqualValues(100, pos.GetTimestamp())
+
+	// advances after the blocked task finishes
+	manager.Unblock(1)
+	r, err = (<-blocked).Await()
+	s.NoError(err)
+	s.NoError(r)
+	segmentID, pos = manager.GetEarliestPosition("channel_1")
+	s.EqualValues(2, segmentID)
+	s.EqualValues(200, pos.GetTimestamp())
+
+	// cancelled tasks no longer hold the checkpoint
+	manager.CancelChannel("channel_1")
+	_, pos = manager.GetEarliestPosition("channel_1")
+	s.Nil(pos)
+}
+
+func (s *SyncManagerSuite) TestEarliestRunningPosition() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	running := newMockSyncTask(2, "channel_1", 200)
+	running.release = make(chan struct{})
+	f1 := s.asyncSyncData(manager, running)
+	s.Eventually(func() bool { return running.runCount.Load() == 1 }, time.Second, time.Millisecond*10)
+
+	// the task of the blocked segment is queued without the segment lock
+	manager.Block(1)
+	queued := s.asyncSyncData(manager, newMockSyncTask(1, "channel_1", 100))
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	segmentID, pos := manager.GetEarliestPosition("channel_1")
+	s.EqualValues(1, segmentID)
+	s.EqualValues(100, pos.GetTimestamp())
+	segmentID, pos = manager.GetEarliestRunningPosition("channel_1")
+	s.EqualValues(2, segmentID)
+	s.EqualValues(200, pos.GetTimestamp())
+
+	close(running.release)
+	manager.Unblock(1)
+	for _, f := range []<-chan *conc.Future[error]{f1, queued} {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+	_, pos = manager.GetEarliestRunningPosition("channel_1")
+	s.Nil(pos)
+}
+
+func (s *SyncManagerSuite) TestEarliestRunningPositionLocked() {
+	manager, err := NewSyncManager(1, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	running := newMockSyncTask(2, "channel_1", 200)
+	running.release = make(chan struct{})
+	f1 := s.asyncSyncData(manager, running)
+	s.Eventually(func() bool { return running.runCount.Load() == 1 }, time.Second, time.Millisecond*10)
+
+	// the task holds the segment lock while waiting for the only worker
+	waiting := newMockSyncTask(1, "channel_1", 100)
+	f2 := s.asyncSyncData(manager, waiting)
+	s.Eventually(func() bool {
+		segmentID, pos := manager.GetEarliestRunningPosition("channel_1")
+		return segmentID == 1 && pos.GetTimestamp() == 100
+	}, time.Second, time.Millisecond*10)
+	s.EqualValues(0, waiting.runCount.Load())
+
+	close(running.release)
+	for _, f := range []<-chan *conc.Future[error]{f1, f2} {
+		_, err := (<-f).Await()
+		s.NoError(err)
+	}
+}
+
+func (s *SyncManagerSuite) TestFirstSyncCallback() {
+	var mu sync.Mutex
+	var notified []int64
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator, WithFirstSyncCallback(func(segmentID int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, segmentID)
+	}))
+	s.NoError(err)
+
+	// failed sync does not count
+	failed := newMockSyncTask(1, "channel_1", 100)
+	failed.err = errors.New("mock")
+	r, err := manager.SyncData(context.Background(), failed).Await()
+	s.NoError(err)
+	s.Error(r)
+	s.Empty(notified)
+
+	for _, ts := range []uint64{200, 300} {
+		r, err := manager.SyncData(context.Background(), newMockSyncTask(1, "channel_1", ts)).Await()
+		s.NoError(err)
+		s.NoError(r)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	s.Equal([]int64{1}, notified)
+}
+
+func (s *SyncManagerSuite) TestTaskHooks() {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string, segmentID int64, taskKey string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, fmt.Sprintf("%s-%d-%s", event, segmentID, taskKey))
+	}
+
+	var manager SyncManager
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator,
+		WithTaskKeyFunc(func(task Task, seq int64) string { return "key" }),
+		WithTaskHooks(TaskHooks{
+			OnEnqueue: func(segmentID int64, taskKey string) { record("enqueue", segmentID, taskKey) },
+			OnStart:   func(segmentID int64, taskKey string) { record("start", segmentID, taskKey) },
+			OnComplete: func(segmentID int64, taskKey string, err error) {
+				s.NoError(err)
+				// segment lock is released, otherwise Block deadlocks
+				manager.Block(segmentID)
+				manager.Unblock(segmentID)
+				record("complete", segmentID, taskKey)
+			},
+		}))
+	s.NoError(err)
+
+	r, err := manager.SyncData(context.Background(), newMockSyncTask(1, "channel_1", 100)).Await()
+	s.NoError(err)
+	s.NoError(r)
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Equal([]string{"enqueue-1-key", "start-1-key", "complete-1-key"}, events)
+}
+
+func (s *SyncManagerSuite) TestReorderBufferDepth() {
+	manager, err := NewSyncManager(10, s.chunkManager, s.allocator)
+	s.NoError(err)
+
+	// tasks run in submit order, while completions are emitted in checkpoint order
+	first := newMockSyncTask(1, "channel_1", 200)
+	first.release = make(chan struct{})
+	second := newMockSyncTask(1, "channel_1", 300)
+	stuck := newMockSyncTask(1, "channel_1", 100)
+	stuck.release = make(chan struct{})
+
+	// SyncData blocks until the previous task of the segment finishes, submit one by one
+	futures := []<-chan *conc.Future[error]{s.asyncSyncData(manager, first)}
+	for idx, task := range []Task{second, stuck} {
+		s.Eventually(func() bool {
+			return len(manager.ListTasks()) == idx+1
+		}, time.Second, time.Millisecond*10)
+		futures = append(futures, s.asyncSyncData(manager, task))
+	}
+	s.Eventually(func() bool {
+		return len(manager.ListTasks()) == 3
+	}, time.Second, time.Millisecond*10)
+	s.Equal(0, manager.ReorderBufferDepth(1))
+
+	close(first.release)
+	s.Eventually(func() bool {
+		return manager.ReorderBufferDepth(1) == 2
+	}, time.Second, time.Millisecond*10)
+	s.EqualValues(1, stuck.runCount.Load())
+	s.Equal(0, manager.ReorderBufferDepth(2))
+
+	close(stuck.release)
+	for _, f := range futures {
+		r, err := (<-f).Await()
+		s.NoError(err)
+		s.NoError(r)
+	}
+	s.Equal(0, manager.ReorderBufferDepth(1))
+}
+
+// asyncSyncData submits task in another goroutine since SyncData blocks when the segment is blocked.
+func (s *SyncManagerSuite) asyncSyncData(manager SyncManager, task Task) <-chan *conc.Future[error] {
+	ch := make(chan *conc.Future[error], 1)
+	go func() {
+		ch <- manager.SyncData(context.Background(), task)
+	}()
+	return ch
+}
+
+// mockSyncTask is a light-weight task for testing sync manager scheduling.
+type mockSyncTask struct {
+	segmentID int64
+	channel   string
+	ts        uint64
+	err       error
+	origin    TaskOrigin
+	runCount  *atomic.Int32
+	payload   int64
+	priority  int
+	// release blocks Run until closed if not nil
+	release chan struct{}
+}
+
+func newMockSyncTask(segmentID int64, channel string, ts uint64) *mockSyncTask {
+	return &mockSyncTask{
+		segmentID: segmentID,
+		channel:   channel,
+		ts:        ts,
+		runCount:  atomic.NewInt32(0),
+	}
+}
+
+func (t *mockSyncTask) SegmentID() int64 { return t.segmentID }
+func (t *mockSyncTask) Checkpoint() *msgpb.MsgPosition {
+	return &msgpb.MsgPosition{ChannelName: t.channel, Timestamp: t.ts}
+}
+
+func (t *mockSyncTask) StartPosition() *msgpb.MsgPosition {
+	return &msgpb.MsgPosition{ChannelName: t.channel, Timestamp: t.ts}
+}
+func (t *mockSyncTask) ChannelName() string { return t.channel }
+func (t *mockSyncTask) Origin() TaskOrigin  { return t.origin }
+
+func (t *mockSyncTask) PayloadSize() int64 { return t.payload }
+
+func (t *mockSyncTask) Priority() int { return t.priority }
+
+func (t *mockSyncTask) Run() error {
+	t.runCount.Inc()
+	if t.release != nil {
+		<-t.release
+	}
+	return t.err
+}
+
+// mockPartitionSyncTask is a mockSyncTask which knows the collection and partition of its segment.
+type mockPartitionSyncTask struct {
+	*mockSyncTask
+	partitionID int64
+}
+
+func newMockPartitionSyncTask(partitionID int64, segmentID int64, channel string, ts uint64) *mockPartitionSyncTask {
+	return &mockPartitionSyncTask{mockSyncTask: newMockSyncTask(segmentID, channel, ts), partitionID: partitionID}
+}
+
+func (t *mockPartitionSyncTask) CollectionID() int64 { return 1 }
+func (t *mockPartitionSyncTask) PartitionID() int64  { return t.partitionID }
+
+// mockFlushSource is a channel buffer which records segments flushed by the scheduled flush.
+type mockFlushSource struct {
+	mu       sync.Mutex
+	manager  SyncManager
+	channel  string
+	buffered map[int64]time.Time
+	synced   []int64
+}
+
+func (src *mockFlushSource) StaleSegments(maxAge time.Duration) []int64 {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	return lo.FilterMap(lo.Keys(src.buffered), func(segmentID int64, _ int) (int64, bool) {
+		return segmentID, time.Since(src.buffered[segmentID]) > maxAge
+	})
+}
+
+func (src *mockFlushSource) SyncSegments(ctx context.Context, segmentIDs []int64) error {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	for _, segmentID := range segmentIDs {
+		delete(src.buffered, segmentID)
+		src.synced = append(src.synced, segmentID)
+		_ = src.manager.SyncData(ctx, newMockSyncTask(segmentID, src.channel, 200))
+	}
+	return nil
+}
+
+func (src *mockFlushSource) flushed() []int64 {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	return append([]int64{}, src.synced...)
+}
+
 func TestSyncManager(t *testing.T) {
 	suite.Run(t, new(SyncManagerSuite))
 }
@@ -0,0 +1,40 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+// SyncResult is the outcome of a sync task submitted via SyncDataWithStats.
+type SyncResult struct {
+	Err error
+	// BytesWritten is the total size of the serialized blobs persisted by the task.
+	BytesWritten int64
+	// FilesWritten is the number of objects persisted, each chunk of a large blob counts as one.
+	FilesWritten int
+}
+
+// writtenStatsTask is implemented by tasks which record the size of the data they persisted.
+type writtenStatsTask interface {
+	BytesWritten() int64
+	FilesWritten() int
+}
+
+// getTaskWrittenStats returns the written bytes and files of task, zero if the task does not record them.
+func getTaskWrittenStats(task Task) (int64, int) {
+	if t, ok := task.(writtenStatsTask); ok {
+		return t.BytesWritten(), t.FilesWritten()
+	}
+	return 0, 0
+}
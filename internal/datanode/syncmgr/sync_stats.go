@@ -0,0 +1,121 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus/pkg/metrics"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
+	"github.com/milvus-io/milvus/pkg/util/typeutil"
+)
+
+const syncStatsReportInterval = time.Second
+
+// SyncStats is the snapshot of the tracked tasks of sync manager.
+type SyncStats struct {
+	// Pending is the number of tasks waiting for the segment lock or a worker.
+	Pending int
+	// Running is the number of tasks being executed.
+	Running int
+	// OldestCheckpoints is the smallest checkpoint timestamp of the tracked tasks of each channel.
+	OldestCheckpoints map[string]typeutil.Timestamp
+}
+
+func (mgr syncManager) TaskStats() SyncStats {
+	stats := SyncStats{OldestCheckpoints: make(map[string]typeutil.Timestamp)}
+	mgr.tasks.Range(func(_ string, task *trackedTask) bool {
+		switch task.state.Load() {
+		case taskPending:
+			stats.Pending++
+		case taskRunning:
+			stats.Running++
+		default:
+			return true
+		}
+		ts := task.Checkpoint().GetTimestamp()
+		if oldest, ok := stats.OldestCheckpoints[task.ChannelName()]; !ok || ts < oldest {
+			stats.OldestCheckpoints[task.ChannelName()] = ts
+		}
+		return true
+	})
+	return stats
+}
+
+// statsReporter reports TaskStats to metrics periodically.
+type statsReporter struct {
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	done     chan struct{}
+}
+
+func newStatsReporter() *statsReporter {
+	return &statsReporter{
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// start reports the stats of mgr in background until stop is called.
+func (r *statsReporter) start(mgr syncManager) {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(syncStatsReportInterval)
+		defer ticker.Stop()
+		reported := typeutil.NewSet[string]()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				reported = mgr.reportStats(reported)
+			}
+		}
+	}()
+}
+
+// stop stops the background reporting started by start and waits for it to exit.
+func (r *statsReporter) stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		<-r.done
+	})
+}
+
+// reportStats sets the metrics with current TaskStats, and removes the checkpoint metrics of
+// the channels in reported which have no tracked task now. Returns the channels reported this time.
+func (mgr syncManager) reportStats(reported typeutil.Set[string]) typeutil.Set[string] {
+	nodeID := fmt.Sprint(paramtable.GetNodeID())
+	stats := mgr.TaskStats()
+	metrics.DataNodeSyncTaskNum.WithLabelValues(nodeID, metrics.PendingSyncTaskLabel).Set(float64(stats.Pending))
+	metrics.DataNodeSyncTaskNum.WithLabelValues(nodeID, metrics.RunningSyncTaskLabel).Set(float64(stats.Running))
+
+	channels := typeutil.NewSet[string]()
+	for channel, ts := range stats.OldestCheckpoints {
+		channels.Insert(channel)
+		metrics.DataNodeSyncOldestCheckpoint.WithLabelValues(nodeID, channel).Set(float64(tsoutil.PhysicalTime(ts).UnixMilli()))
+	}
+	for channel := range reported {
+		if !channels.Contain(channel) {
+			metrics.DataNodeSyncOldestCheckpoint.DeleteLabelValues(nodeID, channel)
+		}
+	}
+	return channels
+}
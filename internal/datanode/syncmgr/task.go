@@ -1,10 +1,14 @@
 package syncmgr
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"path"
 	"strconv"
+	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/samber/lo"
 	"go.uber.org/zap"
 
@@ -18,9 +22,12 @@ import (
 	"github.com/milvus-io/milvus/internal/storage"
 	"github.com/milvus-io/milvus/pkg/common"
 	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/metrics"
 	"github.com/milvus-io/milvus/pkg/util/merr"
 	"github.com/milvus-io/milvus/pkg/util/metautil"
+	"github.com/milvus-io/milvus/pkg/util/paramtable"
 	"github.com/milvus-io/milvus/pkg/util/retry"
+	"github.com/milvus-io/milvus/pkg/util/timerecord"
 	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
@@ -49,6 +56,9 @@ type SyncTask struct {
 
 	isFlush bool
 	isDrop  bool
+	origin  TaskOrigin
+	// priority decides the dispatch order when workers are saturated, higher runs first
+	priority int
 
 	metacache  metacache.MetaCache
 	metaWriter MetaWriter
@@ -60,16 +70,52 @@ type SyncTask struct {
 	segmentData map[string][]byte
 
 	writeRetryOpts []retry.Option
+	// multipartThreshold is the size above which blobs are uploaded via multipart write,
+	// non-positive means never.
+	multipartThreshold int64
+	// blobCodec is the compression of uploaded delta and stats logs, recorded as the suffix of log path.
+	// empty means uncompressed.
+	blobCodec storage.BlobCodec
+	// blobChecksum makes the task upload the CRC32C of each blob as a sidecar object,
+	// which is verified by storage.DecodeBlob when the blob is read.
+	blobChecksum bool
+	// serializeParallelism is the max number of columns serialized concurrently,
+	// columns are serialized sequentially if it's not greater than 1.
+	serializeParallelism int
 
 	failureCallback func(err error)
+
+	// ctx carries the contextual logger set by sync manager at submit,
+	// it's cancelled once the segment is cancelled in sync manager
+	ctx context.Context
+
+	// time cost of serializing and uploading, set after each phase finishes
+	serializeDuration time.Duration
+	uploadDuration    time.Duration
+
+	// size and number of objects persisted by writeLogs
+	bytesWritten int64
+	filesWritten int
+}
+
+func (t *SyncTask) setContext(ctx context.Context) {
+	t.ctx = ctx
+}
+
+func (t *SyncTask) logContext() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
 }
 
 func (t *SyncTask) getLogger() *log.MLogger {
-	return log.Ctx(context.Background()).With(
+	return log.Ctx(t.logContext()).With(
 		zap.Int64("collectionID", t.collectionID),
 		zap.Int64("partitionID", t.partitionID),
 		zap.Int64("segmentID", t.segmentID),
 		zap.String("channel", t.channelName),
+		zap.String("origin", string(t.Origin())),
 	)
 }
 
@@ -104,8 +150,10 @@ func (t *SyncTask) Run() error {
 		t.segmentID = t.segment.CompactTo()
 	}
 
+	tr := timerecord.NewTimeRecorder("syncTask")
 	err = t.serializeInsertData()
 	if err != nil {
+		err = merr.Combine(ErrSerializeFailed, err)
 		log.Warn("failed to serialize insert data", zap.Error(err))
 		t.handleError(err)
 		return err
@@ -113,17 +161,30 @@ func (t *SyncTask) Run() error {
 
 	err = t.serializeDeleteData()
 	if err != nil {
+		err = merr.Combine(ErrSerializeFailed, err)
 		log.Warn("failed to serialize delete data", zap.Error(err))
 		t.handleError(err)
 		return err
 	}
+	t.serializeDuration = tr.RecordSpan()
+	metrics.DataNodeEncodeBufferLatency.WithLabelValues(fmt.Sprint(paramtable.GetNodeID())).Observe(float64(t.serializeDuration.Milliseconds()))
 
+	if err := t.logContext().Err(); err != nil {
+		log.Info("sync task cancelled, skip uploading", zap.Error(err))
+		return err
+	}
 	err = t.writeLogs()
+	if err != nil && t.logContext().Err() != nil {
+		log.Info("sync task cancelled while uploading", zap.Error(err))
+		return err
+	}
 	if err != nil {
 		log.Warn("failed to save serialized data into storage", zap.Error(err))
 		t.handleError(err)
 		return err
 	}
+	t.uploadDuration = tr.RecordSpan()
+	metrics.DataNodeSave2StorageLatency.WithLabelValues(fmt.Sprint(paramtable.GetNodeID()), metrics.AllLabel).Observe(float64(t.uploadDuration.Milliseconds()))
 
 	if t.metaWriter != nil {
 		err = t.writeMeta()
@@ -148,6 +209,45 @@ func (t *SyncTask) Run() error {
 	return nil
 }
 
+// dryRun runs the serialization of Run on a copy of the task without uploading anything,
+// the task itself is not modified.
+func (t *SyncTask) dryRun() error {
+	if t.schema == nil {
+		return merr.WrapErrParameterInvalidMsg("schema of segment %d not provided", t.segmentID)
+	}
+	segment, has := t.metacache.GetSegmentByID(t.segmentID)
+	if !has {
+		return merr.WrapErrSegmentNotFound(t.segmentID)
+	}
+	if segment.CompactTo() == metacache.NullSegment {
+		return nil
+	}
+
+	dry := t.dryRunCopy()
+	dry.segment = segment
+	if err := dry.serializeInsertData(); err != nil {
+		return err
+	}
+	return dry.serializeDeleteData()
+}
+
+// dryRunCopy returns a copy of the task with fresh outputs and an allocator which never consumes real ids.
+func (t *SyncTask) dryRunCopy() *SyncTask {
+	dry := *t
+	dry.allocator = dryRunAllocator{}
+	dry.insertBinlogs = make(map[int64]*datapb.FieldBinlog)
+	dry.statsBinlogs = make(map[int64]*datapb.FieldBinlog)
+	dry.deltaBinlog = &datapb.FieldBinlog{}
+	dry.segmentData = make(map[string][]byte)
+	return &dry
+}
+
+// dryRunAllocator allocates placeholder ids for dry run.
+type dryRunAllocator struct{}
+
+func (dryRunAllocator) Alloc(count uint32) (int64, int64, error) { return 0, int64(count), nil }
+func (dryRunAllocator) AllocOne() (int64, error)                 { return 0, nil }
+
 func (t *SyncTask) serializeInsertData() error {
 	err := t.serializeBinlog()
 	if err != nil {
@@ -183,9 +283,11 @@ func (t *SyncTask) serializeDeleteData() error {
 	data := &datapb.Binlog{}
 
 	blobKey := metautil.JoinIDPath(t.collectionID, t.partitionID, t.segmentID, logID)
-	blobPath := path.Join(t.chunkManager.RootPath(), common.SegmentDeltaLogPath, blobKey)
+	blobPath, err := t.putBlob(path.Join(t.chunkManager.RootPath(), common.SegmentDeltaLogPath, blobKey), value)
+	if err != nil {
+		return err
+	}
 
-	t.segmentData[blobPath] = value
 	data.LogSize = int64(len(blob.Value))
 	data.LogPath = blobPath
 	data.TimestampFrom = t.tsFrom
@@ -200,6 +302,10 @@ func (t *SyncTask) serializeBinlog() error {
 	if t.insertData == nil {
 		return nil
 	}
+	// reject mismatched data before serializing, which panics on it
+	if err := t.insertData.Validate(t.schema); err != nil {
+		return err
+	}
 
 	// get memory size of buffer data
 	memSize := make(map[int64]int)
@@ -209,7 +315,7 @@ func (t *SyncTask) serializeBinlog() error {
 
 	inCodec := t.getInCodec()
 
-	blobs, err := inCodec.Serialize(t.partitionID, t.segmentID, t.insertData)
+	blobs, err := inCodec.SerializeParallel(t.partitionID, t.segmentID, t.insertData, t.serializeParallelism)
 	if err != nil {
 		return err
 	}
@@ -228,6 +334,7 @@ func (t *SyncTask) serializeBinlog() error {
 
 		k := metautil.JoinIDPath(t.collectionID, t.partitionID, t.segmentID, fieldID, logidx)
 		// [rootPath]/[insert_log]/key
+		// insert binlogs are read by segcore and index build as is, so they are never compressed
 		key := path.Join(t.chunkManager.RootPath(), common.SegmentInsertLogPath, k)
 		t.segmentData[key] = blob.GetValue()
 		t.appendBinlog(fieldID, &datapb.Binlog{
@@ -266,9 +373,7 @@ func (t *SyncTask) serializeSinglePkStats(fieldID int64, stats *storage.PrimaryK
 	if err != nil {
 		return err
 	}
-	t.convertBlob2StatsBinlog(blob, fieldID, logidx, rowNum)
-
-	return nil
+	return t.convertBlob2StatsBinlog(blob, fieldID, logidx, rowNum)
 }
 
 func (t *SyncTask) serializeMergedPkStats(fieldID int64, pkType schemapb.DataType) error {
@@ -292,17 +397,16 @@ func (t *SyncTask) serializeMergedPkStats(fieldID int64, pkType schemapb.DataTyp
 	if err != nil {
 		return err
 	}
-	t.convertBlob2StatsBinlog(blob, fieldID, int64(storage.CompoundStatsType), totalRowNum)
-
-	return nil
+	return t.convertBlob2StatsBinlog(blob, fieldID, int64(storage.CompoundStatsType), totalRowNum)
 }
 
-func (t *SyncTask) convertBlob2StatsBinlog(blob *storage.Blob, fieldID, logID int64, rowNum int64) {
+func (t *SyncTask) convertBlob2StatsBinlog(blob *storage.Blob, fieldID, logID int64, rowNum int64) error {
 	key := metautil.JoinIDPath(t.collectionID, t.partitionID, t.segmentID, fieldID, logID)
-	key = path.Join(t.chunkManager.RootPath(), common.SegmentStatslogPath, key)
-
 	value := blob.GetValue()
-	t.segmentData[key] = value
+	key, err := t.putBlob(path.Join(t.chunkManager.RootPath(), common.SegmentStatslogPath, key), value)
+	if err != nil {
+		return err
+	}
 	t.appendStatslog(fieldID, &datapb.Binlog{
 		EntriesNum:    rowNum,
 		TimestampFrom: t.tsFrom,
@@ -310,6 +414,18 @@ func (t *SyncTask) convertBlob2StatsBinlog(blob *storage.Blob, fieldID, logID in
 		LogPath:       key,
 		LogSize:       int64(len(value)),
 	})
+	return nil
+}
+
+// putBlob adds the delta or stats blob into segment data, compressed with the codec of task,
+// it returns the key of the uploaded object.
+func (t *SyncTask) putBlob(key string, value []byte) (string, error) {
+	key, value, err := storage.CompressBlob(key, value, t.blobCodec)
+	if err != nil {
+		return "", err
+	}
+	t.segmentData[key] = value
+	return key, nil
 }
 
 func (t *SyncTask) serializePkStatsLog() error {
@@ -365,9 +481,105 @@ func (t *SyncTask) appendDeltalog(deltalog *datapb.Binlog) {
 
 // writeLogs writes log files (binlog/deltalog/statslog) into storage via chunkManger.
 func (t *SyncTask) writeLogs() error {
-	return retry.Do(context.Background(), func() error {
-		return t.chunkManager.MultiWrite(context.Background(), t.segmentData)
+	contents, largeBlobs := t.splitLargeBlobs()
+	ctx := t.logContext()
+	err := retry.Do(ctx, func() error {
+		return classifyRetryError(t.chunkManager.MultiWrite(ctx, contents))
 	}, t.writeRetryOpts...)
+	if err != nil {
+		return merr.Combine(ErrUploadFailed, errors.Wrapf(err, "failed to write %d logs", len(contents)))
+	}
+	for _, value := range contents {
+		t.recordWritten(value)
+	}
+
+	// blobs above the multipart threshold are uploaded and retried independently
+	for key, value := range largeBlobs {
+		err := retry.Do(ctx, func() error {
+			return classifyRetryError(t.writeBlob(ctx, key, value))
+		}, t.writeRetryOpts...)
+		if err != nil {
+			return merr.Combine(ErrUploadFailed, errors.Wrapf(err, "failed to write log %s", key))
+		}
+		t.recordWritten(value)
+	}
+
+	// checksums are written after the blobs, so that no checksum exists without its blob
+	if t.blobChecksum {
+		checksums := make(map[string][]byte, len(t.segmentData))
+		for key, value := range t.segmentData {
+			checksums[storage.BlobChecksumKey(key)] = storage.BlobChecksum(value)
+		}
+		err := retry.Do(ctx, func() error {
+			return classifyRetryError(t.chunkManager.MultiWrite(ctx, checksums))
+		}, t.writeRetryOpts...)
+		if err != nil {
+			return merr.Combine(ErrUploadFailed, errors.Wrapf(err, "failed to write %d checksums", len(checksums)))
+		}
+		for _, value := range checksums {
+			t.recordWritten(value)
+		}
+	}
+	return nil
+}
+
+// writeBlob writes a single blob, which is streamed in parts if it exceeds the multipart threshold.
+func (t *SyncTask) writeBlob(ctx context.Context, key string, value []byte) error {
+	if t.multipartThreshold > 0 && int64(len(value)) > t.multipartThreshold {
+		return t.chunkManager.MultipartWrite(ctx, key, bytes.NewReader(value), int64(len(value)))
+	}
+	return t.chunkManager.Write(ctx, key, value)
+}
+
+func (t *SyncTask) recordWritten(value []byte) {
+	t.bytesWritten += int64(len(value))
+	t.filesWritten++
+}
+
+var (
+	// ErrSerializeFailed marks the sync failures in serializing the buffered data,
+	// which are caused by bugs or bad data and shall not be retried.
+	ErrSerializeFailed = errors.New("sync task serialize failed")
+	// ErrUploadFailed marks the sync failures in uploading the serialized data into storage,
+	// which are usually transient.
+	ErrUploadFailed = errors.New("sync task upload failed")
+)
+
+// nonRetryableErrors are the error classes which could not be fixed by retrying,
+// sync shall fail fast on them instead of exhausting all attempts.
+var nonRetryableErrors = []error{
+	ErrSerializeFailed,
+	merr.ErrParameterInvalid,
+	merr.ErrIoKeyNotFound,
+}
+
+// classifyRetryError marks err as unrecoverable for retry.Do if it is non-retryable.
+func classifyRetryError(err error) error {
+	if err != nil && errors.IsAny(err, nonRetryableErrors...) {
+		return retry.Unrecoverable(err)
+	}
+	return err
+}
+
+// splitLargeBlobs separates blobs exceeding the multipart threshold from segment data,
+// which are written one by one via multipart upload.
+// Large blobs are never split into several objects, since segcore and index build read binlogs by path,
+// uploading them in parts via MultipartWrite bounds the size of single put instead.
+func (t *SyncTask) splitLargeBlobs() (map[string][]byte, map[string][]byte) {
+	if t.multipartThreshold <= 0 {
+		return t.segmentData, nil
+	}
+
+	contents := make(map[string][]byte)
+	largeBlobs := make(map[string][]byte)
+	for key, value := range t.segmentData {
+		if int64(len(value)) > t.multipartThreshold {
+			largeBlobs[key] = value
+			continue
+		}
+		contents[key] = value
+	}
+	return contents, largeBlobs
 }
 
 // writeMeta updates segments via meta writer in option.
@@ -384,6 +596,46 @@ func (t *SyncTask) getInCodec() *storage.InsertCodec {
 	return storage.NewInsertCodecWithSchema(meta)
 }
 
+// SerializeDuration returns the time cost of serializing the buffered data, zero if not finished.
+func (t *SyncTask) SerializeDuration() time.Duration {
+	return t.serializeDuration
+}
+
+// UploadDuration returns the time cost of uploading the serialized data, zero if not finished.
+func (t *SyncTask) UploadDuration() time.Duration {
+	return t.uploadDuration
+}
+
+// BytesWritten returns the total size of the logs uploaded by the task.
+func (t *SyncTask) BytesWritten() int64 {
+	return t.bytesWritten
+}
+
+// FilesWritten returns the number of objects uploaded by the task.
+func (t *SyncTask) FilesWritten() int {
+	return t.filesWritten
+}
+
+// PayloadSize returns the memory size of the buffered insert and delete data.
+func (t *SyncTask) PayloadSize() int64 {
+	var size int64
+	if t.insertData != nil {
+		size += int64(t.insertData.GetMemorySize())
+	}
+	if t.deleteData != nil {
+		size += t.deleteData.Size()
+	}
+	return size
+}
+
+func (t *SyncTask) CollectionID() int64 {
+	return t.collectionID
+}
+
+func (t *SyncTask) PartitionID() int64 {
+	return t.partitionID
+}
+
 func (t *SyncTask) SegmentID() int64 {
 	return t.segmentID
 }
@@ -396,6 +648,19 @@ func (t *SyncTask) StartPosition() *msgpb.MsgPosition {
 	return t.startPosition
 }
 
+// Origin returns the source which submits the task, flush by default.
+func (t *SyncTask) Origin() TaskOrigin {
+	if t.origin == "" {
+		return OriginFlush
+	}
+	return t.origin
+}
+
+// Priority returns the dispatch priority of the task, zero by default.
+func (t *SyncTask) Priority() int {
+	return t.priority
+}
+
 func (t *SyncTask) ChannelName() string {
 	return t.channelName
 }
@@ -0,0 +1,66 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"fmt"
+	"sync"
+)
+
+// getTaskDedupKey identifies the re-submissions of a task, which share the segment and checkpoint.
+func getTaskDedupKey(task Task) string {
+	collectionID, partitionID := getTaskPartition(task)
+	return fmt.Sprintf("%d-%d-%d-%d", collectionID, partitionID, task.SegmentID(), task.Checkpoint().GetTimestamp())
+}
+
+// taskDeduper tracks the unfinished tasks by dedup key, so that re-submitted tasks share the result of the first one.
+type taskDeduper struct {
+	mu    sync.Mutex
+	tasks map[string]*trackedTask
+}
+
+func newTaskDeduper() *taskDeduper {
+	return &taskDeduper{tasks: make(map[string]*trackedTask)}
+}
+
+// getOrAdd returns the unfinished task of key and true if any, otherwise adds task and returns false.
+// Cancelled tasks are replaced, since their result is not the one of a re-submission.
+// Nil deduper, i.e. dedup disabled, never finds a duplicate.
+func (d *taskDeduper) getOrAdd(key string, task *trackedTask) (*trackedTask, bool) {
+	if d == nil {
+		return task, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if existing, ok := d.tasks[key]; ok && existing.state.Load() != taskCancelled {
+		return existing, true
+	}
+	d.tasks[key] = task
+	return task, false
+}
+
+// remove removes task of key, it does nothing if task has been replaced.
+func (d *taskDeduper) remove(key string, task *trackedTask) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tasks[key] == task {
+		delete(d.tasks, key)
+	}
+}
@@ -0,0 +1,39 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+// TaskOrigin is the source which submits the sync task.
+type TaskOrigin string
+
+const (
+	OriginFlush      TaskOrigin = "flush"
+	OriginCompaction TaskOrigin = "compaction"
+	OriginImport     TaskOrigin = "import"
+)
+
+// originTask is implemented by tasks which know their origin.
+type originTask interface {
+	Origin() TaskOrigin
+}
+
+// getTaskOrigin returns the origin of task, tasks without origin are treated as flush ones.
+func getTaskOrigin(task Task) TaskOrigin {
+	if t, ok := task.(originTask); ok && t.Origin() != "" {
+		return t.Origin()
+	}
+	return OriginFlush
+}
@@ -0,0 +1,53 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import "sort"
+
+// partitionTask is implemented by tasks which know the collection and partition of their segment.
+type partitionTask interface {
+	CollectionID() int64
+	PartitionID() int64
+}
+
+// getTaskPartition returns the collection and partition id of task, zero ids for tasks without partition.
+func getTaskPartition(task Task) (collectionID int64, partitionID int64) {
+	if t, ok := task.(partitionTask); ok {
+		return t.CollectionID(), t.PartitionID()
+	}
+	return 0, 0
+}
+
+// SegmentKey identifies a segment together with the collection and partition it belongs to.
+type SegmentKey struct {
+	CollectionID int64
+	PartitionID  int64
+	SegmentID    int64
+}
+
+// sortSegmentKeys sorts keys by collection, partition and segment id.
+func sortSegmentKeys(keys []SegmentKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CollectionID != keys[j].CollectionID {
+			return keys[i].CollectionID < keys[j].CollectionID
+		}
+		if keys[i].PartitionID != keys[j].PartitionID {
+			return keys[i].PartitionID < keys[j].PartitionID
+		}
+		return keys[i].SegmentID < keys[j].SegmentID
+	})
+}
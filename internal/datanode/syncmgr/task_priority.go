@@ -0,0 +1,142 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// priorityTask is implemented by tasks which shall be dispatched before others,
+// e.g. the tasks of segments holding the channel checkpoint.
+type priorityTask interface {
+	Priority() int
+}
+
+// getTaskPriority returns the priority of task, tasks without priority are of zero priority.
+func getTaskPriority(task Task) int {
+	if t, ok := task.(priorityTask); ok {
+		return t.Priority()
+	}
+	return 0
+}
+
+// dispatchJob is a task waiting in dispatchQueue for a free worker.
+type dispatchJob struct {
+	priority int
+	seq      int64
+	channel  string
+	run      func() error
+
+	// done is closed after the job finishes, err holds the result then.
+	done chan struct{}
+	err  error
+}
+
+// dispatchQueue orders jobs by priority, jobs of the same priority are in push order.
+// If channelLimit is positive, jobs of the channels already running channelLimit jobs are held back.
+type dispatchQueue struct {
+	mu   sync.Mutex
+	jobs dispatchJobHeap
+	seq  int64
+
+	channelLimit int
+	running      map[string]int
+	// skipped is the number of pops which found no runnable job,
+	// each of them is redone once a running job finishes.
+	skipped int
+}
+
+func newDispatchQueue() *dispatchQueue {
+	return &dispatchQueue{
+		running: make(map[string]int),
+	}
+}
+
+func (q *dispatchQueue) push(job *dispatchJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	job.seq = q.seq
+	heap.Push(&q.jobs, job)
+}
+
+// pop returns the runnable job of the highest priority, the caller shall make sure the queue is not empty.
+// It returns nil if all jobs are held back by the channel limit, the caller shall retry after finish returns true.
+func (q *dispatchQueue) pop() *dispatchJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	idx := -1
+	for i, job := range q.jobs {
+		if q.channelLimit > 0 && q.running[job.channel] >= q.channelLimit {
+			continue
+		}
+		if idx < 0 || q.jobs.Less(i, idx) {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		q.skipped++
+		return nil
+	}
+	job := heap.Remove(&q.jobs, idx).(*dispatchJob)
+	q.running[job.channel]++
+	return job
+}
+
+// finish marks job not running, returns true if a skipped pop shall be redone.
+func (q *dispatchQueue) finish(job *dispatchJob) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running[job.channel]--; q.running[job.channel] <= 0 {
+		delete(q.running, job.channel)
+	}
+	if q.skipped > 0 {
+		q.skipped--
+		return true
+	}
+	return false
+}
+
+func (q *dispatchQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs.Len()
+}
+
+type dispatchJobHeap []*dispatchJob
+
+func (h dispatchJobHeap) Len() int { return len(h) }
+
+func (h dispatchJobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h dispatchJobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *dispatchJobHeap) Push(x any) { *h = append(*h, x.(*dispatchJob)) }
+
+func (h *dispatchJobHeap) Pop() any {
+	old := *h
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return job
+}
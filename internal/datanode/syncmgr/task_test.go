@@ -1,7 +1,11 @@
 package syncmgr
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,11 +19,14 @@ import (
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
 	"github.com/milvus-io/milvus/internal/allocator"
 	"github.com/milvus-io/milvus/internal/datanode/broker"
+	binlogio "github.com/milvus-io/milvus/internal/datanode/io"
 	"github.com/milvus-io/milvus/internal/datanode/metacache"
 	"github.com/milvus-io/milvus/internal/mocks"
 	"github.com/milvus-io/milvus/internal/proto/datapb"
 	"github.com/milvus-io/milvus/internal/storage"
 	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/conc"
+	"github.com/milvus-io/milvus/pkg/util/merr"
 	"github.com/milvus-io/milvus/pkg/util/paramtable"
 	"github.com/milvus-io/milvus/pkg/util/retry"
 	"github.com/milvus-io/milvus/pkg/util/tsoutil"
@@ -326,7 +333,8 @@ func (s *SyncTaskSuite) TestRunError() {
 
 		err := task.Run()
 
-		s.Error(err)
+		s.ErrorIs(err, ErrSerializeFailed)
+		s.NotErrorIs(err, ErrUploadFailed)
 		s.True(flag)
 	})
 
@@ -356,9 +364,288 @@ func (s *SyncTaskSuite) TestRunError() {
 
 		err := task.Run()
 
-		s.Error(err)
+		s.ErrorIs(err, ErrUploadFailed)
+		s.NotErrorIs(err, ErrSerializeFailed)
 		s.True(flag)
 	})
+
+	s.Run("chunk_manager_save_non_retryable", func() {
+		s.chunkManager.ExpectedCalls = nil
+		s.chunkManager.Calls = nil
+		s.chunkManager.EXPECT().RootPath().Return("files")
+		s.chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Return(merr.WrapErrParameterInvalidMsg("mocked"))
+		task := s.getSuiteSyncTask()
+
+		task.WithInsertData(s.getInsertBuffer()).WithDeleteData(s.getDeleteBuffer())
+		task.WithWriteRetryOptions(retry.Attempts(3), retry.Sleep(time.Millisecond))
+
+		err := task.Run()
+
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.chunkManager.AssertNumberOfCalls(s.T(), "MultiWrite", 1)
+	})
+
+	s.Run("chunk_manager_save_retryable", func() {
+		s.chunkManager.ExpectedCalls = nil
+		s.chunkManager.Calls = nil
+		s.chunkManager.EXPECT().RootPath().Return("files")
+		s.chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).Return(merr.WrapErrServiceUnavailable("mocked"))
+		task := s.getSuiteSyncTask()
+
+		task.WithInsertData(s.getInsertBuffer()).WithDeleteData(s.getDeleteBuffer())
+		task.WithWriteRetryOptions(retry.Attempts(3), retry.Sleep(time.Millisecond))
+
+		err := task.Run()
+
+		s.ErrorIs(err, merr.ErrServiceUnavailable)
+		s.ErrorIs(err, ErrUploadFailed)
+		s.chunkManager.AssertNumberOfCalls(s.T(), "MultiWrite", 3)
+	})
+
+	s.Run("serialize_fail_not_retried", func() {
+		s.chunkManager.ExpectedCalls = nil
+		s.chunkManager.Calls = nil
+		s.chunkManager.EXPECT().RootPath().Return("files").Maybe()
+		allocCalls := 0
+		s.allocator.AllocF = func(count uint32) (int64, int64, error) {
+			allocCalls++
+			return 0, 0, merr.WrapErrServiceUnavailable("mocked")
+		}
+		task := s.getSuiteSyncTask()
+
+		task.WithInsertData(s.getInsertBuffer()).WithDeleteData(s.getDeleteBuffer())
+		task.WithWriteRetryOptions(retry.Attempts(3), retry.Sleep(time.Millisecond))
+
+		err := task.Run()
+
+		s.ErrorIs(err, ErrSerializeFailed)
+		s.NotErrorIs(err, ErrUploadFailed)
+		s.Equal(1, allocCalls)
+		s.chunkManager.AssertNotCalled(s.T(), "MultiWrite", mock.Anything, mock.Anything)
+
+		// serialize failures are never retried even if the cause looks transient
+		attempts := 0
+		err = retry.Do(context.Background(), func() error {
+			attempts++
+			return classifyRetryError(err)
+		}, retry.Attempts(3), retry.Sleep(time.Millisecond))
+		s.ErrorIs(err, ErrSerializeFailed)
+		s.Equal(1, attempts)
+	})
+
+	s.Run("insert_data_mismatch_schema", func() {
+		s.chunkManager.ExpectedCalls = nil
+		s.chunkManager.Calls = nil
+		s.chunkManager.EXPECT().RootPath().Return("files").Maybe()
+		task := s.getSuiteSyncTask()
+
+		insertData := s.getInsertBuffer()
+		delete(insertData.Data, 101)
+		task.WithInsertData(insertData)
+
+		err := task.Run()
+
+		s.ErrorIs(err, ErrSerializeFailed)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.chunkManager.AssertNotCalled(s.T(), "MultiWrite", mock.Anything, mock.Anything)
+	})
+
+	s.Run("nullable_column_unsupported", func() {
+		s.chunkManager.ExpectedCalls = nil
+		s.chunkManager.Calls = nil
+		s.chunkManager.EXPECT().RootPath().Return("files").Maybe()
+		task := s.getSuiteSyncTask()
+
+		insertData := s.getInsertBuffer()
+		insertData.Data[100] = storage.NewNullableFieldData(insertData.Data[100])
+		task.WithInsertData(insertData)
+
+		err := task.Run()
+
+		s.ErrorIs(err, ErrSerializeFailed)
+		s.ErrorContains(err, "unsupported by codec")
+		s.chunkManager.AssertNotCalled(s.T(), "MultiWrite", mock.Anything, mock.Anything)
+	})
+}
+
+func (s *SyncTaskSuite) TestRunPhaseDuration() {
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, metacache.NewBloomFilterSet())
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return()
+
+	// slow allocation during serialization and slow upload
+	s.allocator.AllocF = func(count uint32) (int64, int64, error) {
+		time.Sleep(30 * time.Millisecond)
+		return time.Now().Unix(), int64(count), nil
+	}
+	s.chunkManager.ExpectedCalls = nil
+	s.chunkManager.EXPECT().RootPath().Return("files").Maybe()
+	s.chunkManager.EXPECT().MultiWrite(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, _ map[string][]byte) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	task := s.getSuiteSyncTask()
+	task.WithInsertData(s.getInsertBuffer()).WithTimeRange(50, 100)
+	s.Zero(task.SerializeDuration())
+	s.Zero(task.UploadDuration())
+
+	err := task.Run()
+	s.Require().NoError(err)
+	s.GreaterOrEqual(task.SerializeDuration(), 30*time.Millisecond)
+	s.GreaterOrEqual(task.UploadDuration(), 50*time.Millisecond)
+}
+
+// multipartCountingCM counts the calls of MultipartWrite.
+type multipartCountingCM struct {
+	storage.ChunkManager
+	multipartWrites int
+}
+
+func (cm *multipartCountingCM) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	cm.multipartWrites++
+	return cm.ChunkManager.MultipartWrite(ctx, filePath, reader, size)
+}
+
+func (s *SyncTaskSuite) TestRunMultipart() {
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, metacache.NewBloomFilterSet())
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return()
+
+	ctx := context.Background()
+	cm := &multipartCountingCM{ChunkManager: storage.NewLocalChunkManager(storage.RootPath(s.T().TempDir()))}
+	threshold := int64(1024)
+
+	task := s.getSuiteSyncTask().WithChunkManager(cm).WithMultipartThreshold(threshold)
+	task.WithInsertData(s.getInsertBuffer())
+	task.WithTimeRange(50, 100)
+	task.WithCheckpoint(&msgpb.MsgPosition{
+		ChannelName: s.channelName,
+		MsgID:       []byte{1, 2, 3, 4},
+		Timestamp:   100,
+	})
+
+	err := task.Run()
+	s.Require().NoError(err)
+
+	var large int
+	for key, value := range task.segmentData {
+		if int64(len(value)) > threshold {
+			large++
+		}
+		content, err := cm.Read(ctx, key)
+		s.NoError(err)
+		s.Equal(value, content)
+	}
+	s.Greater(large, 0)
+	s.Equal(large, cm.multipartWrites)
+	s.Equal(len(task.segmentData), task.FilesWritten())
+}
+
+func (s *SyncTaskSuite) TestRunCompressed() {
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, metacache.NewBloomFilterSet())
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return()
+
+	ctx := context.Background()
+	for codec, suffix := range map[storage.BlobCodec]string{storage.BlobCodecZstd: ".zst", storage.BlobCodecGzip: ".gz"} {
+		s.Run(string(codec), func() {
+			cm := storage.NewLocalChunkManager(storage.RootPath(s.T().TempDir()))
+			task := s.getSuiteSyncTask().WithChunkManager(cm).WithBlobCodec(codec)
+			task.WithInsertData(s.getInsertBuffer()).WithDeleteData(s.getDeleteBuffer())
+			task.WithTimeRange(50, 100)
+			task.WithCheckpoint(&msgpb.MsgPosition{
+				ChannelName: s.channelName,
+				MsgID:       []byte{1, 2, 3, 4},
+				Timestamp:   100,
+			})
+
+			err := task.Run()
+			s.Require().NoError(err)
+
+			// insert binlogs are uploaded as is
+			for _, fieldBinlog := range task.insertBinlogs {
+				for _, binlog := range fieldBinlog.GetBinlogs() {
+					s.False(strings.HasSuffix(binlog.GetLogPath(), suffix))
+					content, err := cm.Read(ctx, binlog.GetLogPath())
+					s.Require().NoError(err)
+					_, err = storage.NewBinlogReader(content)
+					s.NoError(err)
+				}
+			}
+
+			// delta logs are binlogs, stats logs are json
+			parsers := make(map[string]func([]byte) bool)
+			for _, binlog := range task.deltaBinlog.GetBinlogs() {
+				parsers[binlog.GetLogPath()] = func(content []byte) bool {
+					_, err := storage.NewBinlogReader(content)
+					return err == nil
+				}
+			}
+			for _, fieldBinlog := range task.statsBinlogs {
+				for _, binlog := range fieldBinlog.GetBinlogs() {
+					parsers[binlog.GetLogPath()] = json.Valid
+				}
+			}
+			s.NotEmpty(parsers)
+			for logPath, parse := range parsers {
+				s.True(strings.HasSuffix(logPath, suffix))
+				compressed, err := cm.Read(ctx, logPath)
+				s.Require().NoError(err)
+				s.Equal(task.segmentData[logPath], compressed)
+				s.False(parse(compressed))
+
+				content, err := storage.DecodeBlob(ctx, cm, logPath, compressed)
+				s.Require().NoError(err)
+				s.True(parse(content))
+			}
+		})
+	}
+}
+
+func (s *SyncTaskSuite) TestRunChecksum() {
+	seg := metacache.NewSegmentInfo(&datapb.SegmentInfo{}, metacache.NewBloomFilterSet())
+	metacache.UpdateNumOfRows(1000)(seg)
+	s.metacache.EXPECT().GetSegmentByID(s.segmentID).Return(seg, true)
+	s.metacache.EXPECT().UpdateSegments(mock.Anything, mock.Anything).Return()
+	storage.SetBlobChecksumVerification(true)
+	defer storage.SetBlobChecksumVerification(false)
+
+	ctx := context.Background()
+	cm := storage.NewLocalChunkManager(storage.RootPath(s.T().TempDir()))
+	task := s.getSuiteSyncTask().WithChunkManager(cm).WithBlobChecksum(true)
+	task.WithInsertData(s.getInsertBuffer())
+	task.WithTimeRange(50, 100)
+	task.WithCheckpoint(&msgpb.MsgPosition{
+		ChannelName: s.channelName,
+		MsgID:       []byte{1, 2, 3, 4},
+		Timestamp:   100,
+	})
+
+	err := task.Run()
+	s.Require().NoError(err)
+	s.Equal(len(task.segmentData), len(lo.Filter(lo.Keys(task.segmentData), func(key string, _ int) bool {
+		exist, err := cm.Exist(ctx, storage.BlobChecksumKey(key))
+		return err == nil && exist
+	})))
+
+	// logs are read back via the binlog io of compaction
+	binlogIO := binlogio.NewBinlogIO(cm, conc.NewDefaultPool[any]())
+	for key, value := range task.segmentData {
+		contents, err := binlogIO.Download(ctx, []string{key})
+		s.Require().NoError(err)
+		s.Equal([][]byte{value}, contents)
+
+		// flip a byte in the stored blob
+		stored, err := cm.Read(ctx, key)
+		s.Require().NoError(err)
+		stored[len(stored)/2] ^= 0xff
+		s.Require().NoError(cm.Write(ctx, key, stored))
+		_, err = binlogIO.Download(ctx, []string{key})
+		s.ErrorIs(err, storage.ErrBlobChecksumMismatch)
+	}
 }
 
 func TestSyncTask(t *testing.T) {
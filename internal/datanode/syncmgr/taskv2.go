@@ -17,7 +17,6 @@
 package syncmgr
 
 import (
-	"context"
 	"math"
 	"strconv"
 
@@ -57,11 +56,12 @@ type SyncTaskV2 struct {
 }
 
 func (t *SyncTaskV2) getLogger() *log.MLogger {
-	return log.Ctx(context.Background()).With(
+	return log.Ctx(t.logContext()).With(
 		zap.Int64("collectionID", t.collectionID),
 		zap.Int64("partitionID", t.partitionID),
 		zap.Int64("segmentID", t.segmentID),
 		zap.String("channel", t.channelName),
+		zap.String("origin", string(t.Origin())),
 	)
 }
 
@@ -91,21 +91,25 @@ func (t *SyncTaskV2) Run() error {
 	}
 
 	if err = t.serializeInsertData(); err != nil {
+		err = merr.Combine(ErrSerializeFailed, err)
 		t.handleError(err)
 		return err
 	}
 
 	if err = t.serializeStatsData(); err != nil {
+		err = merr.Combine(ErrSerializeFailed, err)
 		t.handleError(err)
 		return err
 	}
 
 	if err = t.serializeDeleteData(); err != nil {
+		err = merr.Combine(ErrSerializeFailed, err)
 		t.handleError(err)
 		return err
 	}
 
 	if err = t.writeSpace(); err != nil {
+		err = merr.Combine(ErrUploadFailed, err)
 		t.handleError(err)
 		return err
 	}
@@ -128,10 +132,45 @@ func (t *SyncTaskV2) Run() error {
 	return nil
 }
 
+// dryRun runs the serialization of Run on a copy of the task without writing the space.
+func (t *SyncTaskV2) dryRun() error {
+	if t.schema == nil {
+		return merr.WrapErrParameterInvalidMsg("schema of segment %d not provided", t.segmentID)
+	}
+	infos := t.metacache.GetSegmentsBy(metacache.WithSegmentIDs(t.segmentID))
+	if len(infos) == 0 {
+		return merr.WrapErrSegmentNotFound(t.segmentID)
+	}
+
+	dry := *t
+	dry.SyncTask = t.SyncTask.dryRunCopy()
+	dry.segment = infos[0]
+	defer func() {
+		if dry.reader != nil {
+			dry.reader.Release()
+		}
+		if dry.deleteReader != nil {
+			dry.deleteReader.Release()
+		}
+	}()
+
+	if err := dry.serializeInsertData(); err != nil {
+		return err
+	}
+	if err := dry.serializeStatsData(); err != nil {
+		return err
+	}
+	return dry.serializeDeleteData()
+}
+
 func (t *SyncTaskV2) serializeInsertData() error {
 	if t.insertData == nil {
 		return nil
 	}
+	// reject mismatched data before building the record, which panics on it
+	if err := t.insertData.Validate(t.schema); err != nil {
+		return err
+	}
 
 	b := array.NewRecordBuilder(memory.DefaultAllocator, t.arrowSchema)
 	defer b.Release()
@@ -538,6 +577,16 @@ func (t *SyncTaskV2) WithFlush() *SyncTaskV2 {
 	return t
 }
 
+func (t *SyncTaskV2) WithOrigin(origin TaskOrigin) *SyncTaskV2 {
+	t.origin = origin
+	return t
+}
+
+func (t *SyncTaskV2) WithPriority(priority int) *SyncTaskV2 {
+	t.priority = priority
+	return t
+}
+
 func (t *SyncTaskV2) WithDrop() *SyncTaskV2 {
 	t.isDrop = true
 	return t
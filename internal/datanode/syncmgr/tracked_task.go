@@ -0,0 +1,127 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+const (
+	taskPending int32 = iota
+	taskRunning
+	taskCancelled
+)
+
+// trackedTask wraps submitted task with its execution state,
+// pending task could be cancelled before it starts running.
+type trackedTask struct {
+	Task
+	state     *atomic.Int32
+	locked    *atomic.Bool // set once the task holds the segment lock, even if waiting for a worker
+	cancelErr *atomic.Error
+	submitTs  time.Time
+	// seq is the submit sequence of the task, which orders the submissions regardless of the clock
+	seq int64
+	// taskID correlates the log lines of the task
+	taskID string
+	// onLeavePending is invoked once the task starts running or gets cancelled
+	onLeavePending func()
+	// onStart is invoked once the task starts running, not for cancelled tasks
+	onStart func()
+	// cancelCtx cancels the context of the task, so that the running task could abort
+	cancelCtx context.CancelFunc
+
+	// done is closed after the task finishes, err holds the result then.
+	done chan struct{}
+	err  error
+}
+
+func newTrackedTask(task Task, taskID string) *trackedTask {
+	return &trackedTask{
+		Task:      task,
+		taskID:    taskID,
+		state:     atomic.NewInt32(taskPending),
+		locked:    atomic.NewBool(false),
+		cancelErr: atomic.NewError(nil),
+		submitTs:  time.Now(),
+		done:      make(chan struct{}),
+	}
+}
+
+// info returns the TaskInfo of the task tracked with key.
+func (t *trackedTask) info(key string) TaskInfo {
+	info := TaskInfo{
+		Key:       key,
+		TaskID:    t.taskID,
+		SegmentID: t.SegmentID(),
+		Channel:   t.ChannelName(),
+		Origin:    getTaskOrigin(t.Task),
+		SubmitTs:  t.submitTs,
+		Task:      t.Task,
+	}
+	info.CollectionID, info.PartitionID = getTaskPartition(t.Task)
+	return info
+}
+
+// finish records the result of the task and notifies the waiters.
+func (t *trackedTask) finish(err error) {
+	t.err = err
+	close(t.done)
+}
+
+// cancel marks the task cancelled with provided reason,
+// returns false if the task is already running or cancelled.
+func (t *trackedTask) cancel(err error) bool {
+	if t.state.Load() != taskPending {
+		return false
+	}
+	t.cancelErr.Store(err)
+	if !t.state.CompareAndSwap(taskPending, taskCancelled) {
+		return false
+	}
+	t.leavePending()
+	return true
+}
+
+func (t *trackedTask) Run() error {
+	if !t.state.CompareAndSwap(taskPending, taskRunning) {
+		return t.cancelErr.Load()
+	}
+	t.leavePending()
+	if t.onStart != nil {
+		t.onStart()
+	}
+	return t.Task.Run()
+}
+
+func (t *trackedTask) onKeyLocked() {
+	t.locked.Store(true)
+}
+
+// Priority exposes the priority of the wrapped task to the dispatcher.
+func (t *trackedTask) Priority() int {
+	return getTaskPriority(t.Task)
+}
+
+func (t *trackedTask) leavePending() {
+	if t.onLeavePending != nil {
+		t.onLeavePending()
+	}
+}
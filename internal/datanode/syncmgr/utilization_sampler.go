@@ -0,0 +1,100 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus/pkg/util/conc"
+)
+
+const (
+	utilizationSampleInterval = 100 * time.Millisecond
+	// keep samples of the latest minute
+	utilizationSampleSize = 600
+)
+
+// utilizationSampler periodically samples the ratio of busy workers of a pool.
+type utilizationSampler struct {
+	mu      sync.RWMutex
+	samples []float64
+	next    int
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	done     chan struct{}
+}
+
+func newUtilizationSampler() *utilizationSampler {
+	return &utilizationSampler{
+		samples: make([]float64, 0, utilizationSampleSize),
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start samples the utilization of pool in background until stop is called.
+func (s *utilizationSampler) start(pool *conc.Pool[error]) {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(utilizationSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				s.add(float64(pool.Running()) / float64(pool.Cap()))
+			}
+		}
+	}()
+}
+
+// stop stops the background sampling started by start and waits for it to exit,
+// kept samples are still available.
+func (s *utilizationSampler) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.done
+	})
+}
+
+func (s *utilizationSampler) add(sample float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.samples) < utilizationSampleSize {
+		s.samples = append(s.samples, sample)
+		return
+	}
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % utilizationSampleSize
+}
+
+// average returns the average of kept samples, zero if there is no sample yet.
+func (s *utilizationSampler) average() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sample := range s.samples {
+		sum += sample
+	}
+	return sum / float64(len(s.samples))
+}
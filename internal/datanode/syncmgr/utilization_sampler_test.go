@@ -0,0 +1,69 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/pkg/util/conc"
+)
+
+func TestUtilizationSampler(t *testing.T) {
+	sampler := newUtilizationSampler()
+	assert.Equal(t, 0.0, sampler.average())
+
+	sampler.add(0)
+	sampler.add(1)
+	assert.Equal(t, 0.5, sampler.average())
+
+	// old samples are overwritten once window is full
+	for i := 0; i < utilizationSampleSize; i++ {
+		sampler.add(1)
+	}
+	assert.Equal(t, 1.0, sampler.average())
+}
+
+func TestUtilizationSamplerStop(t *testing.T) {
+	pool := conc.NewPool[error](1)
+	defer pool.Release()
+	sampler := newUtilizationSampler()
+	sampler.start(pool)
+	assert.Eventually(t, func() bool {
+		sampler.mu.RLock()
+		defer sampler.mu.RUnlock()
+		return len(sampler.samples) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// stop waits for the sampling goroutine to exit and could be called repeatedly
+	sampler.stop()
+	sampler.stop()
+	select {
+	case <-sampler.done:
+	default:
+		t.Fatal("sampling goroutine not exited")
+	}
+	sampler.mu.RLock()
+	sampled := len(sampler.samples)
+	sampler.mu.RUnlock()
+	time.Sleep(2 * utilizationSampleInterval)
+	sampler.mu.RLock()
+	defer sampler.mu.RUnlock()
+	assert.Equal(t, sampled, len(sampler.samples))
+}
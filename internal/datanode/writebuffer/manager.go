@@ -62,6 +62,7 @@ func (m *bufferManager) Register(channel string, metacache metacache.MetaCache,
 		return err
 	}
 	m.buffers[channel] = buf
+	m.syncMgr.RegisterFlushSource(channel, buf)
 	return nil
 }
 
@@ -152,6 +153,7 @@ func (m *bufferManager) RemoveChannel(channel string) {
 		log.Warn("failed to remove channel, channel not maintained in manager", zap.String("channel", channel))
 		return
 	}
+	m.syncMgr.UnregisterFlushSource(channel)
 
 	buf.Close(false)
 }
@@ -168,6 +170,7 @@ func (m *bufferManager) DropChannel(channel string) {
 		log.Warn("failed to drop channel, channel not maintained in manager", zap.String("channel", channel))
 		return
 	}
+	m.syncMgr.UnregisterFlushSource(channel)
 
 	buf.Close(true)
 }
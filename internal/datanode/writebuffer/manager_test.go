@@ -55,6 +55,8 @@ func (s *ManagerSuite) SetupSuite() {
 
 func (s *ManagerSuite) SetupTest() {
 	s.syncMgr = syncmgr.NewMockSyncManager(s.T())
+	s.syncMgr.EXPECT().RegisterFlushSource(mock.Anything, mock.Anything).Maybe()
+	s.syncMgr.EXPECT().UnregisterFlushSource(mock.Anything).Maybe()
 	s.metacache = metacache.NewMockMetaCache(s.T())
 	s.metacache.EXPECT().Collection().Return(s.collID).Maybe()
 	s.metacache.EXPECT().Schema().Return(s.collSchema).Maybe()
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	msgstream "github.com/milvus-io/milvus/pkg/mq/msgstream"
+
+	time "time"
 )
 
 // MockWriteBuffer is an autogenerated mock type for the WriteBuffer type
@@ -304,6 +306,93 @@ func (_c *MockWriteBuffer_SetFlushTimestamp_Call) RunAndReturn(run func(uint64))
 	return _c
 }
 
+// StaleSegments provides a mock function with given fields: maxAge
+func (_m *MockWriteBuffer) StaleSegments(maxAge time.Duration) []int64 {
+	ret := _m.Called(maxAge)
+
+	var r0 []int64
+	if rf, ok := ret.Get(0).(func(time.Duration) []int64); ok {
+		r0 = rf(maxAge)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	return r0
+}
+
+// MockWriteBuffer_StaleSegments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StaleSegments'
+type MockWriteBuffer_StaleSegments_Call struct {
+	*mock.Call
+}
+
+// StaleSegments is a helper method to define mock.On call
+//   - maxAge time.Duration
+func (_e *MockWriteBuffer_Expecter) StaleSegments(maxAge interface{}) *MockWriteBuffer_StaleSegments_Call {
+	return &MockWriteBuffer_StaleSegments_Call{Call: _e.mock.On("StaleSegments", maxAge)}
+}
+
+func (_c *MockWriteBuffer_StaleSegments_Call) Run(run func(maxAge time.Duration)) *MockWriteBuffer_StaleSegments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockWriteBuffer_StaleSegments_Call) Return(_a0 []int64) *MockWriteBuffer_StaleSegments_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockWriteBuffer_StaleSegments_Call) RunAndReturn(run func(time.Duration) []int64) *MockWriteBuffer_StaleSegments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SyncSegments provides a mock function with given fields: ctx, segmentIDs
+func (_m *MockWriteBuffer) SyncSegments(ctx context.Context, segmentIDs []int64) error {
+	ret := _m.Called(ctx, segmentIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) error); ok {
+		r0 = rf(ctx, segmentIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockWriteBuffer_SyncSegments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncSegments'
+type MockWriteBuffer_SyncSegments_Call struct {
+	*mock.Call
+}
+
+// SyncSegments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - segmentIDs []int64
+func (_e *MockWriteBuffer_Expecter) SyncSegments(ctx interface{}, segmentIDs interface{}) *MockWriteBuffer_SyncSegments_Call {
+	return &MockWriteBuffer_SyncSegments_Call{Call: _e.mock.On("SyncSegments", ctx, segmentIDs)}
+}
+
+func (_c *MockWriteBuffer_SyncSegments_Call) Run(run func(ctx context.Context, segmentIDs []int64)) *MockWriteBuffer_SyncSegments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int64))
+	})
+	return _c
+}
+
+func (_c *MockWriteBuffer_SyncSegments_Call) Return(_a0 error) *MockWriteBuffer_SyncSegments_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockWriteBuffer_SyncSegments_Call) RunAndReturn(run func(context.Context, []int64) error) *MockWriteBuffer_SyncSegments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockWriteBuffer creates a new instance of MockWriteBuffer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockWriteBuffer(t interface {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/samber/lo"
@@ -27,6 +28,7 @@ import (
 	"github.com/milvus-io/milvus/pkg/mq/msgstream"
 	"github.com/milvus-io/milvus/pkg/util/conc"
 	"github.com/milvus-io/milvus/pkg/util/merr"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
 	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
@@ -51,6 +53,10 @@ type WriteBuffer interface {
 	// If there are any non-empty segment buffer, returns the earliest buffer start position.
 	// Otherwise, returns latest buffered checkpoint.
 	GetCheckpoint() *msgpb.MsgPosition
+	// StaleSegments returns the segments whose buffered data is older than maxAge.
+	StaleSegments(maxAge time.Duration) []int64
+	// SyncSegments submits sync tasks for provided segments if they have buffered data.
+	SyncSegments(ctx context.Context, segmentIDs []int64) error
 	// Close is the method to close and sink current buffer data.
 	Close(drop bool)
 }
@@ -196,6 +202,29 @@ func (wb *writeBufferBase) GetCheckpoint() *msgpb.MsgPosition {
 	return checkpoint
 }
 
+func (wb *writeBufferBase) StaleSegments(maxAge time.Duration) []int64 {
+	wb.mut.RLock()
+	defer wb.mut.RUnlock()
+
+	now := time.Now()
+	return lo.FilterMap(lo.Values(wb.buffers), func(buf *segmentBuffer, _ int) (int64, bool) {
+		return buf.segmentID, now.Sub(tsoutil.PhysicalTime(buf.MinTimestamp())) > maxAge
+	})
+}
+
+func (wb *writeBufferBase) SyncSegments(ctx context.Context, segmentIDs []int64) error {
+	wb.mut.Lock()
+	defer wb.mut.Unlock()
+
+	// buffers may be synced by other triggers since selected
+	segmentIDs = lo.Filter(segmentIDs, func(segmentID int64, _ int) bool {
+		_, ok := wb.buffers[segmentID]
+		return ok
+	})
+	wb.syncSegments(ctx, segmentIDs)
+	return nil
+}
+
 func (wb *writeBufferBase) triggerSync() (segmentIDs []int64) {
 	segmentsToSync := wb.getSegmentsToSync(wb.checkpoint.GetTimestamp())
 	if len(segmentsToSync) > 0 {
@@ -3,6 +3,7 @@ package writebuffer
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -16,6 +17,7 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/datapb"
 	"github.com/milvus-io/milvus/pkg/common"
 	"github.com/milvus-io/milvus/pkg/util/paramtable"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
 )
 
 type WriteBufferSuite struct {
@@ -258,6 +260,37 @@ func (s *WriteBufferSuite) TestGetCheckpoint() {
 	})
 }
 
+func (s *WriteBufferSuite) TestStaleSegments() {
+	stale, err := newSegmentBuffer(2, s.collSchema)
+	s.Require().NoError(err)
+	stale.insertBuffer.startPos = &msgpb.MsgPosition{
+		Timestamp: tsoutil.ComposeTSByTime(time.Now().Add(-time.Minute), 0),
+	}
+	fresh, err := newSegmentBuffer(3, s.collSchema)
+	s.Require().NoError(err)
+	fresh.insertBuffer.startPos = &msgpb.MsgPosition{
+		Timestamp: tsoutil.ComposeTSByTime(time.Now(), 0),
+	}
+	empty, err := newSegmentBuffer(4, s.collSchema)
+	s.Require().NoError(err)
+
+	s.wb.mut.Lock()
+	s.wb.buffers[2] = stale
+	s.wb.buffers[3] = fresh
+	s.wb.buffers[4] = empty
+	s.wb.mut.Unlock()
+	defer func() {
+		s.wb.mut.Lock()
+		defer s.wb.mut.Unlock()
+		s.wb.buffers = make(map[int64]*segmentBuffer)
+	}()
+
+	s.ElementsMatch([]int64{2}, s.wb.StaleSegments(10*time.Second))
+
+	// segments without buffer are ignored
+	s.NoError(s.wb.SyncSegments(context.Background(), []int64{5}))
+}
+
 func TestWriteBufferBase(t *testing.T) {
 	suite.Run(t, new(WriteBufferSuite))
 }
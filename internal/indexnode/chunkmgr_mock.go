@@ -3,6 +3,7 @@ package indexnode
 import (
 	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"sync"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/milvus-io/milvus/internal/storage"
 	"github.com/milvus-io/milvus/pkg/common"
 	"github.com/milvus-io/milvus/pkg/mq/msgstream"
+	"github.com/milvus-io/milvus/pkg/util/merr"
 	"github.com/milvus-io/milvus/pkg/util/paramtable"
 )
 
@@ -113,6 +115,11 @@ func (c *mockChunkmgr) MultiWrite(ctx context.Context, contents map[string][]byt
 	return errNotImplErr
 }
 
+func (c *mockChunkmgr) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	// TODO
+	return errNotImplErr
+}
+
 func (c *mockChunkmgr) Exist(ctx context.Context, filePath string) (bool, error) {
 	// TODO
 	return false, errNotImplErr
@@ -121,7 +128,7 @@ func (c *mockChunkmgr) Exist(ctx context.Context, filePath string) (bool, error)
 func (c *mockChunkmgr) Read(ctx context.Context, filePath string) ([]byte, error) {
 	value, ok := c.segmentData.Load(filePath)
 	if !ok {
-		return nil, fmt.Errorf("data not exists")
+		return nil, merr.WrapErrIoKeyNotFound(filePath)
 	}
 	return value.(*storage.Blob).Value, nil
 }
@@ -46,6 +46,7 @@ import (
 	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
 	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/storage"
 	"github.com/milvus-io/milvus/internal/types"
 	"github.com/milvus-io/milvus/internal/util/dependency"
 	"github.com/milvus-io/milvus/internal/util/initcore"
@@ -212,6 +213,8 @@ func (i *IndexNode) Init() error {
 		log.Info("IndexNode init session successful", zap.Int64("serverID", i.session.ServerID))
 
 		i.initSegcore()
+		// verify checksum sidecars only if datanodes write them
+		storage.SetBlobChecksumVerification(paramtable.Get().DataNodeCfg.SyncBlobChecksum.GetAsBool())
 	})
 
 	log.Info("init index node done", zap.Int64("nodeID", paramtable.GetNodeID()), zap.String("Address", i.address))
@@ -355,7 +355,7 @@ func (it *indexBuildTask) LoadData(ctx context.Context) error {
 			}
 			return nil, err
 		}
-		return data, nil
+		return storage.DecodeBlob(ctx, it.cm, path, data)
 	}
 	getBlobByPath := func(path string) (*Blob, error) {
 		value, err := getValueByPath(path)
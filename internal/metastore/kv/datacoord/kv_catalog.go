@@ -513,6 +513,20 @@ func (kc *Catalog) SaveChannelCheckpoint(ctx context.Context, vChannel string, p
 	return kc.MetaKv.Save(k, string(v))
 }
 
+// SaveChannelCheckpoints saves the checkpoints keyed by their channel names in a single transaction.
+func (kc *Catalog) SaveChannelCheckpoints(ctx context.Context, positions []*msgpb.MsgPosition) error {
+	kvs := make(map[string]string, len(positions))
+	for _, pos := range positions {
+		k := buildChannelCPKey(pos.GetChannelName())
+		v, err := proto.Marshal(pos)
+		if err != nil {
+			return err
+		}
+		kvs[k] = string(v)
+	}
+	return kc.MetaKv.MultiSave(kvs)
+}
+
 func (kc *Catalog) DropChannelCheckpoint(ctx context.Context, vChannel string) error {
 	k := buildChannelCPKey(vChannel)
 	return kc.MetaKv.Remove(k)
@@ -584,6 +584,29 @@ func TestChannelCP(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("SaveChannelCheckpoints", func(t *testing.T) {
+		txn := mocks.NewMetaKv(t)
+		txn.EXPECT().MultiSave(mock.Anything).RunAndReturn(func(kvs map[string]string) error {
+			assert.Equal(t, 2, len(kvs))
+			assert.Contains(t, kvs, k)
+			return nil
+		})
+		catalog := NewCatalog(txn, rootPath, "")
+		err := catalog.SaveChannelCheckpoints(context.TODO(), []*msgpb.MsgPosition{
+			{ChannelName: mockVChannel, MsgID: []byte{}, Timestamp: 1000},
+			{ChannelName: mockVChannel + "_1", MsgID: []byte{}, Timestamp: 1000},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("SaveChannelCheckpoints failed", func(t *testing.T) {
+		txn := mocks.NewMetaKv(t)
+		catalog := NewCatalog(txn, rootPath, "")
+		txn.EXPECT().MultiSave(mock.Anything).Return(errors.New("mock error"))
+		err = catalog.SaveChannelCheckpoints(context.TODO(), []*msgpb.MsgPosition{pos})
+		assert.Error(t, err)
+	})
+
 	t.Run("DropChannelCheckpoint", func(t *testing.T) {
 		txn := mocks.NewMetaKv(t)
 		txn.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	io "io"
+
 	mmap "golang.org/x/exp/mmap"
 
 	mock "github.com/stretchr/testify/mock"
@@ -341,6 +343,51 @@ func (_c *ChunkManager_MultiWrite_Call) RunAndReturn(run func(context.Context, m
 	return _c
 }
 
+// MultipartWrite provides a mock function with given fields: ctx, filePath, reader, size
+func (_m *ChunkManager) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	ret := _m.Called(ctx, filePath, reader, size)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader, int64) error); ok {
+		r0 = rf(ctx, filePath, reader, size)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ChunkManager_MultipartWrite_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MultipartWrite'
+type ChunkManager_MultipartWrite_Call struct {
+	*mock.Call
+}
+
+// MultipartWrite is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filePath string
+//   - reader io.Reader
+//   - size int64
+func (_e *ChunkManager_Expecter) MultipartWrite(ctx interface{}, filePath interface{}, reader interface{}, size interface{}) *ChunkManager_MultipartWrite_Call {
+	return &ChunkManager_MultipartWrite_Call{Call: _e.mock.On("MultipartWrite", ctx, filePath, reader, size)}
+}
+
+func (_c *ChunkManager_MultipartWrite_Call) Run(run func(ctx context.Context, filePath string, reader io.Reader, size int64)) *ChunkManager_MultipartWrite_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(io.Reader), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *ChunkManager_MultipartWrite_Call) Return(_a0 error) *ChunkManager_MultipartWrite_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ChunkManager_MultipartWrite_Call) RunAndReturn(run func(context.Context, string, io.Reader, int64) error) *ChunkManager_MultipartWrite_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Path provides a mock function with given fields: ctx, filePath
 func (_m *ChunkManager) Path(ctx context.Context, filePath string) (string, error) {
 	ret := _m.Called(ctx, filePath)
@@ -742,7 +742,7 @@ func (loader *segmentLoader) loadBloomFilter(ctx context.Context, segmentID int6
 	}
 
 	startTs := time.Now()
-	values, err := loader.cm.MultiRead(ctx, binlogPaths)
+	values, err := storage.ReadBlobs(ctx, loader.cm, binlogPaths)
 	if err != nil {
 		return err
 	}
@@ -797,6 +797,10 @@ func (loader *segmentLoader) LoadDeltaLogs(ctx context.Context, segment Segment,
 			if err != nil {
 				return err
 			}
+			value, err = storage.DecodeBlob(ctx, loader.cm, bLog.GetLogPath(), value)
+			if err != nil {
+				return err
+			}
 			blob := &storage.Blob{
 				Key:   bLog.GetLogPath(),
 				Value: value,
@@ -333,6 +333,8 @@ func (node *QueryNode) Init() error {
 		node.unsubscribingChannels = typeutil.NewConcurrentSet[string]()
 		node.manager = segments.NewManager()
 		node.loader = segments.NewLoader(node.manager, node.chunkManager)
+		// verify checksum sidecars only if datanodes write them
+		storage.SetBlobChecksumVerification(paramtable.Get().DataNodeCfg.SyncBlobChecksum.GetAsBool())
 		node.dispClient = msgdispatcher.NewClient(node.factory, typeutil.QueryNodeRole, paramtable.GetNodeID())
 		// init pipeline manager
 		node.pipelineManager = pipeline.NewManager(node.manager, node.tSafeManager, node.dispClient, node.delegators)
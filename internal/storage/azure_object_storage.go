@@ -109,6 +109,13 @@ func (AzureObjectStorage *AzureObjectStorage) PutObject(ctx context.Context, buc
 	return checkObjectStorageError(objectName, err)
 }
 
+func (AzureObjectStorage *AzureObjectStorage) PutObjectInParts(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, partSize int64) error {
+	_, err := AzureObjectStorage.Client.NewContainerClient(bucketName).NewBlockBlobClient(objectName).UploadStream(ctx, reader, &azblob.UploadStreamOptions{
+		BlockSize: partSize,
+	})
+	return checkObjectStorageError(objectName, err)
+}
+
 func (AzureObjectStorage *AzureObjectStorage) StatObject(ctx context.Context, bucketName, objectName string) (int64, error) {
 	info, err := AzureObjectStorage.Client.NewContainerClient(bucketName).NewBlockBlobClient(objectName).GetProperties(ctx, &blob.GetPropertiesOptions{})
 	if err != nil {
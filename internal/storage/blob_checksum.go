@@ -0,0 +1,80 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"hash/crc32"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"go.uber.org/atomic"
+
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// blobChecksumSuffix is the suffix of the sidecar object holding the CRC32C of a blob.
+const blobChecksumSuffix = ".crc32c"
+
+// ErrBlobChecksumMismatch is returned when the content read does not match the checksum written with it.
+var ErrBlobChecksumMismatch = errors.New("blob checksum mismatch")
+
+// verifyBlobChecksum toggles the checksum verification of DecodeBlob.
+var verifyBlobChecksum = atomic.NewBool(false)
+
+// SetBlobChecksumVerification enables or disables verifying the blobs decoded by DecodeBlob
+// against their checksum sidecar, it's disabled by default.
+// Each verified read costs an extra read of the sidecar, so it shall be enabled only if sidecars are written.
+func SetBlobChecksumVerification(enabled bool) {
+	verifyBlobChecksum.Store(enabled)
+}
+
+// BlobChecksumKey returns the object key of the checksum sidecar of the blob with provided key.
+func BlobChecksumKey(key string) string {
+	return key + blobChecksumSuffix
+}
+
+// ParseBlobChecksumKey returns the key of the blob which the checksum sidecar with provided key belongs to,
+// it returns false if key is not a checksum sidecar.
+func ParseBlobChecksumKey(key string) (string, bool) {
+	if !strings.HasSuffix(key, blobChecksumSuffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(key, blobChecksumSuffix), true
+}
+
+// BlobChecksum returns the encoded CRC32C of value, which is stored as the checksum sidecar.
+func BlobChecksum(value []byte) []byte {
+	return common.Endian.AppendUint32(nil, crc32.Checksum(value, castagnoliTable))
+}
+
+// VerifyBlobChecksum compares value with the checksum sidecar of the blob with provided key,
+// blobs written without checksum are not verified.
+func VerifyBlobChecksum(ctx context.Context, cm ChunkManager, key string, value []byte) error {
+	expected, err := cm.Read(ctx, BlobChecksumKey(key))
+	if errors.Is(err, merr.ErrIoKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if actual := BlobChecksum(value); string(actual) != string(expected) {
+		return errors.Wrapf(ErrBlobChecksumMismatch, "blob %s, expected %x, actual %x", key, expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobChecksum(t *testing.T) {
+	SetBlobChecksumVerification(true)
+	defer SetBlobChecksumVerification(false)
+	ctx := context.Background()
+	cm := NewLocalChunkManager(RootPath(t.TempDir()))
+	value := []byte("0123456789abcdefghij")
+
+	key := path.Join(cm.RootPath(), "blob")
+	require.NoError(t, cm.Write(ctx, key, value))
+	require.NoError(t, cm.Write(ctx, BlobChecksumKey(key), BlobChecksum(value)))
+	content, err := DecodeBlob(ctx, cm, key, value)
+	assert.NoError(t, err)
+	assert.Equal(t, value, content)
+
+	// flip a byte of the stored blob
+	corrupted := append([]byte(nil), value...)
+	corrupted[3] ^= 0xff
+	_, err = DecodeBlob(ctx, cm, key, corrupted)
+	assert.ErrorIs(t, err, ErrBlobChecksumMismatch)
+
+	SetBlobChecksumVerification(false)
+	content, err = DecodeBlob(ctx, cm, key, corrupted)
+	SetBlobChecksumVerification(true)
+	assert.NoError(t, err)
+	assert.Equal(t, corrupted, content)
+
+	// blobs without checksum are not verified
+	plain := path.Join(cm.RootPath(), "plain")
+	require.NoError(t, cm.Write(ctx, plain, value))
+	content, err = DecodeBlob(ctx, cm, plain, value)
+	assert.NoError(t, err)
+	assert.Equal(t, value, content)
+}
+
+func TestParseBlobChecksumKey(t *testing.T) {
+	key, ok := ParseBlobChecksumKey(BlobChecksumKey("insert_log/1/2/3/4/5"))
+	assert.True(t, ok)
+	assert.Equal(t, "insert_log/1/2/3/4/5", key)
+
+	_, ok = ParseBlobChecksumKey("insert_log/1/2/3/4/5")
+	assert.False(t, ok)
+}
+
+func TestReadBlobs(t *testing.T) {
+	SetBlobChecksumVerification(true)
+	defer SetBlobChecksumVerification(false)
+	ctx := context.Background()
+	cm := NewLocalChunkManager(RootPath(t.TempDir()))
+	value := []byte("0123456789abcdefghij")
+
+	keys := []string{path.Join(cm.RootPath(), "a"), path.Join(cm.RootPath(), "b")}
+	for _, key := range keys {
+		require.NoError(t, cm.Write(ctx, key, value))
+		require.NoError(t, cm.Write(ctx, BlobChecksumKey(key), BlobChecksum(value)))
+	}
+	values, err := ReadBlobs(ctx, cm, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{value, value}, values)
+
+	corrupted := append([]byte(nil), value...)
+	corrupted[0] ^= 0xff
+	require.NoError(t, cm.Write(ctx, keys[1], corrupted))
+	_, err = ReadBlobs(ctx, cm, keys)
+	assert.ErrorIs(t, err, ErrBlobChecksumMismatch)
+
+	// sidecars are not read if verification is disabled
+	SetBlobChecksumVerification(false)
+	require.NoError(t, cm.Remove(ctx, BlobChecksumKey(keys[1])))
+	values, err = ReadBlobs(ctx, &noSidecarReadCM{ChunkManager: cm, t: t}, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{value, corrupted}, values)
+}
+
+// noSidecarReadCM fails the test on reading any checksum sidecar.
+type noSidecarReadCM struct {
+	ChunkManager
+	t *testing.T
+}
+
+func (cm *noSidecarReadCM) Read(ctx context.Context, filePath string) ([]byte, error) {
+	_, isChecksum := ParseBlobChecksumKey(filePath)
+	assert.False(cm.t, isChecksum, "checksum sidecar %s read", filePath)
+	return cm.ChunkManager.Read(ctx, filePath)
+}
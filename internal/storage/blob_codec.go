@@ -0,0 +1,132 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/milvus-io/milvus/pkg/util/compressor"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// BlobCodec is the compression applied to a blob before it is uploaded,
+// the codec is recorded as the suffix of the object key so that readers could decompress it.
+type BlobCodec string
+
+const (
+	BlobCodecNone BlobCodec = "none"
+	BlobCodecZstd BlobCodec = "zstd"
+	BlobCodecGzip BlobCodec = "gzip"
+)
+
+// blobCodecSuffixes are the object key suffixes of compressed blobs.
+var blobCodecSuffixes = map[BlobCodec]string{
+	BlobCodecZstd: ".zst",
+	BlobCodecGzip: ".gz",
+}
+
+var (
+	zstdOnce         sync.Once
+	zstdCompressor   *compressor.ZstdCompressor
+	zstdDecompressor *compressor.ZstdDecompressor
+	zstdErr          error
+)
+
+// getZstd returns the shared zstd compressor and decompressor, which are safe for concurrent block operations.
+func getZstd() (*compressor.ZstdCompressor, *compressor.ZstdDecompressor, error) {
+	zstdOnce.Do(func() {
+		zstdCompressor, zstdErr = compressor.NewZstdCompressor(nil)
+		if zstdErr != nil {
+			return
+		}
+		zstdDecompressor, zstdErr = compressor.NewZstdDecompressor(nil)
+	})
+	return zstdCompressor, zstdDecompressor, zstdErr
+}
+
+// ParseBlobCodec parses the codec name, empty name means BlobCodecNone.
+func ParseBlobCodec(name string) (BlobCodec, error) {
+	switch codec := BlobCodec(strings.ToLower(name)); codec {
+	case "", BlobCodecNone:
+		return BlobCodecNone, nil
+	case BlobCodecZstd, BlobCodecGzip:
+		return codec, nil
+	default:
+		return "", merr.WrapErrParameterInvalidMsg("unknown blob codec %s", name)
+	}
+}
+
+// CompressBlob compresses value with codec, it returns the object key carrying the codec suffix
+// and the compressed content. The key and value are returned as is for BlobCodecNone.
+func CompressBlob(key string, value []byte, codec BlobCodec) (string, []byte, error) {
+	switch codec {
+	case "", BlobCodecNone:
+		return key, value, nil
+	case BlobCodecZstd:
+		c, _, err := getZstd()
+		if err != nil {
+			return "", nil, err
+		}
+		return key + blobCodecSuffixes[codec], c.CompressBytes(value, nil), nil
+	case BlobCodecGzip:
+		buf := &bytes.Buffer{}
+		w := gzip.NewWriter(buf)
+		if _, err := w.Write(value); err != nil {
+			return "", nil, err
+		}
+		if err := w.Close(); err != nil {
+			return "", nil, err
+		}
+		return key + blobCodecSuffixes[codec], buf.Bytes(), nil
+	default:
+		return "", nil, merr.WrapErrParameterInvalidMsg("unknown blob codec %s", codec)
+	}
+}
+
+// DecompressBlob decompresses the content of the object with provided key according to the codec suffix of key,
+// content of keys without codec suffix is returned as is.
+func DecompressBlob(key string, value []byte) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(key, blobCodecSuffixes[BlobCodecZstd]):
+		_, d, err := getZstd()
+		if err != nil {
+			return nil, err
+		}
+		decompressed, err := d.DecompressBytes(value, nil)
+		if err != nil {
+			return nil, merr.WrapErrIoFailed(key, err)
+		}
+		return decompressed, nil
+	case strings.HasSuffix(key, blobCodecSuffixes[BlobCodecGzip]):
+		r, err := gzip.NewReader(bytes.NewReader(value))
+		if err != nil {
+			return nil, merr.WrapErrIoFailed(key, err)
+		}
+		defer r.Close()
+		decompressed, err := io.ReadAll(r)
+		if err != nil {
+			return nil, merr.WrapErrIoFailed(key, err)
+		}
+		return decompressed, nil
+	default:
+		return value, nil
+	}
+}
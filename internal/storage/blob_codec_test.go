@@ -0,0 +1,82 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+func TestBlobCodec(t *testing.T) {
+	ctx := context.Background()
+	cm := NewLocalChunkManager(RootPath(t.TempDir()))
+	value := bytes.Repeat([]byte("scalar column "), 1024)
+
+	for _, codec := range []BlobCodec{BlobCodecZstd, BlobCodecGzip} {
+		t.Run(string(codec), func(t *testing.T) {
+			key, compressed, err := CompressBlob(path.Join(cm.RootPath(), string(codec)), value, codec)
+			require.NoError(t, err)
+			assert.Less(t, len(compressed), len(value))
+
+			decompressed, err := DecompressBlob(key, compressed)
+			assert.NoError(t, err)
+			assert.Equal(t, value, decompressed)
+
+			require.NoError(t, cm.Write(ctx, key, compressed))
+			contents, err := ReadBlobs(ctx, cm, []string{key})
+			assert.NoError(t, err)
+			assert.Equal(t, [][]byte{value}, contents)
+
+			content, err := DecodeBlob(ctx, cm, key, compressed)
+			assert.NoError(t, err)
+			assert.Equal(t, value, content)
+
+			_, err = DecompressBlob(key, value)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("none", func(t *testing.T) {
+		key, content, err := CompressBlob("key", value, BlobCodecNone)
+		assert.NoError(t, err)
+		assert.Equal(t, "key", key)
+		assert.Equal(t, value, content)
+
+		content, err = DecompressBlob(key, value)
+		assert.NoError(t, err)
+		assert.Equal(t, value, content)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		for name, expected := range map[string]BlobCodec{"": BlobCodecNone, "none": BlobCodecNone, "ZSTD": BlobCodecZstd, "gzip": BlobCodecGzip} {
+			codec, err := ParseBlobCodec(name)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, codec)
+		}
+		_, err := ParseBlobCodec("lz4")
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+		_, _, err = CompressBlob("key", value, "lz4")
+		assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	})
+}
@@ -0,0 +1,49 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+)
+
+// DecodeBlob decodes the content read from the object with provided key into the serialized blob.
+// It's verified against the checksum sidecar if enabled by SetBlobChecksumVerification,
+// then decompressed if it was compressed by CompressBlob.
+func DecodeBlob(ctx context.Context, cm ChunkManager, key string, value []byte) ([]byte, error) {
+	if verifyBlobChecksum.Load() {
+		if err := VerifyBlobChecksum(ctx, cm, key, value); err != nil {
+			return nil, err
+		}
+	}
+	return DecompressBlob(key, value)
+}
+
+// ReadBlobs reads the objects with provided keys and decodes them by DecodeBlob,
+// binlogs written by sync tasks shall be read via it or DecodeBlob.
+func ReadBlobs(ctx context.Context, cm ChunkManager, keys []string) ([][]byte, error) {
+	values, err := cm.MultiRead(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		values[i], err = DecodeBlob(ctx, cm, key, values[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,101 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strconv"
+
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// columnChecksum returns the CRC32C of the rows starting from start of the column.
+// It is computed over the values instead of the encoded payload, so it does not depend on the binlog format.
+func columnChecksum(data FieldData, start int) (uint32, error) {
+	h := crc32.New(castagnoliTable)
+	var err error
+	switch d := data.(type) {
+	case *BoolFieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *Int8FieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *Int16FieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *Int32FieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *Int64FieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *FloatFieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *DoubleFieldData:
+		err = binary.Write(h, common.Endian, d.Data[start:])
+	case *FloatVectorFieldData:
+		err = binary.Write(h, common.Endian, d.Data[start*d.Dim:])
+	case *BinaryVectorFieldData:
+		_, err = h.Write(d.Data[start*d.Dim/8:])
+	case *Float16VectorFieldData:
+		_, err = h.Write(d.Data[start*d.Dim*2:])
+	default:
+		err = writeRowsChecksum(h, data, start)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+// writeRowsChecksum writes variable length rows into h one by one.
+func writeRowsChecksum(h hash.Hash32, data FieldData, start int) error {
+	buf := make([]byte, 0, 64)
+	for row := start; row < data.RowNum(); row++ {
+		value, err := encodeRowValue(buf[:0], data.GetRow(row))
+		if err != nil {
+			return err
+		}
+		buf = value
+		h.Write(value)
+	}
+	return nil
+}
+
+// verifyColumnChecksum checks the rows read from a binlog against the checksum recorded in its descriptor,
+// binlogs written without checksum are not verified.
+func verifyColumnChecksum(descriptor *descriptorEventData, fieldID FieldID, data FieldData, start int) error {
+	stored, ok := descriptor.Extras[checksumKey]
+	if !ok || data == nil {
+		return nil
+	}
+	expected, err := strconv.ParseUint(fmt.Sprint(stored), 10, 32)
+	if err != nil {
+		return merr.WrapErrIoFailedReason("invalid checksum", fmt.Sprintf("field %d, checksum %v", fieldID, stored))
+	}
+	actual, err := columnChecksum(data, start)
+	if err != nil {
+		return err
+	}
+	if uint32(expected) != actual {
+		return merr.WrapErrIoFailedReason("checksum mismatch",
+			fmt.Sprintf("field %d is corrupted, expected checksum %d, actual %d", fieldID, expected, actual))
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+var _ FieldData = (*ContiguousArrayFieldData)(nil)
+
+// ContiguousArrayFieldData is an array column whose elements of all rows live in one slice,
+// row i consists of elements in [offsets[i], offsets[i+1]).
+// Int8, Int16 and Int32 elements are kept as int32 like ScalarField does.
+type ContiguousArrayFieldData struct {
+	ElementType schemapb.DataType
+
+	offsets []int
+	bools   []bool
+	ints    []int32
+	longs   []int64
+	floats  []float32
+	doubles []float64
+	strings []string
+}
+
+// NewContiguousArrayFieldData returns an empty contiguous array column of elementType.
+func NewContiguousArrayFieldData(elementType schemapb.DataType) *ContiguousArrayFieldData {
+	return &ContiguousArrayFieldData{
+		ElementType: elementType,
+		offsets:     []int{0},
+	}
+}
+
+func (data *ContiguousArrayFieldData) clone() *ContiguousArrayFieldData {
+	return &ContiguousArrayFieldData{
+		ElementType: data.ElementType,
+		offsets:     append([]int(nil), data.offsets...),
+		bools:       append([]bool(nil), data.bools...),
+		ints:        append([]int32(nil), data.ints...),
+		longs:       append([]int64(nil), data.longs...),
+		floats:      append([]float32(nil), data.floats...),
+		doubles:     append([]float64(nil), data.doubles...),
+		strings:     append([]string(nil), data.strings...),
+	}
+}
+
+// CompactArrayField replaces the array column of fieldID with a ContiguousArrayFieldData of the same rows.
+// The compacted column is converted back with ToArrayFieldData when serialized by InsertCodec.
+func (i *InsertData) CompactArrayField(fieldID FieldID) error {
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	arrayData, ok := fieldData.(*ArrayFieldData)
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("field %d is not array, type %T", fieldID, fieldData)
+	}
+	compacted := NewContiguousArrayFieldData(arrayData.ElementType)
+	for _, row := range arrayData.Data {
+		if err := compacted.AppendRow(row); err != nil {
+			return err
+		}
+	}
+	i.Data[fieldID] = compacted
+	return nil
+}
+
+// ToArrayFieldData converts the column back to ArrayFieldData.
+func (data *ContiguousArrayFieldData) ToArrayFieldData() *ArrayFieldData {
+	result := &ArrayFieldData{
+		ElementType: data.ElementType,
+		Data:        make([]*schemapb.ScalarField, 0, data.RowNum()),
+	}
+	for i := 0; i < data.RowNum(); i++ {
+		result.Data = append(result.Data, data.GetRow(i).(*schemapb.ScalarField))
+	}
+	return result
+}
+
+func (data *ContiguousArrayFieldData) RowNum() int { return len(data.offsets) - 1 }
+
+// GetRow returns the i-th row as ScalarField, which shares the elements with the column.
+func (data *ContiguousArrayFieldData) GetRow(i int) any {
+	start, end := data.offsets[i], data.offsets[i+1]
+	switch data.ElementType {
+	case schemapb.DataType_Bool:
+		return &schemapb.ScalarField{Data: &schemapb.ScalarField_BoolData{
+			BoolData: &schemapb.BoolArray{Data: data.bools[start:end:end]},
+		}}
+	case schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32:
+		return &schemapb.ScalarField{Data: &schemapb.ScalarField_IntData{
+			IntData: &schemapb.IntArray{Data: data.ints[start:end:end]},
+		}}
+	case schemapb.DataType_Int64:
+		return &schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{
+			LongData: &schemapb.LongArray{Data: data.longs[start:end:end]},
+		}}
+	case schemapb.DataType_Float:
+		return &schemapb.ScalarField{Data: &schemapb.ScalarField_FloatData{
+			FloatData: &schemapb.FloatArray{Data: data.floats[start:end:end]},
+		}}
+	case schemapb.DataType_Double:
+		return &schemapb.ScalarField{Data: &schemapb.ScalarField_DoubleData{
+			DoubleData: &schemapb.DoubleArray{Data: data.doubles[start:end:end]},
+		}}
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		return &schemapb.ScalarField{Data: &schemapb.ScalarField_StringData{
+			StringData: &schemapb.StringArray{Data: data.strings[start:end:end]},
+		}}
+	default:
+		return &schemapb.ScalarField{}
+	}
+}
+
+// Slice returns rows [start, end), the offsets are rebased so the elements are shared with the column.
+func (data *ContiguousArrayFieldData) Slice(start, end int) FieldData {
+	first, last := data.offsets[start], data.offsets[end]
+	offsets := make([]int, 0, end-start+1)
+	for _, offset := range data.offsets[start : end+1] {
+		offsets = append(offsets, offset-first)
+	}
+	return &ContiguousArrayFieldData{
+		ElementType: data.ElementType,
+		offsets:     offsets,
+		bools:       sliceElements(data.bools, first, last),
+		ints:        sliceElements(data.ints, first, last),
+		longs:       sliceElements(data.longs, first, last),
+		floats:      sliceElements(data.floats, first, last),
+		doubles:     sliceElements(data.doubles, first, last),
+		strings:     sliceElements(data.strings, first, last),
+	}
+}
+
+// sliceElements slices the element array in use, arrays of other element kinds are empty.
+func sliceElements[T any](elements []T, start, end int) []T {
+	if len(elements) == 0 {
+		return nil
+	}
+	return elements[start:end:end]
+}
+
+// AppendRow copies the elements of row into the column,
+// the element kind of row shall match the ElementType of the column.
+func (data *ContiguousArrayFieldData) AppendRow(row interface{}) error {
+	v, ok := row.(*schemapb.ScalarField)
+	if !ok {
+		return merr.WrapErrParameterInvalid("*schemapb.ScalarField", row, "Wrong row type")
+	}
+	var length int
+	var matched bool
+	switch data.ElementType {
+	case schemapb.DataType_Bool:
+		if elements, ok := v.GetData().(*schemapb.ScalarField_BoolData); ok {
+			matched = true
+			data.bools = append(data.bools, elements.BoolData.GetData()...)
+			length = len(data.bools)
+		}
+	case schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32:
+		if elements, ok := v.GetData().(*schemapb.ScalarField_IntData); ok {
+			matched = true
+			data.ints = append(data.ints, elements.IntData.GetData()...)
+			length = len(data.ints)
+		}
+	case schemapb.DataType_Int64:
+		if elements, ok := v.GetData().(*schemapb.ScalarField_LongData); ok {
+			matched = true
+			data.longs = append(data.longs, elements.LongData.GetData()...)
+			length = len(data.longs)
+		}
+	case schemapb.DataType_Float:
+		if elements, ok := v.GetData().(*schemapb.ScalarField_FloatData); ok {
+			matched = true
+			data.floats = append(data.floats, elements.FloatData.GetData()...)
+			length = len(data.floats)
+		}
+	case schemapb.DataType_Double:
+		if elements, ok := v.GetData().(*schemapb.ScalarField_DoubleData); ok {
+			matched = true
+			data.doubles = append(data.doubles, elements.DoubleData.GetData()...)
+			length = len(data.doubles)
+		}
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		if elements, ok := v.GetData().(*schemapb.ScalarField_StringData); ok {
+			matched = true
+			data.strings = append(data.strings, elements.StringData.GetData()...)
+			length = len(data.strings)
+		}
+	}
+	if !matched {
+		return merr.WrapErrParameterInvalidMsg("array element type mismatch, expected %s, got %T", data.ElementType.String(), v.GetData())
+	}
+	data.offsets = append(data.offsets, length)
+	return nil
+}
+
+// GetRowSize returns the same size as ArrayFieldData with an identical row.
+func (data *ContiguousArrayFieldData) GetRowSize(i int) int {
+	start, end := data.offsets[i], data.offsets[i+1]
+	switch data.ElementType {
+	case schemapb.DataType_Bool, schemapb.DataType_Int8:
+		return end - start
+	case schemapb.DataType_Int16:
+		return (end - start) * 2
+	case schemapb.DataType_Int32, schemapb.DataType_Float:
+		return (end - start) * 4
+	case schemapb.DataType_Int64, schemapb.DataType_Double:
+		return (end - start) * 8
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		return (&StringFieldData{Data: data.strings[start:end]}).GetMemorySize() + stringArrayOverhead
+	default:
+		return 0
+	}
+}
+
+// GetMemorySize returns the same size as ArrayFieldData with identical rows.
+func (data *ContiguousArrayFieldData) GetMemorySize() int {
+	switch data.ElementType {
+	case schemapb.DataType_Bool:
+		return len(data.bools)
+	case schemapb.DataType_Int8:
+		return len(data.ints)
+	case schemapb.DataType_Int16:
+		return len(data.ints) * 2
+	case schemapb.DataType_Int32, schemapb.DataType_Float:
+		return len(data.ints)*4 + len(data.floats)*4
+	case schemapb.DataType_Int64, schemapb.DataType_Double:
+		return len(data.longs)*8 + len(data.doubles)*8
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		return (&StringFieldData{Data: data.strings}).GetMemorySize() + data.RowNum()*stringArrayOverhead
+	default:
+		return 0
+	}
+}
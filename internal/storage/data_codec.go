@@ -24,11 +24,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
 	"github.com/milvus-io/milvus/internal/proto/etcdpb"
 	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/conc"
 	"github.com/milvus-io/milvus/pkg/util/merr"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
 	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
@@ -49,6 +52,25 @@ const (
 // So splitting index file won't introduce incompatibility with past version.
 const maxLengthPerRowOfIndexFile = 4 * 1024 * 1024
 
+// defaultCompressThreshold is the minimum memory size of a column to be compressed when serialized.
+const defaultCompressThreshold = 1024
+
+// compressThresholds overrides defaultCompressThreshold for specific data types,
+// variable length columns carry more per-column overhead so a larger threshold is used.
+var compressThresholds = map[schemapb.DataType]int{
+	schemapb.DataType_String:  4096,
+	schemapb.DataType_VarChar: 4096,
+	schemapb.DataType_JSON:    4096,
+	schemapb.DataType_Array:   4096,
+}
+
+func getCompressThreshold(dataType schemapb.DataType) int {
+	if threshold, ok := compressThresholds[dataType]; ok {
+		return threshold
+	}
+	return defaultCompressThreshold
+}
+
 type (
 	// UniqueID is type alias of typeutil.UniqueID
 	UniqueID = typeutil.UniqueID
@@ -108,6 +130,11 @@ func (b Blob) GetValue() []byte {
 // ${tenant}/insert_log/${collection_id}/${partition_id}/${segment_id}/${field_id}/${log_idx}
 type InsertCodec struct {
 	Schema *etcdpb.CollectionMeta
+
+	// skipChecksum disables the checksum verification when deserializing
+	skipChecksum bool
+	// now is the clock of the timestamps in binlog headers, time.Now if nil
+	now func() time.Time
 }
 
 // NewInsertCodec creates an InsertCodec
@@ -120,6 +147,13 @@ func NewInsertCodecWithSchema(schema *etcdpb.CollectionMeta) *InsertCodec {
 	return &InsertCodec{Schema: schema}
 }
 
+// WithChecksumVerification toggles whether the column checksums are verified when deserializing, enabled by default.
+// Disable it if the binlogs are trusted and the deserialization is performance critical.
+func (insertCodec *InsertCodec) WithChecksumVerification(enabled bool) *InsertCodec {
+	insertCodec.skipChecksum = !enabled
+	return insertCodec
+}
+
 // Serialize Pk stats log
 func (insertCodec *InsertCodec) SerializePkStats(stats *PrimaryKeyStats, rowNum int64) (*Blob, error) {
 	if stats == nil || stats.BF == nil {
@@ -201,8 +235,14 @@ func (insertCodec *InsertCodec) SerializePkStatsByData(data *InsertData) (*Blob,
 // For each field, it will create a binlog writer, and write an event to the binlog.
 // It returns binlog buffer in the end.
 func (insertCodec *InsertCodec) Serialize(partitionID UniqueID, segmentID UniqueID, data *InsertData) ([]*Blob, error) {
+	return insertCodec.SerializeParallel(partitionID, segmentID, data, 1)
+}
+
+// SerializeParallel is Serialize encoding at most parallelism columns concurrently,
+// the blobs are identical to those of Serialize. Columns are encoded sequentially if parallelism <= 1.
+// Lazily decompressed and compacted columns of data are replaced with the plain ones.
+func (insertCodec *InsertCodec) SerializeParallel(partitionID UniqueID, segmentID UniqueID, data *InsertData, parallelism int) ([]*Blob, error) {
 	blobs := make([]*Blob, 0)
-	var writer *InsertBinlogWriter
 	timeFieldData, ok := data.Data[common.TimeStampField]
 	if !ok {
 		return nil, fmt.Errorf("data doesn't contains timestamp field")
@@ -211,6 +251,9 @@ func (insertCodec *InsertCodec) Serialize(partitionID UniqueID, segmentID Unique
 		return nil, fmt.Errorf("there's no data in InsertData")
 	}
 	rowNum := int64(timeFieldData.RowNum())
+	if err := data.materialize(); err != nil {
+		return nil, err
+	}
 
 	ts := timeFieldData.(*Int64FieldData).Data
 	var startTs, endTs Timestamp
@@ -232,176 +275,225 @@ func (insertCodec *InsertCodec) Serialize(partitionID UniqueID, segmentID Unique
 	}
 	sort.Sort(dataSorter)
 
-	for _, field := range insertCodec.Schema.Schema.Fields {
-		singleData := data.Data[field.FieldID]
+	// all binlogs of one serialization share the header timestamp, no matter in which order the columns are encoded
+	now := time.Now
+	if insertCodec.now != nil {
+		now = insertCodec.now
+	}
+	headerTs := tsoutil.ComposeTS(now().UnixNano()/int64(time.Millisecond), 0)
 
-		// encode fields
-		writer = NewInsertBinlogWriter(field.DataType, insertCodec.Schema.ID, partitionID, segmentID, field.FieldID)
-		var eventWriter *insertEventWriter
-		var err error
-		if typeutil.IsVectorType(field.DataType) {
-			switch field.DataType {
-			case schemapb.DataType_FloatVector:
-				eventWriter, err = writer.NextInsertEventWriter(singleData.(*FloatVectorFieldData).Dim)
-			case schemapb.DataType_BinaryVector:
-				eventWriter, err = writer.NextInsertEventWriter(singleData.(*BinaryVectorFieldData).Dim)
-			case schemapb.DataType_Float16Vector:
-				eventWriter, err = writer.NextInsertEventWriter(singleData.(*Float16VectorFieldData).Dim)
-			default:
-				return nil, fmt.Errorf("undefined data type %d", field.DataType)
+	fields := insertCodec.Schema.Schema.Fields
+	if parallelism <= 1 {
+		for _, field := range fields {
+			blob, err := insertCodec.serializeField(partitionID, segmentID, field, data.Data[field.FieldID], startTs, endTs, headerTs, rowNum)
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			eventWriter, err = writer.NextInsertEventWriter()
+			blobs = append(blobs, blob)
 		}
+		return blobs, nil
+	}
+
+	// columns are encoded independently, blobs are assembled in field order from the futures
+	pool := conc.NewPool[*Blob](parallelism)
+	defer pool.Release()
+	futures := make([]*conc.Future[*Blob], 0, len(fields))
+	for _, field := range fields {
+		field := field
+		futures = append(futures, pool.Submit(func() (*Blob, error) {
+			return insertCodec.serializeField(partitionID, segmentID, field, data.Data[field.FieldID], startTs, endTs, headerTs, rowNum)
+		}))
+	}
+	if err := conc.AwaitAll(futures...); err != nil {
+		return nil, err
+	}
+	for _, future := range futures {
+		blobs = append(blobs, future.Value())
+	}
+	return blobs, nil
+}
+
+// serializeField encodes the data of one field into an insert binlog blob.
+func (insertCodec *InsertCodec) serializeField(partitionID UniqueID, segmentID UniqueID, field *schemapb.FieldSchema, singleData FieldData,
+	startTs Timestamp, endTs Timestamp, headerTs Timestamp, rowNum int64,
+) (*Blob, error) {
+	writer := NewInsertBinlogWriter(field.DataType, insertCodec.Schema.ID, partitionID, segmentID, field.FieldID)
+	writer.descriptorEventHeader.Timestamp = headerTs
+	var eventWriter *insertEventWriter
+	var err error
+	if typeutil.IsVectorType(field.DataType) {
+		switch field.DataType {
+		case schemapb.DataType_FloatVector:
+			eventWriter, err = writer.NextInsertEventWriter(singleData.(*FloatVectorFieldData).Dim)
+		case schemapb.DataType_BinaryVector:
+			eventWriter, err = writer.NextInsertEventWriter(singleData.(*BinaryVectorFieldData).Dim)
+		case schemapb.DataType_Float16Vector:
+			eventWriter, err = writer.NextInsertEventWriter(singleData.(*Float16VectorFieldData).Dim)
+		default:
+			return nil, fmt.Errorf("undefined data type %d", field.DataType)
+		}
+	} else {
+		eventWriter, err = writer.NextInsertEventWriter()
+	}
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	eventWriter.eventHeader.Timestamp = headerTs
+	eventWriter.SetEventTimestamp(startTs, endTs)
+	// small columns are stored raw since the compression overhead exceeds the savings
+	compressed := singleData.GetMemorySize() >= getCompressThreshold(field.DataType)
+	if payloadWriter, ok := eventWriter.PayloadWriterInterface.(*NativePayloadWriter); ok && !compressed {
+		payloadWriter.disableCompression()
+	}
+	writer.AddExtra(compressedKey, strconv.FormatBool(compressed))
+	checksum, err := columnChecksum(singleData, 0)
+	if err != nil {
+		eventWriter.Close()
+		writer.Close()
+		return nil, err
+	}
+	writer.AddExtra(checksumKey, strconv.FormatUint(uint64(checksum), 10))
+	switch field.DataType {
+	case schemapb.DataType_Bool:
+		err = eventWriter.AddBoolToPayload(singleData.(*BoolFieldData).Data)
 		if err != nil {
+			eventWriter.Close()
 			writer.Close()
 			return nil, err
 		}
-
-		eventWriter.SetEventTimestamp(startTs, endTs)
-		switch field.DataType {
-		case schemapb.DataType_Bool:
-			err = eventWriter.AddBoolToPayload(singleData.(*BoolFieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*BoolFieldData).GetMemorySize()))
-		case schemapb.DataType_Int8:
-			err = eventWriter.AddInt8ToPayload(singleData.(*Int8FieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int8FieldData).GetMemorySize()))
-		case schemapb.DataType_Int16:
-			err = eventWriter.AddInt16ToPayload(singleData.(*Int16FieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int16FieldData).GetMemorySize()))
-		case schemapb.DataType_Int32:
-			err = eventWriter.AddInt32ToPayload(singleData.(*Int32FieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int32FieldData).GetMemorySize()))
-		case schemapb.DataType_Int64:
-			err = eventWriter.AddInt64ToPayload(singleData.(*Int64FieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int64FieldData).GetMemorySize()))
-		case schemapb.DataType_Float:
-			err = eventWriter.AddFloatToPayload(singleData.(*FloatFieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*FloatFieldData).GetMemorySize()))
-		case schemapb.DataType_Double:
-			err = eventWriter.AddDoubleToPayload(singleData.(*DoubleFieldData).Data)
-			if err != nil {
-				eventWriter.Close()
-				writer.Close()
-				return nil, err
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*DoubleFieldData).GetMemorySize()))
-		case schemapb.DataType_String, schemapb.DataType_VarChar:
-			for _, singleString := range singleData.(*StringFieldData).Data {
-				err = eventWriter.AddOneStringToPayload(singleString)
-				if err != nil {
-					eventWriter.Close()
-					writer.Close()
-					return nil, err
-				}
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*StringFieldData).GetMemorySize()))
-		case schemapb.DataType_Array:
-			for _, singleArray := range singleData.(*ArrayFieldData).Data {
-				err = eventWriter.AddOneArrayToPayload(singleArray)
-				if err != nil {
-					eventWriter.Close()
-					writer.Close()
-					return nil, err
-				}
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*ArrayFieldData).GetMemorySize()))
-		case schemapb.DataType_JSON:
-			for _, singleJSON := range singleData.(*JSONFieldData).Data {
-				err = eventWriter.AddOneJSONToPayload(singleJSON)
-				if err != nil {
-					eventWriter.Close()
-					writer.Close()
-					return nil, err
-				}
-			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*JSONFieldData).GetMemorySize()))
-		case schemapb.DataType_BinaryVector:
-			err = eventWriter.AddBinaryVectorToPayload(singleData.(*BinaryVectorFieldData).Data, singleData.(*BinaryVectorFieldData).Dim)
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*BoolFieldData).GetMemorySize()))
+	case schemapb.DataType_Int8:
+		err = eventWriter.AddInt8ToPayload(singleData.(*Int8FieldData).Data)
+		if err != nil {
+			eventWriter.Close()
+			writer.Close()
+			return nil, err
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int8FieldData).GetMemorySize()))
+	case schemapb.DataType_Int16:
+		err = eventWriter.AddInt16ToPayload(singleData.(*Int16FieldData).Data)
+		if err != nil {
+			eventWriter.Close()
+			writer.Close()
+			return nil, err
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int16FieldData).GetMemorySize()))
+	case schemapb.DataType_Int32:
+		err = eventWriter.AddInt32ToPayload(singleData.(*Int32FieldData).Data)
+		if err != nil {
+			eventWriter.Close()
+			writer.Close()
+			return nil, err
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int32FieldData).GetMemorySize()))
+	case schemapb.DataType_Int64:
+		err = eventWriter.AddInt64ToPayload(singleData.(*Int64FieldData).Data)
+		if err != nil {
+			eventWriter.Close()
+			writer.Close()
+			return nil, err
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Int64FieldData).GetMemorySize()))
+	case schemapb.DataType_Float:
+		err = eventWriter.AddFloatToPayload(singleData.(*FloatFieldData).Data)
+		if err != nil {
+			eventWriter.Close()
+			writer.Close()
+			return nil, err
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*FloatFieldData).GetMemorySize()))
+	case schemapb.DataType_Double:
+		err = eventWriter.AddDoubleToPayload(singleData.(*DoubleFieldData).Data)
+		if err != nil {
+			eventWriter.Close()
+			writer.Close()
+			return nil, err
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*DoubleFieldData).GetMemorySize()))
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		for _, singleString := range singleData.(*StringFieldData).Data {
+			err = eventWriter.AddOneStringToPayload(singleString)
 			if err != nil {
 				eventWriter.Close()
 				writer.Close()
 				return nil, err
 			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*BinaryVectorFieldData).GetMemorySize()))
-		case schemapb.DataType_FloatVector:
-			err = eventWriter.AddFloatVectorToPayload(singleData.(*FloatVectorFieldData).Data, singleData.(*FloatVectorFieldData).Dim)
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*StringFieldData).GetMemorySize()))
+	case schemapb.DataType_Array:
+		for _, singleArray := range singleData.(*ArrayFieldData).Data {
+			err = eventWriter.AddOneArrayToPayload(singleArray)
 			if err != nil {
 				eventWriter.Close()
 				writer.Close()
 				return nil, err
 			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*FloatVectorFieldData).GetMemorySize()))
-		case schemapb.DataType_Float16Vector:
-			err = eventWriter.AddFloat16VectorToPayload(singleData.(*Float16VectorFieldData).Data, singleData.(*Float16VectorFieldData).Dim)
+		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*ArrayFieldData).GetMemorySize()))
+	case schemapb.DataType_JSON:
+		for _, singleJSON := range singleData.(*JSONFieldData).Data {
+			err = eventWriter.AddOneJSONToPayload(singleJSON)
 			if err != nil {
 				eventWriter.Close()
 				writer.Close()
 				return nil, err
 			}
-			writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Float16VectorFieldData).GetMemorySize()))
-		default:
-			return nil, fmt.Errorf("undefined data type %d", field.DataType)
 		}
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*JSONFieldData).GetMemorySize()))
+	case schemapb.DataType_BinaryVector:
+		err = eventWriter.AddBinaryVectorToPayload(singleData.(*BinaryVectorFieldData).Data, singleData.(*BinaryVectorFieldData).Dim)
 		if err != nil {
+			eventWriter.Close()
+			writer.Close()
 			return nil, err
 		}
-		writer.SetEventTimeStamp(startTs, endTs)
-
-		err = writer.Finish()
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*BinaryVectorFieldData).GetMemorySize()))
+	case schemapb.DataType_FloatVector:
+		err = eventWriter.AddFloatVectorToPayload(singleData.(*FloatVectorFieldData).Data, singleData.(*FloatVectorFieldData).Dim)
 		if err != nil {
 			eventWriter.Close()
 			writer.Close()
 			return nil, err
 		}
-
-		buffer, err := writer.GetBuffer()
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*FloatVectorFieldData).GetMemorySize()))
+	case schemapb.DataType_Float16Vector:
+		err = eventWriter.AddFloat16VectorToPayload(singleData.(*Float16VectorFieldData).Data, singleData.(*Float16VectorFieldData).Dim)
 		if err != nil {
 			eventWriter.Close()
 			writer.Close()
 			return nil, err
 		}
-		blobKey := fmt.Sprintf("%d", field.FieldID)
-		blobs = append(blobs, &Blob{
-			Key:    blobKey,
-			Value:  buffer,
-			RowNum: rowNum,
-		})
+		writer.AddExtra(originalSizeKey, fmt.Sprintf("%v", singleData.(*Float16VectorFieldData).GetMemorySize()))
+	default:
+		return nil, fmt.Errorf("undefined data type %d", field.DataType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	writer.SetEventTimeStamp(startTs, endTs)
+
+	err = writer.Finish()
+	if err != nil {
 		eventWriter.Close()
 		writer.Close()
+		return nil, err
 	}
 
-	return blobs, nil
+	buffer, err := writer.GetBuffer()
+	if err != nil {
+		eventWriter.Close()
+		writer.Close()
+		return nil, err
+	}
+	eventWriter.Close()
+	writer.Close()
+	return &Blob{
+		Key:    fmt.Sprintf("%d", field.FieldID),
+		Value:  buffer,
+		RowNum: rowNum,
+	}, nil
 }
 
 func (insertCodec *InsertCodec) DeserializeAll(blobs []*Blob) (
@@ -447,6 +539,11 @@ func (insertCodec *InsertCodec) DeserializeInto(fieldBinlogs []*Blob, rowNum int
 		fieldID := binlogReader.FieldID
 		totalLength := 0
 		dim := 0
+		// rows before this binlog, which are not covered by its checksum
+		start := 0
+		if fieldData, ok := insertData.Data[fieldID]; ok {
+			start = fieldData.RowNum()
+		}
 
 		for {
 			eventReader, err := binlogReader.NextEventReader()
@@ -736,6 +833,13 @@ func (insertCodec *InsertCodec) DeserializeInto(fieldBinlogs []*Blob, rowNum int
 			eventReader.Close()
 		}
 
+		if !insertCodec.skipChecksum {
+			if err := verifyColumnChecksum(&binlogReader.descriptorEventData, fieldID, insertData.Data[fieldID], start); err != nil {
+				binlogReader.Close()
+				return InvalidUniqueID, InvalidUniqueID, InvalidUniqueID, err
+			}
+		}
+
 		if rowNum <= 0 {
 			rowNum = totalLength
 		}
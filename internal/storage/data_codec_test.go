@@ -17,9 +17,11 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -29,6 +31,7 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/etcdpb"
 	"github.com/milvus-io/milvus/pkg/common"
 	"github.com/milvus-io/milvus/pkg/log"
+	"github.com/milvus-io/milvus/pkg/util/merr"
 )
 
 const (
@@ -178,6 +181,47 @@ func genTestCollectionMeta() *etcdpb.CollectionMeta {
 	}
 }
 
+func TestInsertCodecChecksum(t *testing.T) {
+	schema := &etcdpb.CollectionMeta{
+		ID: CollectionID,
+		Schema: &schemapb.CollectionSchema{
+			Fields: []*schemapb.FieldSchema{
+				{FieldID: RowIDField, Name: "row_id", DataType: schemapb.DataType_Int64},
+				{FieldID: TimestampField, Name: "Timestamp", DataType: schemapb.DataType_Int64},
+				{FieldID: StringField, Name: "field_varchar", DataType: schemapb.DataType_VarChar},
+			},
+		},
+	}
+	insertCodec := NewInsertCodecWithSchema(schema)
+	data := &InsertData{Data: map[FieldID]FieldData{
+		RowIDField:     &Int64FieldData{Data: []int64{1, 2}},
+		TimestampField: &Int64FieldData{Data: []int64{1, 2}},
+		StringField:    &StringFieldData{Data: []string{"checksum-value-1", "checksum-value-2"}},
+	}}
+	blobs, err := insertCodec.Serialize(PartitionID, SegmentID, data)
+	assert.NoError(t, err)
+
+	_, _, _, err = insertCodec.Deserialize(blobs)
+	assert.NoError(t, err)
+
+	// corrupt one byte of the raw stored string
+	for _, blob := range blobs {
+		if blob.Key == fmt.Sprint(StringField) {
+			idx := bytes.Index(blob.Value, []byte("checksum-value-1"))
+			assert.Greater(t, idx, 0)
+			blob.Value[idx] = 'C'
+		}
+	}
+	_, _, _, err = insertCodec.Deserialize(blobs)
+	assert.ErrorIs(t, err, merr.ErrIoFailed)
+	assert.ErrorContains(t, err, fmt.Sprintf("field %d is corrupted", StringField))
+
+	// corruption is not detected if verification is disabled
+	_, _, result, err := insertCodec.WithChecksumVerification(false).Deserialize(blobs)
+	assert.NoError(t, err)
+	assert.Equal(t, "Checksum-value-1", result.Data[StringField].GetRow(0))
+}
+
 func TestInsertCodec(t *testing.T) {
 	schema := genTestCollectionMeta()
 	insertCodec := NewInsertCodecWithSchema(schema)
@@ -331,9 +375,9 @@ func TestInsertCodec(t *testing.T) {
 			FloatField:         &FloatFieldData{[]float32{}},
 			DoubleField:        &DoubleFieldData{[]float64{}},
 			StringField:        &StringFieldData{[]string{}},
-			BinaryVectorField:  &BinaryVectorFieldData{[]byte{}, 8},
-			FloatVectorField:   &FloatVectorFieldData{[]float32{}, 4},
-			Float16VectorField: &Float16VectorFieldData{[]byte{}, 4},
+			BinaryVectorField:  &BinaryVectorFieldData{Data: []byte{}, Dim: 8},
+			FloatVectorField:   &FloatVectorFieldData{Data: []float32{}, Dim: 4},
+			Float16VectorField: &Float16VectorFieldData{Data: []byte{}, Dim: 4},
 			ArrayField:         &ArrayFieldData{schemapb.DataType_Int32, []*schemapb.ScalarField{}},
 			JSONField:          &JSONFieldData{[][]byte{}},
 		},
@@ -422,6 +466,149 @@ func TestInsertCodec(t *testing.T) {
 	assert.Error(t, err, "SerializePkStatsList zero length pkstats list shall return error")
 }
 
+func TestInsertCodecCompressThreshold(t *testing.T) {
+	schema := &etcdpb.CollectionMeta{
+		ID: CollectionID,
+		Schema: &schemapb.CollectionSchema{
+			Fields: []*schemapb.FieldSchema{
+				{FieldID: RowIDField, Name: "row_id", DataType: schemapb.DataType_Int64},
+				{FieldID: TimestampField, Name: "Timestamp", DataType: schemapb.DataType_Int64},
+				{FieldID: StringField, Name: "field_varchar", DataType: schemapb.DataType_VarChar},
+			},
+		},
+	}
+	insertCodec := NewInsertCodecWithSchema(schema)
+
+	serialize := func(values []string) (*BinlogReader, int) {
+		rowIDs := make([]int64, 0, len(values))
+		for i := range values {
+			rowIDs = append(rowIDs, int64(i+1))
+		}
+		data := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:     &Int64FieldData{Data: rowIDs},
+			TimestampField: &Int64FieldData{Data: rowIDs},
+			StringField:    &StringFieldData{Data: values},
+		}}
+		blobs, err := insertCodec.Serialize(PartitionID, SegmentID, data)
+		assert.NoError(t, err)
+		for _, blob := range blobs {
+			if blob.Key == fmt.Sprint(StringField) {
+				reader, err := NewBinlogReader(blob.Value)
+				assert.NoError(t, err)
+				return reader, len(blob.Value)
+			}
+		}
+		t.FailNow()
+		return nil, 0
+	}
+
+	// tiny column is stored raw
+	reader, _ := serialize([]string{"uncompressed-value-1", "uncompressed-value-2"})
+	assert.False(t, reader.IsCompressed())
+	assert.Contains(t, string(reader.buffer.Bytes()), "uncompressed-value-1")
+	reader.Close()
+
+	values := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		values = append(values, "compressed-value")
+	}
+	reader, size := serialize(values)
+	assert.True(t, reader.IsCompressed())
+	assert.Less(t, size, (&StringFieldData{Data: values}).GetMemorySize()/5)
+	reader.Close()
+}
+
+// genWideInsertData generates a schema of fieldNum user fields of mixed types and rowNum rows of data for it.
+func genWideInsertData(fieldNum, rowNum int) (*etcdpb.CollectionMeta, *InsertData) {
+	schema := &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: RowIDField, Name: "row_id", DataType: schemapb.DataType_Int64},
+			{FieldID: TimestampField, Name: "Timestamp", DataType: schemapb.DataType_Int64},
+		},
+	}
+	rowIDs := make([]int64, 0, rowNum)
+	for i := 0; i < rowNum; i++ {
+		// unordered to cover the sorting before serializing
+		rowIDs = append(rowIDs, int64(rowNum-i))
+	}
+	data := &InsertData{Data: map[FieldID]FieldData{
+		RowIDField:     &Int64FieldData{Data: rowIDs},
+		TimestampField: &Int64FieldData{Data: append([]int64(nil), rowIDs...)},
+	}}
+	for i := 0; i < fieldNum; i++ {
+		fieldID := FieldID(common.StartOfUserFieldID + i)
+		field := &schemapb.FieldSchema{FieldID: fieldID, Name: fmt.Sprintf("field_%d", i)}
+		switch i % 4 {
+		case 0:
+			field.DataType = schemapb.DataType_Int64
+			data.Data[fieldID] = &Int64FieldData{Data: generateInt64Array(rowNum)}
+		case 1:
+			field.DataType = schemapb.DataType_VarChar
+			values := make([]string, 0, rowNum)
+			for row := 0; row < rowNum; row++ {
+				values = append(values, fmt.Sprintf("value-%d-%d", i, row))
+			}
+			data.Data[fieldID] = &StringFieldData{Data: values}
+		case 2:
+			field.DataType = schemapb.DataType_FloatVector
+			field.TypeParams = []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "8"}}
+			data.Data[fieldID] = &FloatVectorFieldData{Data: generateFloatVectors(rowNum, 8), Dim: 8}
+		case 3:
+			field.DataType = schemapb.DataType_JSON
+			values := make([][]byte, 0, rowNum)
+			for row := 0; row < rowNum; row++ {
+				values = append(values, []byte(fmt.Sprintf(`{"field":%d,"row":%d}`, i, row)))
+			}
+			data.Data[fieldID] = &JSONFieldData{Data: values}
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	return &etcdpb.CollectionMeta{ID: CollectionID, Schema: schema}, data
+}
+
+func TestInsertCodecSerializeParallel(t *testing.T) {
+	schema, data := genWideInsertData(32, 100)
+	insertCodec := NewInsertCodecWithSchema(schema)
+	// pin the clock of binlog headers, whose timestamps differ otherwise between serializations
+	now := time.Now()
+	insertCodec.now = func() time.Time { return now }
+
+	expected, err := insertCodec.Serialize(PartitionID, SegmentID, data.Clone())
+	assert.NoError(t, err)
+	assert.Len(t, expected, len(schema.GetSchema().GetFields()))
+	for _, parallelism := range []int{0, 1, 4, 64} {
+		blobs, err := insertCodec.SerializeParallel(PartitionID, SegmentID, data.Clone(), parallelism)
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected), len(blobs), "parallelism %d", parallelism)
+		for i, blob := range blobs {
+			assert.Equal(t, expected[i].Key, blob.Key, "parallelism %d", parallelism)
+			assert.Equal(t, expected[i].RowNum, blob.RowNum, "parallelism %d", parallelism)
+			assert.True(t, bytes.Equal(expected[i].Value, blob.Value), "parallelism %d, blob %s", parallelism, blob.Key)
+		}
+	}
+
+	_, _, result, err := insertCodec.Deserialize(expected)
+	assert.NoError(t, err)
+	assert.Equal(t, data.GetRowNum(), result.GetRowNum())
+
+	_, err = insertCodec.SerializeParallel(PartitionID, SegmentID, &InsertData{Data: map[FieldID]FieldData{}}, 4)
+	assert.Error(t, err)
+}
+
+func BenchmarkInsertCodecSerializeParallel(b *testing.B) {
+	schema, data := genWideInsertData(1000, 1000)
+	insertCodec := NewInsertCodecWithSchema(schema)
+	for _, parallelism := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("parallelism_%d", parallelism), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := insertCodec.SerializeParallel(PartitionID, SegmentID, data, parallelism); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestDeleteCodec(t *testing.T) {
 	t.Run("int64 pk", func(t *testing.T) {
 		deleteCodec := NewDeleteCodec()
@@ -707,8 +894,8 @@ func TestMemorySize(t *testing.T) {
 			FloatField:        &FloatFieldData{[]float32{}},
 			DoubleField:       &DoubleFieldData{[]float64{}},
 			StringField:       &StringFieldData{[]string{}},
-			BinaryVectorField: &BinaryVectorFieldData{[]byte{}, 8},
-			FloatVectorField:  &FloatVectorFieldData{[]float32{}, 4},
+			BinaryVectorField: &BinaryVectorFieldData{Data: []byte{}, Dim: 8},
+			FloatVectorField:  &FloatVectorFieldData{Data: []float32{}, Dim: 4},
 		},
 	}
 
@@ -30,7 +30,13 @@ import (
 	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
-const originalSizeKey = "original_size"
+const (
+	originalSizeKey = "original_size"
+	// compressedKey records whether the payload is compressed, payload without it is compressed.
+	compressedKey = "compressed"
+	// checksumKey records the CRC32C of the column values in the binlog.
+	checksumKey = "checksum"
+)
 
 type descriptorEventData struct {
 	DescriptorEventDataFixPart
@@ -72,6 +78,12 @@ func (data *descriptorEventData) AddExtra(k string, v interface{}) {
 	data.Extras[k] = v
 }
 
+// IsCompressed returns whether the payload is compressed.
+func (data *descriptorEventData) IsCompressed() bool {
+	compressed, ok := data.Extras[compressedKey]
+	return !ok || compressed != "false"
+}
+
 // FinishExtra marshal extras to json format.
 // Call before GetMemoryUsageInBytes to get an accurate length of description event.
 func (data *descriptorEventData) FinishExtra() error {
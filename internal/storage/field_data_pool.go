@@ -0,0 +1,176 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"math/bits"
+	"sync"
+	"unsafe"
+)
+
+const (
+	// arrays are pooled in power of two capacity classes within [minPooledCap, maxPooledCap] elements
+	minPooledCapClass = 8
+	maxPooledCapClass = 24
+)
+
+// fixedWidth is the element type of the backing arrays which could be pooled.
+type fixedWidth interface {
+	~bool | ~int8 | ~uint8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// arrayPool is a size-classed pool of fixed-width backing arrays keyed by element size,
+// so that types with the same element size (e.g. int64 and double) share the arrays.
+// Arrays are kept as []uint64 to guarantee alignment for every element type.
+type arrayPool struct {
+	classes map[uintptr][]*sync.Pool // element size => pools indexed by capacity class
+}
+
+func newArrayPool() *arrayPool {
+	p := &arrayPool{classes: make(map[uintptr][]*sync.Pool)}
+	for _, elemSize := range []uintptr{1, 2, 4, 8} {
+		pools := make([]*sync.Pool, maxPooledCapClass+1)
+		for class := minPooledCapClass; class <= maxPooledCapClass; class++ {
+			words := (uintptr(1)<<class*elemSize + 7) / 8
+			pools[class] = &sync.Pool{New: func() any {
+				arr := make([]uint64, words)
+				return &arr
+			}}
+		}
+		p.classes[elemSize] = pools
+	}
+	return p
+}
+
+// fieldArrayPool is shared by the InsertData of all collections.
+var fieldArrayPool = newArrayPool()
+
+// capClass returns the smallest class holding capacity elements, false if it is beyond the pooled classes.
+func capClass(capacity int) (int, bool) {
+	class := bits.Len(uint(capacity - 1))
+	if class < minPooledCapClass {
+		class = minPooledCapClass
+	}
+	return class, capacity > 0 && class <= maxPooledCapClass
+}
+
+// getPooledArray returns an empty slice with at least capacity elements,
+// which is taken from the pool if capacity is in the pooled classes.
+func getPooledArray[T fixedWidth](p *arrayPool, capacity int) []T {
+	class, ok := capClass(capacity)
+	if !ok {
+		return make([]T, 0, capacity)
+	}
+	var zero T
+	arr := p.classes[unsafe.Sizeof(zero)][class].Get().(*[]uint64)
+	return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(*arr))), 1<<class)[:0]
+}
+
+// putPooledArray returns the backing array of s to the pool,
+// arrays not taken from the pool are dropped. s shall not be used afterwards.
+func putPooledArray[T fixedWidth](p *arrayPool, s []T) {
+	class, ok := capClass(cap(s))
+	if !ok || cap(s) != 1<<class {
+		return
+	}
+	var zero T
+	elemSize := unsafe.Sizeof(zero)
+	ptr := unsafe.Pointer(unsafe.SliceData(s))
+	if uintptr(ptr)%8 != 0 {
+		return
+	}
+	arr := unsafe.Slice((*uint64)(ptr), (uintptr(cap(s))*elemSize+7)/8)
+	p.classes[elemSize][class].Put(&arr)
+}
+
+// acquirePooledArrays replaces the empty fixed-width fields of data with pooled arrays holding rowNum rows.
+func acquirePooledArrays(data FieldData, rowNum int) {
+	switch d := data.(type) {
+	case *BoolFieldData:
+		d.Data = getPooledArray[bool](fieldArrayPool, rowNum)
+	case *Int8FieldData:
+		d.Data = getPooledArray[int8](fieldArrayPool, rowNum)
+	case *Int16FieldData:
+		d.Data = getPooledArray[int16](fieldArrayPool, rowNum)
+	case *Int32FieldData:
+		d.Data = getPooledArray[int32](fieldArrayPool, rowNum)
+	case *Int64FieldData:
+		d.Data = getPooledArray[int64](fieldArrayPool, rowNum)
+	case *FloatFieldData:
+		d.Data = getPooledArray[float32](fieldArrayPool, rowNum)
+	case *DoubleFieldData:
+		d.Data = getPooledArray[float64](fieldArrayPool, rowNum)
+	case *FloatVectorFieldData:
+		d.Data = getPooledArray[float32](fieldArrayPool, rowNum*d.Dim)
+	case *BinaryVectorFieldData:
+		d.Data = getPooledArray[byte](fieldArrayPool, rowNum*d.Dim/8)
+	case *Float16VectorFieldData:
+		d.Data = getPooledArray[byte](fieldArrayPool, rowNum*d.Dim*2)
+	}
+}
+
+// releasePooledArrays returns the fixed-width backing array of data to the pool and leaves the field empty.
+func releasePooledArrays(data FieldData) {
+	switch d := data.(type) {
+	case *BoolFieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *Int8FieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *Int16FieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *Int32FieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *Int64FieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *FloatFieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *DoubleFieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *FloatVectorFieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *BinaryVectorFieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	case *Float16VectorFieldData:
+		putPooledArray(fieldArrayPool, d.Data)
+		d.Data = nil
+	}
+}
+
+// Release returns the fixed-width backing arrays to the pool shared by all collections and leaves the fields empty.
+// Rows or columns obtained before shall not be used afterwards since the arrays could be reused by others.
+func (i *InsertData) Release() {
+	for _, fieldData := range i.Data {
+		releasePooledArrays(fieldData)
+	}
+}
+
+// Reset releases the backing arrays like Release, then takes pooled arrays holding rowNum rows for refilling.
+func (i *InsertData) Reset(rowNum int) {
+	for _, fieldData := range i.Data {
+		releasePooledArrays(fieldData)
+		acquirePooledArrays(fieldData, rowNum)
+	}
+}
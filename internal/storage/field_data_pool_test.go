@@ -0,0 +1,123 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/common"
+)
+
+func genPoolTestSchema(dim int) *schemapb.CollectionSchema {
+	return &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: RowIDField, DataType: schemapb.DataType_Int64},
+			{FieldID: TimestampField, DataType: schemapb.DataType_Int64},
+			{FieldID: DoubleField, DataType: schemapb.DataType_Double},
+			{FieldID: BoolField, DataType: schemapb.DataType_Bool},
+			{FieldID: FloatVectorField, DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{
+				{Key: common.DimKey, Value: fmt.Sprint(dim)},
+			}},
+		},
+	}
+}
+
+func appendPoolTestRows(t testing.TB, data *InsertData, rowNum int, base int64, dim int) {
+	vector := make([]float32, dim)
+	for i := 0; i < rowNum; i++ {
+		for j := range vector {
+			vector[j] = float32(base)
+		}
+		err := data.Append(map[FieldID]interface{}{
+			RowIDField:       base + int64(i),
+			TimestampField:   base,
+			DoubleField:      float64(base),
+			BoolField:        base%2 == 0,
+			FloatVectorField: vector,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestPooledArrays(t *testing.T) {
+	// the first collection releases its arrays, the second one reuses them
+	first, err := NewInsertData(genPoolTestSchema(4), WithPooledArrays(300))
+	require.NoError(t, err)
+	appendPoolTestRows(t, first, 300, 1000, 4)
+	first.Release()
+	assert.Equal(t, 0, first.GetRowNum())
+
+	second, err := NewInsertData(genPoolTestSchema(8), WithPooledArrays(100))
+	require.NoError(t, err)
+	assert.Equal(t, 0, second.GetRowNum())
+	appendPoolTestRows(t, second, 100, 2001, 8)
+	assert.Equal(t, 100, second.GetRowNum())
+	for i := 0; i < second.GetRowNum(); i++ {
+		assert.Equal(t, int64(2001+i), second.Data[RowIDField].GetRow(i))
+		assert.Equal(t, int64(2001), second.Data[TimestampField].GetRow(i))
+		assert.Equal(t, float64(2001), second.Data[DoubleField].GetRow(i))
+		assert.Equal(t, false, second.Data[BoolField].GetRow(i))
+		assert.Equal(t, []float32{2001, 2001, 2001, 2001, 2001, 2001, 2001, 2001}, second.Data[FloatVectorField].GetRow(i))
+	}
+
+	// reset keeps the fields usable
+	second.Reset(100)
+	assert.Equal(t, 0, second.GetRowNum())
+	appendPoolTestRows(t, second, 2, 4000, 8)
+	assert.Equal(t, []int64{4000, 4001}, second.Data[RowIDField].(*Int64FieldData).Data)
+	assert.Equal(t, []bool{true, true}, second.Data[BoolField].(*BoolFieldData).Data)
+	second.Release()
+
+	// capacity beyond the pooled classes is not pooled
+	arr := getPooledArray[int64](fieldArrayPool, 1<<(maxPooledCapClass+1))
+	assert.Equal(t, 1<<(maxPooledCapClass+1), cap(arr))
+	putPooledArray(fieldArrayPool, arr)
+}
+
+func BenchmarkMultiCollectionIngest(b *testing.B) {
+	const (
+		collectionNum = 8
+		rowNum        = 1024
+		dim           = 16
+	)
+	schemas := make([]*schemapb.CollectionSchema, 0, collectionNum)
+	for i := 0; i < collectionNum; i++ {
+		schemas = append(schemas, genPoolTestSchema(dim))
+	}
+
+	run := func(b *testing.B, opts ...InsertDataOption) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for c, schema := range schemas {
+				data, err := NewInsertData(schema, opts...)
+				if err != nil {
+					b.Fatal(err)
+				}
+				appendPoolTestRows(b, data, rowNum, int64(c), dim)
+				data.Release()
+			}
+		}
+	}
+	b.Run("unpooled", func(b *testing.B) { run(b) })
+	b.Run("pooled", func(b *testing.B) { run(b, WithPooledArrays(rowNum)) })
+}
@@ -0,0 +1,117 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// FieldStats is the precomputed range and row count of an int64 or varchar field,
+// normally the primary key field of a segment.
+type FieldStats struct {
+	FieldID FieldID
+	// Min and Max are nil if there is no row
+	Min    PrimaryKey
+	Max    PrimaryKey
+	RowNum int64
+}
+
+// SegmentStatsMerge combines the stats of the same field from many segments,
+// the result covers the union range and the summed row count.
+func SegmentStatsMerge(stats ...FieldStats) FieldStats {
+	var merged FieldStats
+	for idx, s := range stats {
+		if idx == 0 {
+			merged.FieldID = s.FieldID
+		}
+		merged.RowNum += s.RowNum
+		if s.Min != nil && (merged.Min == nil || s.Min.LT(merged.Min)) {
+			merged.Min = s.Min
+		}
+		if s.Max != nil && (merged.Max == nil || s.Max.GT(merged.Max)) {
+			merged.Max = s.Max
+		}
+	}
+	return merged
+}
+
+// InsertDataStats is the precomputed stats of an InsertData.
+type InsertDataStats struct {
+	RowNum int64
+	Fields map[FieldID]FieldStats
+}
+
+// MergeInsertDataStats combines the stats of many InsertData field by field.
+func MergeInsertDataStats(stats ...InsertDataStats) InsertDataStats {
+	merged := InsertDataStats{Fields: make(map[FieldID]FieldStats)}
+	for _, s := range stats {
+		merged.RowNum += s.RowNum
+		for fieldID, fieldStats := range s.Fields {
+			if prev, ok := merged.Fields[fieldID]; ok {
+				fieldStats = SegmentStatsMerge(prev, fieldStats)
+			}
+			merged.Fields[fieldID] = fieldStats
+		}
+	}
+	return merged
+}
+
+// FieldStats computes the stats of an int64 or varchar field.
+func (i *InsertData) FieldStats(fieldID FieldID) (FieldStats, error) {
+	stats := FieldStats{FieldID: fieldID}
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return stats, merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	switch data := fieldData.(type) {
+	case *Int64FieldData:
+		for _, v := range data.Data {
+			stats.update(NewInt64PrimaryKey(v))
+		}
+	case *StringFieldData:
+		for _, v := range data.Data {
+			stats.update(NewVarCharPrimaryKey(v))
+		}
+	default:
+		return stats, merr.WrapErrParameterInvalidMsg("stats of field %d with type %T is not supported", fieldID, fieldData)
+	}
+	return stats, nil
+}
+
+// Stats computes the stats of all int64 and varchar fields.
+func (i *InsertData) Stats() InsertDataStats {
+	stats := InsertDataStats{
+		RowNum: int64(i.GetRowNum()),
+		Fields: make(map[FieldID]FieldStats),
+	}
+	for fieldID := range i.Data {
+		if fieldStats, err := i.FieldStats(fieldID); err == nil {
+			stats.Fields[fieldID] = fieldStats
+		}
+	}
+	return stats
+}
+
+func (s *FieldStats) update(pk PrimaryKey) {
+	s.RowNum++
+	if s.Min == nil || pk.LT(s.Min) {
+		s.Min = pk
+	}
+	if s.Max == nil || pk.GT(s.Max) {
+		s.Max = pk
+	}
+}
@@ -0,0 +1,78 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentStatsMerge(t *testing.T) {
+	segments := []*InsertData{
+		{Data: map[FieldID]FieldData{
+			RowIDField:  &Int64FieldData{Data: []int64{1, 2, 3}},
+			Int64Field:  &Int64FieldData{Data: []int64{10, 30, 20}},
+			StringField: &StringFieldData{Data: []string{"b", "d", "c"}},
+			FloatField:  &FloatFieldData{Data: []float32{1, 2, 3}},
+		}},
+		{Data: map[FieldID]FieldData{
+			RowIDField:  &Int64FieldData{Data: []int64{4, 5}},
+			Int64Field:  &Int64FieldData{Data: []int64{25, 5}},
+			StringField: &StringFieldData{Data: []string{"c", "a"}},
+			FloatField:  &FloatFieldData{Data: []float32{1, 2}},
+		}},
+		{Data: map[FieldID]FieldData{
+			RowIDField:  &Int64FieldData{Data: []int64{6, 7, 8, 9}},
+			Int64Field:  &Int64FieldData{Data: []int64{15, 40, 35, 12}},
+			StringField: &StringFieldData{Data: []string{"e", "b", "c", "d"}},
+			FloatField:  &FloatFieldData{Data: []float32{1, 2, 3, 4}},
+		}},
+	}
+
+	var fieldStats []FieldStats
+	var dataStats []InsertDataStats
+	for _, segment := range segments {
+		stats, err := segment.FieldStats(Int64Field)
+		require.NoError(t, err)
+		fieldStats = append(fieldStats, stats)
+		dataStats = append(dataStats, segment.Stats())
+	}
+
+	merged := SegmentStatsMerge(fieldStats...)
+	assert.EqualValues(t, Int64Field, merged.FieldID)
+	assert.EqualValues(t, 9, merged.RowNum)
+	assert.True(t, merged.Min.EQ(NewInt64PrimaryKey(5)))
+	assert.True(t, merged.Max.EQ(NewInt64PrimaryKey(40)))
+
+	all := MergeInsertDataStats(dataStats...)
+	assert.EqualValues(t, 9, all.RowNum)
+	assert.Len(t, all.Fields, 3)
+	assert.NotContains(t, all.Fields, FieldID(FloatField))
+	assert.True(t, all.Fields[StringField].Min.EQ(NewVarCharPrimaryKey("a")))
+	assert.True(t, all.Fields[StringField].Max.EQ(NewVarCharPrimaryKey("e")))
+	assert.True(t, all.Fields[RowIDField].Max.EQ(NewInt64PrimaryKey(9)))
+
+	// empty stats do not affect the range
+	merged = SegmentStatsMerge(merged, FieldStats{FieldID: Int64Field})
+	assert.EqualValues(t, 9, merged.RowNum)
+	assert.True(t, merged.Min.EQ(NewInt64PrimaryKey(5)))
+
+	_, err := segments[0].FieldStats(FloatField)
+	assert.Error(t, err)
+}
@@ -17,12 +17,26 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang/protobuf/proto"
+	"github.com/samber/lo"
 
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
 	"github.com/milvus-io/milvus/pkg/common"
 	"github.com/milvus-io/milvus/pkg/util/merr"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
+	"github.com/milvus-io/milvus/pkg/util/typeutil"
 )
 
 // TODO: fill it
@@ -31,88 +45,1548 @@ type BlobInfo struct {
 	Length int
 }
 
-// InsertData example row_schema: {float_field, int_field, float_vector_field, string_field}
-// Data {<0, row_id>, <1, timestamp>, <100, float_field>, <101, int_field>, <102, float_vector_field>, <103, string_field>}
-//
-// system filed id:
-// 0: unique row id
-// 1: timestamp
-// 100: first user field id
-// 101: second user field id
-// 102: ...
-type InsertData struct {
-	// TODO, data should be zero copy by passing data directly to event reader or change Data to map[FieldID]FieldDataArray
-	Data  map[FieldID]FieldData // field id to field data
-	Infos []BlobInfo
+// InsertData example row_schema: {float_field, int_field, float_vector_field, string_field}
+// Data {<0, row_id>, <1, timestamp>, <100, float_field>, <101, int_field>, <102, float_vector_field>, <103, string_field>}
+//
+// system filed id:
+// 0: unique row id
+// 1: timestamp
+// 100: first user field id
+// 101: second user field id
+// 102: ...
+type InsertData struct {
+	// TODO, data should be zero copy by passing data directly to event reader or change Data to map[FieldID]FieldDataArray
+	Data  map[FieldID]FieldData // field id to field data
+	Infos []BlobInfo
+
+	// schema is kept to resolve field names, nil if InsertData is not created by NewInsertData
+	schema *schemapb.CollectionSchema
+	// missing records the offsets of rows absent from each field, appended by AppendPartial
+	missing map[FieldID][]int
+}
+
+// InsertDataOption is the optional parameter of NewInsertData.
+type InsertDataOption func(opt *insertDataOption)
+
+type insertDataOption struct {
+	inferDim        bool
+	pooledRows      int
+	rejectNonFinite bool
+}
+
+// WithInferDim makes vector fields without dim in schema take the dim of the first appended row,
+// later rows with different dim are rejected.
+func WithInferDim() InsertDataOption {
+	return func(opt *insertDataOption) {
+		opt.inferDim = true
+	}
+}
+
+// WithPooledArrays makes fixed-width fields take backing arrays holding rowNum rows from the pool
+// shared by all collections, the arrays shall be returned with Release or Reset.
+func WithPooledArrays(rowNum int) InsertDataOption {
+	return func(opt *insertDataOption) {
+		opt.pooledRows = rowNum
+	}
+}
+
+// WithRejectNonFinite makes float vector fields reject rows containing NaN or Inf,
+// which costs a scan of each appended vector.
+func WithRejectNonFinite() InsertDataOption {
+	return func(opt *insertDataOption) {
+		opt.rejectNonFinite = true
+	}
+}
+
+// applyFieldData sets the per field options on fieldData.
+func (opt *insertDataOption) applyFieldData(fieldData FieldData) {
+	if d, ok := fieldData.(*FloatVectorFieldData); ok {
+		d.rejectNonFinite = opt.rejectNonFinite
+	}
+}
+
+func NewInsertData(schema *schemapb.CollectionSchema, opts ...InsertDataOption) (*InsertData, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("Nil input schema")
+	}
+	option := &insertDataOption{}
+	for _, opt := range opts {
+		opt(option)
+	}
+
+	idata := &InsertData{
+		Data:   make(map[FieldID]FieldData),
+		schema: schema,
+	}
+
+	for _, fSchema := range schema.Fields {
+		if option.inferDim && typeutil.IsVectorType(fSchema.DataType) {
+			if _, err := GetDimFromParams(fSchema.GetTypeParams()); err != nil {
+				fieldData, err := newDimInferredFieldData(fSchema.DataType)
+				if err != nil {
+					return nil, err
+				}
+				option.applyFieldData(fieldData)
+				idata.Data[fSchema.FieldID] = fieldData
+				continue
+			}
+		}
+		fieldData, err := NewFieldData(fSchema.DataType, fSchema)
+		if err != nil {
+			return nil, err
+		}
+		if option.pooledRows > 0 {
+			acquirePooledArrays(fieldData, option.pooledRows)
+		}
+		option.applyFieldData(fieldData)
+		idata.Data[fSchema.FieldID] = fieldData
+	}
+	return idata, nil
+}
+
+// NewInsertDataWithCap creates InsertData like NewInsertData, with the backing array of each column
+// preallocated to hold cap rows, so that appending up to cap rows causes no reallocation.
+// Columns of variable row size only preallocate the row slice. GetMemorySize counts appended rows only.
+func NewInsertDataWithCap(schema *schemapb.CollectionSchema, cap int) (*InsertData, error) {
+	if cap < 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("negative capacity %d", cap)
+	}
+	idata, err := NewInsertData(schema)
+	if err != nil {
+		return nil, err
+	}
+	for _, fieldData := range idata.Data {
+		reserveFieldData(fieldData, cap)
+	}
+	return idata, nil
+}
+
+// newDimInferredFieldData returns an empty vector field data whose dim is set by the first appended row.
+func newDimInferredFieldData(dataType schemapb.DataType) (FieldData, error) {
+	switch dataType {
+	case schemapb.DataType_FloatVector:
+		return &FloatVectorFieldData{Data: make([]float32, 0), inferDim: true}, nil
+	case schemapb.DataType_BinaryVector:
+		return &BinaryVectorFieldData{Data: make([]byte, 0), inferDim: true}, nil
+	case schemapb.DataType_Float16Vector:
+		return &Float16VectorFieldData{Data: make([]byte, 0), inferDim: true}, nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("dim of %s could not be inferred", dataType.String())
+	}
+}
+
+func (iData *InsertData) IsEmpty() bool {
+	if iData == nil {
+		return true
+	}
+
+	timeFieldData, ok := iData.Data[common.TimeStampField]
+	return (!ok) || (timeFieldData.RowNum() <= 0)
+}
+
+func (i *InsertData) GetRowNum() int {
+	if i.Data == nil || len(i.Data) == 0 {
+		return 0
+	}
+
+	data, ok := i.Data[common.RowIDField]
+	if !ok {
+		return 0
+	}
+
+	return data.RowNum()
+}
+
+func (i *InsertData) GetMemorySize() int {
+	var size int
+	if i.Data == nil || len(i.Data) == 0 {
+		return size
+	}
+
+	for _, data := range i.Data {
+		size += data.GetMemorySize()
+	}
+
+	return size
+}
+
+// MemorySizeByField returns the memory size of each field, which sum up to GetMemorySize.
+func (i *InsertData) MemorySizeByField() map[FieldID]int {
+	sizes := make(map[FieldID]int, len(i.Data))
+	for fieldID, data := range i.Data {
+		sizes[fieldID] = data.GetMemorySize()
+	}
+	return sizes
+}
+
+// SortedField is a field of InsertData along with its id.
+type SortedField struct {
+	FieldID FieldID
+	Data    FieldData
+}
+
+// SortedFields returns the fields in ascending FieldID order,
+// serializers shall iterate fields this way so the output doesn't depend on map iteration order.
+func (i *InsertData) SortedFields() []SortedField {
+	fields := make([]SortedField, 0, len(i.Data))
+	for fieldID, data := range i.Data {
+		fields = append(fields, SortedField{FieldID: fieldID, Data: data})
+	}
+	sort.Slice(fields, func(a, b int) bool { return fields[a].FieldID < fields[b].FieldID })
+	return fields
+}
+
+func (i *InsertData) Append(row map[FieldID]interface{}) error {
+	for fID, v := range row {
+		field, ok := i.Data[fID]
+		if !ok {
+			return fmt.Errorf("Missing field when appending row, got %d", fID)
+		}
+
+		if err := field.AppendRow(v); err != nil {
+			return errors.Wrapf(err, "failed to append field %d", fID)
+		}
+	}
+
+	return nil
+}
+
+// AppendColumn appends a whole column to the field of fieldID, column is the typed slice accepted by
+// BulkAppender.AppendRows of the field, rows appended to nullable fields are valid.
+// Columns are appended one by one so their row numbers are not checked against each other,
+// use ValidateRowNum after all columns are appended.
+func (i *InsertData) AppendColumn(fieldID FieldID, column interface{}) error {
+	field, ok := i.Data[fieldID]
+	if !ok {
+		return fmt.Errorf("Missing field when appending column, got %d", fieldID)
+	}
+	appender, ok := field.(BulkAppender)
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("appending column to field %d of %T is not supported", fieldID, field)
+	}
+	if err := appender.AppendRows(column); err != nil {
+		return errors.Wrapf(err, "failed to append column of field %d", fieldID)
+	}
+	return nil
+}
+
+// ValidateRowNum checks all fields have the same row number as GetRowNum.
+func (i *InsertData) ValidateRowNum() error {
+	rowNum := i.GetRowNum()
+	for fieldID, fieldData := range i.Data {
+		if fieldData.RowNum() != rowNum {
+			return merr.WrapErrParameterInvalidMsg("row num of field %d is %d, expected %d", fieldID, fieldData.RowNum(), rowNum)
+		}
+	}
+	return nil
+}
+
+// Validate checks i could be serialized with schema: every field of schema is present with the matching type and dim,
+// no field is out of schema, and all columns have the same row num. It returns the first problem found.
+func (i *InsertData) Validate(schema *schemapb.CollectionSchema) error {
+	if schema == nil {
+		return merr.WrapErrParameterInvalidMsg("nil schema")
+	}
+	schemaFields := typeutil.NewSet[FieldID]()
+	for _, field := range schema.GetFields() {
+		schemaFields.Insert(field.GetFieldID())
+		fieldData, ok := i.Data[field.GetFieldID()]
+		if !ok {
+			return merr.WrapErrParameterInvalidMsg("field %d(%s) missing in insert data", field.GetFieldID(), field.GetName())
+		}
+		if err := validateFieldData(field, fieldData); err != nil {
+			return err
+		}
+	}
+	for _, field := range i.SortedFields() {
+		if !schemaFields.Contain(field.FieldID) {
+			return merr.WrapErrParameterInvalidMsg("field %d not found in schema", field.FieldID)
+		}
+	}
+	return i.ValidateRowNum()
+}
+
+// validateFieldData checks fieldData holds the values of field in a column type InsertCodec serializes.
+func validateFieldData(field *schemapb.FieldSchema, fieldData FieldData) error {
+	var match bool
+	switch data := fieldData.(type) {
+	case *BoolFieldData:
+		match = field.GetDataType() == schemapb.DataType_Bool
+	case *Int8FieldData:
+		match = field.GetDataType() == schemapb.DataType_Int8
+	case *Int16FieldData:
+		match = field.GetDataType() == schemapb.DataType_Int16
+	case *Int32FieldData:
+		match = field.GetDataType() == schemapb.DataType_Int32
+	case *Int64FieldData:
+		match = field.GetDataType() == schemapb.DataType_Int64
+	case *FloatFieldData:
+		match = field.GetDataType() == schemapb.DataType_Float
+	case *DoubleFieldData:
+		match = field.GetDataType() == schemapb.DataType_Double
+	case *StringFieldData, *LazyStringFieldData:
+		match = field.GetDataType() == schemapb.DataType_String || field.GetDataType() == schemapb.DataType_VarChar
+	case *JSONFieldData, *LazyJSONFieldData:
+		match = field.GetDataType() == schemapb.DataType_JSON
+	case *ArrayFieldData:
+		match = field.GetDataType() == schemapb.DataType_Array && data.ElementType == field.GetElementType()
+	case *ContiguousArrayFieldData:
+		match = field.GetDataType() == schemapb.DataType_Array && data.ElementType == field.GetElementType()
+	case *BinaryVectorFieldData:
+		match = field.GetDataType() == schemapb.DataType_BinaryVector
+	case *FloatVectorFieldData:
+		match = field.GetDataType() == schemapb.DataType_FloatVector
+	case *Float16VectorFieldData:
+		match = field.GetDataType() == schemapb.DataType_Float16Vector
+	default:
+		// e.g. nullable columns, whose validity has no place in the insert binlog format,
+		// and quantized columns, whose original vectors could not be restored
+		return newUnsupportedByCodecError(field, fieldData)
+	}
+	if !match {
+		return merr.WrapErrParameterInvalidMsg("field %d(%s) of type %s got column %T", field.GetFieldID(), field.GetName(), field.GetDataType().String(), fieldData)
+	}
+
+	// vectors of schema without dim infer the dim from data
+	dim, err := GetDimFromParams(field.GetTypeParams())
+	if err == nil && typeutil.IsVectorType(field.GetDataType()) && getFieldDataDim(fieldData) != dim {
+		return merr.WrapErrParameterInvalidMsg("field %d(%s) expects dim %d, got %d", field.GetFieldID(), field.GetName(), dim, getFieldDataDim(fieldData))
+	}
+	return nil
+}
+
+// materialize replaces columns which are decompressed lazily or compacted with the plain columns InsertCodec serializes.
+func (i *InsertData) materialize() error {
+	for fieldID, fieldData := range i.Data {
+		column, err := materializeFieldData(fieldData)
+		if err != nil {
+			return errors.Wrapf(err, "failed to materialize field %d", fieldID)
+		}
+		i.Data[fieldID] = column
+	}
+	return nil
+}
+
+// materializeFieldData returns the plain column holding the same rows as data, or data itself if it is plain.
+func materializeFieldData(data FieldData) (FieldData, error) {
+	switch data := data.(type) {
+	case *LazyStringFieldData:
+		values, err := data.Column()
+		if err != nil {
+			return nil, err
+		}
+		return &StringFieldData{Data: values}, nil
+	case *LazyJSONFieldData:
+		values, err := data.Column()
+		if err != nil {
+			return nil, err
+		}
+		return &JSONFieldData{Data: values}, nil
+	case *ContiguousArrayFieldData:
+		return data.ToArrayFieldData(), nil
+	default:
+		return data, nil
+	}
+}
+
+// newUnsupportedByCodecError returns the error of fieldData in a column type InsertCodec could not serialize.
+func newUnsupportedByCodecError(field *schemapb.FieldSchema, fieldData FieldData) error {
+	return merr.WrapErrParameterInvalidMsg("field %d(%s) got column %T, which is unsupported by codec", field.GetFieldID(), field.GetName(), fieldData)
+}
+
+const (
+	// estimatedBlobOverhead is the approximate size of binlog headers and payload metadata of one field blob.
+	estimatedBlobOverhead = 400
+	// estimatedVarLenCompressRatio is the approximate compress ratio of variable length payload.
+	estimatedVarLenCompressRatio = 0.5
+)
+
+// AppendRows appends rows column by column, which saves the per row lookup of fields.
+// All rows are validated before any of them is appended, i is unchanged if any row is invalid.
+func (i *InsertData) AppendRows(rows []map[FieldID]interface{}) error {
+	for _, row := range rows {
+		for fID := range row {
+			if _, ok := i.Data[fID]; !ok {
+				return fmt.Errorf("Missing field when appending row, got %d", fID)
+			}
+		}
+	}
+
+	// rows are appended to staged columns first, so that a failure leaves i untouched
+	staged := make(map[FieldID]FieldData, len(i.Data))
+	for fID, field := range i.Data {
+		column := field.Slice(0, 0)
+		reserveFieldData(column, len(rows))
+		appended := 0
+		for _, row := range rows {
+			v, ok := row[fID]
+			if !ok {
+				continue
+			}
+			if err := column.AppendRow(v); err != nil {
+				return errors.Wrapf(err, "failed to append field %d", fID)
+			}
+			appended++
+		}
+		if appended > 0 {
+			staged[fID] = column
+		}
+	}
+
+	// staged rows are merged into capped slices of the fields, which never write to the buffers of i,
+	// the merged columns replace the fields only after all of them succeed
+	merged := &InsertData{Data: make(map[FieldID]FieldData, len(staged))}
+	for fID, column := range staged {
+		field := i.Data[fID]
+		if getFieldDataDim(field) != getFieldDataDim(column) {
+			// the field has no row yet and its dim is inferred by the staged rows
+			merged.Data[fID] = column
+			continue
+		}
+		merged.Data[fID] = field.Slice(0, field.RowNum())
+		if err := merged.mergeColumn(fID, column); err != nil {
+			return errors.Wrapf(err, "failed to append field %d", fID)
+		}
+	}
+	for fID, column := range merged.Data {
+		i.Data[fID] = column
+	}
+	return nil
+}
+
+// reserveFieldData grows the backing array of data to hold rowNum more rows,
+// it's a no-op for field data types with variable row size.
+func reserveFieldData(data FieldData, rowNum int) {
+	switch d := data.(type) {
+	case *BoolFieldData:
+		d.Data = make([]bool, 0, rowNum)
+	case *Int8FieldData:
+		d.Data = make([]int8, 0, rowNum)
+	case *Int16FieldData:
+		d.Data = make([]int16, 0, rowNum)
+	case *Int32FieldData:
+		d.Data = make([]int32, 0, rowNum)
+	case *Int64FieldData:
+		d.Data = make([]int64, 0, rowNum)
+	case *FloatFieldData:
+		d.Data = make([]float32, 0, rowNum)
+	case *DoubleFieldData:
+		d.Data = make([]float64, 0, rowNum)
+	case *StringFieldData:
+		d.Data = make([]string, 0, rowNum)
+	case *JSONFieldData:
+		d.Data = make([][]byte, 0, rowNum)
+	case *ArrayFieldData:
+		d.Data = make([]*schemapb.ScalarField, 0, rowNum)
+	case *BinaryVectorFieldData:
+		d.Data = make([]byte, 0, rowNum*d.Dim/8)
+	case *FloatVectorFieldData:
+		d.Data = make([]float32, 0, rowNum*d.Dim)
+	case *Float16VectorFieldData:
+		d.Data = make([]byte, 0, rowNum*d.Dim*2)
+	}
+}
+
+// AppendReusing appends a row like Append, but the caller could reuse row and the values in it for the next call.
+// Values are copied out synchronously and neither the map nor the byte slices of json values are retained.
+// Fields of row are checked before appending, so no field is appended if any field is missing.
+func (i *InsertData) AppendReusing(row map[FieldID]interface{}) error {
+	for fID := range row {
+		if _, ok := i.Data[fID]; !ok {
+			return fmt.Errorf("Missing field when appending row, got %d", fID)
+		}
+	}
+
+	for fID, v := range row {
+		field := i.Data[fID]
+		// JSONFieldData keeps the appended slice, copy it since the caller may reuse the buffer
+		if bs, ok := v.([]byte); ok {
+			if _, isJSON := field.(*JSONFieldData); isJSON {
+				v = append([]byte(nil), bs...)
+			}
+		}
+		if err := field.AppendRow(v); err != nil {
+			return errors.Wrapf(err, "failed to append field %d", fID)
+		}
+	}
+	return nil
+}
+
+// EstimateSerializedSize approximates the total size of blobs generated by codec.Serialize without serializing.
+// Fixed length payloads are counted by their layout size, variable length ones are assumed to be compressed.
+func (i *InsertData) EstimateSerializedSize(codec *InsertCodec) (int, error) {
+	if codec == nil || codec.Schema == nil {
+		return 0, merr.WrapErrParameterInvalidMsg("codec without schema")
+	}
+
+	var size int
+	for _, field := range codec.Schema.GetSchema().GetFields() {
+		fieldData, ok := i.Data[field.GetFieldID()]
+		if !ok {
+			return 0, merr.WrapErrParameterInvalidMsg("field %d not found in insert data", field.GetFieldID())
+		}
+
+		size += estimatedBlobOverhead
+		switch data := fieldData.(type) {
+		case *BoolFieldData:
+			// bool values are bit packed
+			size += (data.RowNum() + 7) / 8
+		case *BinaryVectorFieldData, *FloatVectorFieldData, *Float16VectorFieldData:
+			// vectors are hardly compressible, exclude dim from memory size
+			size += data.GetMemorySize() - 4
+		case *StringFieldData:
+			var length int
+			for _, val := range data.Data {
+				length += len(val) + 4
+			}
+			size += int(float64(length) * estimatedVarLenCompressRatio)
+		case *JSONFieldData:
+			var length int
+			for _, val := range data.Data {
+				length += len(val) + 4
+			}
+			size += int(float64(length) * estimatedVarLenCompressRatio)
+		case *ArrayFieldData:
+			size += int(float64(data.GetMemorySize()) * estimatedVarLenCompressRatio)
+		default:
+			size += data.GetMemorySize()
+		}
+	}
+	return size, nil
+}
+
+// TimestampConflict records rows sharing the same primary key and timestamp,
+// which is ambiguous for MVCC.
+type TimestampConflict struct {
+	PK        any
+	Timestamp int64
+	Offsets   []int
+}
+
+// FindTimestampConflicts returns all groups of rows which have same primary key and timestamp,
+// ordered by first occurrence.
+func (i *InsertData) FindTimestampConflicts(pkFieldID FieldID) ([]TimestampConflict, error) {
+	pkData, ok := i.Data[pkFieldID]
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("primary key field %d not found", pkFieldID)
+	}
+	switch pkData.(type) {
+	case *Int64FieldData, *StringFieldData:
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported primary key type %T", pkData)
+	}
+	tsData, ok := i.Data[common.TimeStampField].(*Int64FieldData)
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("timestamp field not found")
+	}
+	if pkData.RowNum() != tsData.RowNum() {
+		return nil, merr.WrapErrParameterInvalidMsg("row num not match, pk: %d, timestamp: %d", pkData.RowNum(), tsData.RowNum())
+	}
+
+	type pkTs struct {
+		pk any
+		ts int64
+	}
+	offsets := make(map[pkTs][]int)
+	keys := make([]pkTs, 0)
+	for row := 0; row < pkData.RowNum(); row++ {
+		key := pkTs{pk: pkData.GetRow(row), ts: tsData.Data[row]}
+		if _, ok := offsets[key]; !ok {
+			keys = append(keys, key)
+		}
+		offsets[key] = append(offsets[key], row)
+	}
+
+	var conflicts []TimestampConflict
+	for _, key := range keys {
+		if len(offsets[key]) > 1 {
+			conflicts = append(conflicts, TimestampConflict{
+				PK:        key.pk,
+				Timestamp: key.ts,
+				Offsets:   offsets[key],
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// ScalarsToVector packs the numeric columns of fieldIDs into a float vector column stored as targetFieldID.
+// The dim of the vector equals to the number of source fields, values of each row keep the order of fieldIDs.
+func (i *InsertData) ScalarsToVector(fieldIDs []FieldID, targetFieldID FieldID) error {
+	if len(fieldIDs) == 0 {
+		return merr.WrapErrParameterInvalidMsg("no source field provided")
+	}
+	if target, ok := i.Data[targetFieldID]; ok && target.RowNum() > 0 {
+		return merr.WrapErrParameterInvalidMsg("target field %d already has data", targetFieldID)
+	}
+
+	rowNum := -1
+	for _, fieldID := range fieldIDs {
+		fieldData, ok := i.Data[fieldID]
+		if !ok {
+			return merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+		}
+		switch fieldData.(type) {
+		case *Int8FieldData, *Int16FieldData, *Int32FieldData, *Int64FieldData, *FloatFieldData, *DoubleFieldData:
+		default:
+			return merr.WrapErrParameterInvalidMsg("field %d is not numeric, type %T", fieldID, fieldData)
+		}
+		if rowNum >= 0 && fieldData.RowNum() != rowNum {
+			return merr.WrapErrParameterInvalidMsg("row num of field %d not match, expected %d, actual %d", fieldID, rowNum, fieldData.RowNum())
+		}
+		rowNum = fieldData.RowNum()
+	}
+
+	dim := len(fieldIDs)
+	vectors := make([]float32, rowNum*dim)
+	for idx, fieldID := range fieldIDs {
+		switch fieldData := i.Data[fieldID].(type) {
+		case *Int8FieldData:
+			for row, val := range fieldData.Data {
+				vectors[row*dim+idx] = float32(val)
+			}
+		case *Int16FieldData:
+			for row, val := range fieldData.Data {
+				vectors[row*dim+idx] = float32(val)
+			}
+		case *Int32FieldData:
+			for row, val := range fieldData.Data {
+				vectors[row*dim+idx] = float32(val)
+			}
+		case *Int64FieldData:
+			for row, val := range fieldData.Data {
+				vectors[row*dim+idx] = float32(val)
+			}
+		case *FloatFieldData:
+			for row, val := range fieldData.Data {
+				vectors[row*dim+idx] = val
+			}
+		case *DoubleFieldData:
+			for row, val := range fieldData.Data {
+				vectors[row*dim+idx] = float32(val)
+			}
+		}
+	}
+	i.Data[targetFieldID] = &FloatVectorFieldData{Data: vectors, Dim: dim}
+	return nil
+}
+
+// DistinctTerms returns the sorted distinct terms of a varchar field and the number of rows containing each term.
+// If tokenize is true, values are split by white spaces, otherwise each value is a whole term. Null rows contain no term.
+func (i *InsertData) DistinctTerms(fieldID FieldID, tokenize bool) ([]string, []int, error) {
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return nil, nil, merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	// null rows are not stored in the wrapped column
+	if nullable, ok := fieldData.(*NullableFieldData); ok {
+		fieldData = nullable.FieldData
+	}
+	fieldData, err := materializeFieldData(fieldData)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to materialize field %d", fieldID)
+	}
+	stringData, ok := fieldData.(*StringFieldData)
+	if !ok {
+		return nil, nil, merr.WrapErrParameterInvalidMsg("field %d is not varchar, type %T", fieldID, fieldData)
+	}
+
+	counts := make(map[string]int)
+	for _, value := range stringData.Data {
+		if !tokenize {
+			counts[value]++
+			continue
+		}
+		// count each row once even if the token repeats in the row
+		tokens := typeutil.NewSet(strings.Fields(value)...)
+		for token := range tokens {
+			counts[token]++
+		}
+	}
+
+	terms := lo.Keys(counts)
+	sort.Strings(terms)
+	rowCounts := make([]int, 0, len(terms))
+	for _, term := range terms {
+		rowCounts = append(rowCounts, counts[term])
+	}
+	return terms, rowCounts, nil
+}
+
+// Types of json value inferred by InferJSONSchema.
+const (
+	JSONTypeString   = "string"
+	JSONTypeNumber   = "number"
+	JSONTypeBool     = "bool"
+	JSONTypeObject   = "object"
+	JSONTypeArray    = "array"
+	JSONTypeConflict = "conflict"
+)
+
+// InferJSONSchema infers the value type of each top-level key of a json field.
+// Keys with values of different types among rows are marked as JSONTypeConflict, null values and null rows are ignored.
+func (i *InsertData) InferJSONSchema(fieldID FieldID) (map[string]string, error) {
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	jsonData := fieldData
+	if nullable, ok := fieldData.(*NullableFieldData); ok {
+		jsonData = nullable.FieldData
+	}
+	if _, ok := jsonData.(*JSONFieldData); !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("field %d is not json, type %T", fieldID, fieldData)
+	}
+
+	types := make(map[string]string)
+	for row := 0; row < fieldData.RowNum(); row++ {
+		value := fieldData.GetRow(row)
+		if value == nil {
+			continue
+		}
+		obj := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(value.([]byte), &obj); err != nil {
+			return nil, merr.WrapErrParameterInvalidMsg("row %d of field %d is not a json object: %s", row, fieldID, err.Error())
+		}
+		for key, raw := range obj {
+			valueType := inferJSONType(raw)
+			if valueType == "" {
+				continue
+			}
+			if existing, ok := types[key]; ok && existing != valueType {
+				valueType = JSONTypeConflict
+			}
+			types[key] = valueType
+		}
+	}
+	return types, nil
+}
+
+// inferJSONType returns the type of a valid json value, empty string for null.
+func inferJSONType(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return ""
+	}
+	switch raw[0] {
+	case '"':
+		return JSONTypeString
+	case '{':
+		return JSONTypeObject
+	case '[':
+		return JSONTypeArray
+	case 't', 'f':
+		return JSONTypeBool
+	case 'n':
+		return ""
+	default:
+		return JSONTypeNumber
+	}
+}
+
+// ExtractPath extracts the scalar at the dotted path, e.g. "meta.level", of each row into a new column.
+// The column type is inferred from the values: Int64 if all are integers, Double if all are numbers,
+// String if all are strings. Rows where the path is missing or null are null in the returned NullableFieldData.
+func (data *JSONFieldData) ExtractPath(path string) (FieldData, error) {
+	keys := strings.Split(path, ".")
+	if lo.Contains(keys, "") {
+		return nil, merr.WrapErrParameterInvalidMsg("invalid json path %q", path)
+	}
+
+	values := make([]any, 0, len(data.Data))
+	var hasInt, hasFloat, hasString bool
+	for row, blob := range data.Data {
+		raw, err := lookupJSONPath(blob, keys)
+		if err != nil {
+			return nil, merr.WrapErrParameterInvalidMsg("row %d is not valid json: %s", row, err.Error())
+		}
+		var value any
+		if raw != nil {
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.UseNumber()
+			if err := decoder.Decode(&value); err != nil {
+				return nil, merr.WrapErrParameterInvalidMsg("row %d is not valid json: %s", row, err.Error())
+			}
+		}
+		switch v := value.(type) {
+		case nil:
+		case json.Number:
+			if _, err := v.Int64(); err == nil {
+				hasInt = true
+			} else {
+				hasFloat = true
+			}
+		case string:
+			hasString = true
+		default:
+			return nil, merr.WrapErrParameterInvalidMsg("value at %q of row %d is not scalar: %s", path, row, string(raw))
+		}
+		values = append(values, value)
+	}
+	if hasString && (hasInt || hasFloat) {
+		return nil, merr.WrapErrParameterInvalidMsg("values at %q are of both string and number", path)
+	}
+
+	var column FieldData
+	switch {
+	case hasString:
+		column = &StringFieldData{}
+	case hasFloat:
+		column = &DoubleFieldData{}
+	case hasInt:
+		column = &Int64FieldData{}
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("path %q not found in any row", path)
+	}
+	result := NewNullableFieldData(column)
+	for _, value := range values {
+		var row any
+		switch v := value.(type) {
+		case json.Number:
+			if hasFloat {
+				row, _ = v.Float64()
+			} else {
+				row, _ = v.Int64()
+			}
+		case string:
+			row = v
+		}
+		if err := result.AppendNullableRow(row, value != nil); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// lookupJSONPath returns the raw value at keys of blob, nil if the path is missing or not an object along the way.
+func lookupJSONPath(blob []byte, keys []string) (json.RawMessage, error) {
+	raw := json.RawMessage(blob)
+	if !json.Valid(raw) {
+		return nil, errors.New("invalid json")
+	}
+	for _, key := range keys {
+		if inferJSONType(raw) != JSONTypeObject {
+			return nil, nil
+		}
+		obj := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, err
+		}
+		value, ok := obj[key]
+		if !ok {
+			return nil, nil
+		}
+		raw = value
+	}
+	return raw, nil
+}
+
+// RowHashes computes a hash of each row over all fields except excludeFieldIDs,
+// the result is deterministic across processes so rows with identical content could be deduplicated anywhere.
+func (i *InsertData) RowHashes(excludeFieldIDs []FieldID) ([]uint64, error) {
+	excluded := typeutil.NewSet(excludeFieldIDs...)
+	// hash fields in a fixed order
+	fields := lo.Filter(i.SortedFields(), func(field SortedField, _ int) bool {
+		return !excluded.Contain(field.FieldID)
+	})
+	if len(fields) == 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("no field to hash")
+	}
+
+	rowNum := fields[0].Data.RowNum()
+	for _, field := range fields {
+		if field.Data.RowNum() != rowNum {
+			return nil, merr.WrapErrParameterInvalidMsg("row num of field %d not match, expected %d, actual %d", field.FieldID, rowNum, field.Data.RowNum())
+		}
+	}
+
+	hashes := make([]uint64, 0, rowNum)
+	buf := make([]byte, 0, 64)
+	for row := 0; row < rowNum; row++ {
+		h := fnv.New64a()
+		for _, field := range fields {
+			buf = binary.LittleEndian.AppendUint64(buf[:0], uint64(field.FieldID))
+			value, err := encodeRowValue(buf, field.Data.GetRow(row))
+			if err != nil {
+				return nil, err
+			}
+			h.Write(value)
+		}
+		hashes = append(hashes, h.Sum64())
+	}
+	return hashes, nil
+}
+
+// Equal returns whether other has the same fields as i, and every field has identical rows,
+// vectors and json are compared byte by byte. Two InsertData without any row are equal regardless of fields.
+func (i *InsertData) Equal(other *InsertData) bool {
+	noRow := func(data *InsertData) bool {
+		return data == nil || lo.EveryBy(lo.Values(data.Data), func(fieldData FieldData) bool { return fieldData.RowNum() == 0 })
+	}
+	if noRow(i) || noRow(other) {
+		return noRow(i) && noRow(other)
+	}
+	if len(i.Data) != len(other.Data) {
+		return false
+	}
+	for fieldID, fieldData := range i.Data {
+		otherData, ok := other.Data[fieldID]
+		if !ok || reflect.TypeOf(fieldData) != reflect.TypeOf(otherData) ||
+			getFieldDataDim(fieldData) != getFieldDataDim(otherData) || fieldData.RowNum() != otherData.RowNum() {
+			return false
+		}
+		for row := 0; row < fieldData.RowNum(); row++ {
+			if !rowValueEqual(fieldData.GetRow(row), otherData.GetRow(row)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rowValueEqual compares two row values by their binary form.
+func rowValueEqual(a, b any) bool {
+	if arrayA, ok := a.(*schemapb.ScalarField); ok {
+		arrayB, ok := b.(*schemapb.ScalarField)
+		return ok && proto.Equal(arrayA, arrayB)
+	}
+	encodedA, errA := encodeRowValue(nil, a)
+	encodedB, errB := encodeRowValue(nil, b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(encodedA, encodedB)
+}
+
+// encodeRowValue appends the length prefixed binary form of row value to buf.
+func encodeRowValue(buf []byte, value any) ([]byte, error) {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0), nil
+	case bool, int8, int16, int32, int64, float32, float64, []float32, []int8:
+		w := bytes.NewBuffer(make([]byte, 0, binary.Size(v)))
+		if err := binary.Write(w, common.Endian, v); err != nil {
+			return nil, err
+		}
+		data = w.Bytes()
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case *schemapb.ScalarField:
+		var err error
+		data, err = proto.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported row value type %T", value)
+	}
+	buf = append(buf, 1)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...), nil
+}
+
+// FilterExpired returns a new InsertData without rows expired at now, i.e. rows with timestamp older than now minus ttl,
+// and the number of dropped rows. No row expires if ttl is not positive.
+func (i *InsertData) FilterExpired(now typeutil.Timestamp, ttl time.Duration) (*InsertData, int, error) {
+	tsData, ok := i.Data[common.TimeStampField].(*Int64FieldData)
+	if !ok {
+		return nil, 0, merr.WrapErrParameterInvalidMsg("timestamp field not found")
+	}
+	if ttl <= 0 {
+		return i, 0, nil
+	}
+
+	expireTime := tsoutil.PhysicalTime(now).Add(-ttl)
+	result, err := i.FilterRows(func(row int) bool {
+		return !tsoutil.PhysicalTime(uint64(tsData.Data[row])).Before(expireTime)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, tsData.RowNum() - result.Data[common.TimeStampField].RowNum(), nil
+}
+
+// ValidateVectorField checks that every row of a vector field is not null and has expectedDim,
+// the returned error reports the first offending row.
+func (i *InsertData) ValidateVectorField(fieldID FieldID, expectedDim int) error {
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	vectorData := fieldData
+	nullable, isNullable := fieldData.(*NullableFieldData)
+	if isNullable {
+		vectorData = nullable.FieldData
+	}
+
+	// length of data and the length of each row, counted in the unit of data slice
+	var dataLen, rowLen int
+	switch data := vectorData.(type) {
+	case *FloatVectorFieldData:
+		dataLen, rowLen = len(data.Data), data.Dim
+	case *QuantizedVectorFieldData:
+		dataLen, rowLen = len(data.Data), data.Dim
+	case *BinaryVectorFieldData:
+		dataLen, rowLen = len(data.Data), data.Dim/8
+	case *Float16VectorFieldData:
+		dataLen, rowLen = len(data.Data), data.Dim*2
+	default:
+		return merr.WrapErrParameterInvalidMsg("field %d is not vector, type %T", fieldID, fieldData)
+	}
+
+	dim := getFieldDataDim(vectorData)
+	if fieldData.RowNum() > 0 && dim != expectedDim {
+		return merr.WrapErrParameterInvalidMsg("row 0 of field %d has dim %d, expected %d", fieldID, dim, expectedDim)
+	}
+	if rowLen > 0 && dataLen%rowLen != 0 {
+		return merr.WrapErrParameterInvalidMsg("row %d of field %d is incomplete, dim %d expected", dataLen/rowLen, fieldID, expectedDim)
+	}
+	if isNullable {
+		for row := 0; row < nullable.RowNum(); row++ {
+			if !nullable.IsValid(row) {
+				return merr.WrapErrParameterInvalidMsg("row %d of field %d is null", row, fieldID)
+			}
+		}
+	}
+	return nil
+}
+
+// ColumnDowncast returns the values of an integer field as a slice of the narrower targetType,
+// which could be Int8, Int16, Int32 or Int64. It fails if any value does not fit in targetType.
+func (i *InsertData) ColumnDowncast(fieldID FieldID, targetType schemapb.DataType) (interface{}, error) {
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	var values []int64
+	switch data := fieldData.(type) {
+	case *Int8FieldData:
+		values = lo.Map(data.Data, func(v int8, _ int) int64 { return int64(v) })
+	case *Int16FieldData:
+		values = lo.Map(data.Data, func(v int16, _ int) int64 { return int64(v) })
+	case *Int32FieldData:
+		values = lo.Map(data.Data, func(v int32, _ int) int64 { return int64(v) })
+	case *Int64FieldData:
+		values = data.Data
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("field %d is not integer, type %T", fieldID, fieldData)
+	}
+
+	switch targetType {
+	case schemapb.DataType_Int8:
+		return downcastInts[int8](fieldID, values, math.MinInt8, math.MaxInt8)
+	case schemapb.DataType_Int16:
+		return downcastInts[int16](fieldID, values, math.MinInt16, math.MaxInt16)
+	case schemapb.DataType_Int32:
+		return downcastInts[int32](fieldID, values, math.MinInt32, math.MaxInt32)
+	case schemapb.DataType_Int64:
+		return append([]int64(nil), values...), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported downcast target type %s", targetType.String())
+	}
+}
+
+func downcastInts[T int8 | int16 | int32](fieldID FieldID, values []int64, minVal, maxVal int64) ([]T, error) {
+	result := make([]T, 0, len(values))
+	for row, v := range values {
+		if v < minVal || v > maxVal {
+			return nil, merr.WrapErrParameterInvalidMsg("value %d of row %d in field %d out of range [%d, %d]",
+				v, row, fieldID, minVal, maxVal)
+		}
+		result = append(result, T(v))
+	}
+	return result, nil
+}
+
+// RowView provides read access to a single row of InsertData.
+type RowView struct {
+	data *InsertData
+	row  int
+}
+
+// Index returns the offset of the row.
+func (v RowView) Index() int { return v.row }
+
+// Get returns the value of fieldID in the row, false if the field does not exist.
+func (v RowView) Get(fieldID FieldID) (any, bool) {
+	fieldData, ok := v.data.Data[fieldID]
+	if !ok || v.row >= fieldData.RowNum() {
+		return nil, false
+	}
+	return fieldData.GetRow(v.row), true
+}
+
+// GenerateDeletes returns the delete records of the rows which match returns true,
+// each record consists of the primary key and the timestamp of the row.
+func (i *InsertData) GenerateDeletes(pkFieldID, tsFieldID FieldID, match func(RowView) bool) (*DeleteData, error) {
+	pkData, ok := i.Data[pkFieldID]
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("pk field %d not found", pkFieldID)
+	}
+	tsFieldData, ok := i.Data[tsFieldID]
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("timestamp field %d not found", tsFieldID)
+	}
+	tsData, ok := tsFieldData.(*Int64FieldData)
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("timestamp field %d is not int64, type %T", tsFieldID, tsFieldData)
+	}
+	if pkData.RowNum() != tsData.RowNum() {
+		return nil, merr.WrapErrParameterInvalidMsg("row num of pk field %d and timestamp field %d not match", pkFieldID, tsFieldID)
+	}
+
+	var pkOf func(row int) PrimaryKey
+	switch pkData := pkData.(type) {
+	case *Int64FieldData:
+		pkOf = func(row int) PrimaryKey { return NewInt64PrimaryKey(pkData.Data[row]) }
+	case *StringFieldData:
+		pkOf = func(row int) PrimaryKey { return NewVarCharPrimaryKey(pkData.Data[row]) }
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("pk field %d is neither int64 nor varchar, type %T", pkFieldID, pkData)
+	}
+
+	deleteData := &DeleteData{}
+	for row := 0; row < pkData.RowNum(); row++ {
+		if match(RowView{data: i, row: row}) {
+			deleteData.Append(pkOf(row), Timestamp(tsData.Data[row]))
+		}
+	}
+	return deleteData, nil
 }
 
-func NewInsertData(schema *schemapb.CollectionSchema) (*InsertData, error) {
+// GetPrimaryKeys returns the primary keys of all rows, the primary key field is located by schema.
+func (i *InsertData) GetPrimaryKeys(schema *schemapb.CollectionSchema) (PrimaryKeys, error) {
 	if schema == nil {
-		return nil, fmt.Errorf("Nil input schema")
+		return nil, merr.WrapErrParameterInvalidMsg("nil schema")
 	}
+	pkField, err := typeutil.GetPrimaryFieldSchema(schema)
+	if err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg(err.Error())
+	}
+	pkData, ok := i.Data[pkField.GetFieldID()]
+	if !ok {
+		return nil, merr.WrapErrParameterInvalidMsg("pk field %d not found", pkField.GetFieldID())
+	}
+	switch pkData := pkData.(type) {
+	case *Int64FieldData:
+		return NewInt64PrimaryKeys(pkData.Data...), nil
+	case *StringFieldData:
+		return NewVarcharPrimaryKeys(pkData.Data...), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("pk field %d is neither int64 nor varchar, type %T", pkField.GetFieldID(), pkData)
+	}
+}
 
-	idata := &InsertData{
-		Data: make(map[FieldID]FieldData),
+// Merge appends all rows of other into i with the native merge of each field,
+// the two shall have identical field sets and types. Nothing happens if other has no row.
+func (i *InsertData) Merge(other *InsertData) error {
+	if other == nil || lo.EveryBy(lo.Values(other.Data), func(fieldData FieldData) bool { return fieldData.RowNum() == 0 }) {
+		return nil
+	}
+	if len(other.Data) != len(i.Data) {
+		return merr.WrapErrParameterInvalidMsg("field num not match, expected %d, actual %d", len(i.Data), len(other.Data))
+	}
+	for fieldID, fieldData := range other.Data {
+		target, ok := i.Data[fieldID]
+		if !ok {
+			return merr.WrapErrParameterInvalidMsg("field %d not found in target", fieldID)
+		}
+		if err := checkMergeColumn(fieldID, target, fieldData); err != nil {
+			return err
+		}
 	}
 
-	for _, fSchema := range schema.Fields {
-		fieldData, err := NewFieldData(fSchema.DataType, fSchema)
-		if err != nil {
-			return nil, err
+	for fieldID, fieldData := range other.Data {
+		if err := i.mergeColumn(fieldID, fieldData); err != nil {
+			return err
 		}
-		idata.Data[fSchema.FieldID] = fieldData
 	}
-	return idata, nil
+	i.Infos = append(i.Infos, other.Infos...)
+	return nil
 }
 
-func (iData *InsertData) IsEmpty() bool {
-	if iData == nil {
-		return true
+// checkMergeColumn checks fieldData could be merged into target, which shall have the same type and dim.
+func checkMergeColumn(fieldID FieldID, target, fieldData FieldData) error {
+	if reflect.TypeOf(target) != reflect.TypeOf(fieldData) || getFieldDataDim(target) != getFieldDataDim(fieldData) {
+		return merr.WrapErrParameterInvalidMsg("field %d not match, expected %T(dim %d), actual %T(dim %d)",
+			fieldID, target, getFieldDataDim(target), fieldData, getFieldDataDim(fieldData))
 	}
+	if arrayData, ok := target.(*ArrayFieldData); ok && arrayData.ElementType != fieldData.(*ArrayFieldData).ElementType {
+		return merr.WrapErrParameterInvalidMsg("element type of field %d not match, expected %s, actual %s",
+			fieldID, arrayData.ElementType.String(), fieldData.(*ArrayFieldData).ElementType.String())
+	}
+	return nil
+}
 
-	timeFieldData, ok := iData.Data[common.TimeStampField]
-	return (!ok) || (timeFieldData.RowNum() <= 0)
+// mergeColumn appends all rows of fieldData to the field of fieldID with the native merge if there is,
+// field types without native merge are appended row by row.
+func (i *InsertData) mergeColumn(fieldID FieldID, fieldData FieldData) error {
+	before := i.Data[fieldID].RowNum()
+	MergeFieldData(i, fieldID, fieldData)
+	if i.Data[fieldID].RowNum() != before || fieldData.RowNum() == 0 {
+		return nil
+	}
+	for row := 0; row < fieldData.RowNum(); row++ {
+		if err := i.Data[fieldID].AppendRow(fieldData.GetRow(row)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (i *InsertData) GetRowNum() int {
-	if i.Data == nil || len(i.Data) == 0 {
-		return 0
+// Slice returns a new InsertData with rows [start, end) of every field,
+// columns of the result share the underlying buffers with i.
+func (i *InsertData) Slice(start, end int) (*InsertData, error) {
+	rowNum := i.GetRowNum()
+	if start < 0 || end > rowNum || start > end {
+		return nil, merr.WrapErrParameterInvalidMsg("invalid slice range [%d, %d) of %d rows", start, end, rowNum)
+	}
+	result := &InsertData{
+		Data:   make(map[FieldID]FieldData, len(i.Data)),
+		Infos:  []BlobInfo{{Length: end - start}},
+		schema: i.schema,
+	}
+	for fieldID, fieldData := range i.Data {
+		if fieldData.RowNum() != rowNum {
+			return nil, merr.WrapErrParameterInvalidMsg("row num of field %d is %d, expected %d", fieldID, fieldData.RowNum(), rowNum)
+		}
+		result.Data[fieldID] = fieldData.Slice(start, end)
 	}
+	return result, nil
+}
 
-	data, ok := i.Data[common.RowIDField]
-	if !ok {
-		return 0
+// Truncate keeps the first rowNum rows of all fields and drops the rest, fields are aligned after truncating.
+// The kept rows are capped slices of the original buffers, so later appends never overwrite the dropped rows.
+func (i *InsertData) Truncate(rowNum int) error {
+	if rowNum < 0 || rowNum > i.GetRowNum() {
+		return merr.WrapErrParameterInvalidMsg("invalid truncate row num %d of %d rows", rowNum, i.GetRowNum())
+	}
+	for fieldID, fieldData := range i.Data {
+		if fieldData.RowNum() < rowNum {
+			return merr.WrapErrParameterInvalidMsg("row num of field %d is %d, less than %d", fieldID, fieldData.RowNum(), rowNum)
+		}
+	}
+	for fieldID, fieldData := range i.Data {
+		i.Data[fieldID] = fieldData.Slice(0, rowNum)
+	}
+	for fieldID, offsets := range i.missing {
+		kept := lo.Filter(offsets, func(offset int, _ int) bool { return offset < rowNum })
+		if len(kept) == 0 {
+			delete(i.missing, fieldID)
+			continue
+		}
+		i.missing[fieldID] = kept
 	}
+	return nil
+}
 
-	return data.RowNum()
+// MergeWithFields appends the rows of other into i, only the fields in keepFields are merged and kept,
+// other fields are dropped from i without copying. Kept fields shall be aligned in both i and other.
+func (i *InsertData) MergeWithFields(other *InsertData, keepFields []FieldID) error {
+	if other == nil {
+		return merr.WrapErrParameterInvalidMsg("merge with nil insert data")
+	}
+	rowNum := func(data *InsertData, name string) (int, error) {
+		num := -1
+		for _, fieldID := range keepFields {
+			fieldRows := 0
+			if fieldData, ok := data.Data[fieldID]; ok {
+				fieldRows = fieldData.RowNum()
+			} else if name == "other" {
+				return 0, merr.WrapErrParameterInvalidMsg("kept field %d not found in other", fieldID)
+			}
+			if num >= 0 && fieldRows != num {
+				return 0, merr.WrapErrParameterInvalidMsg("kept fields of %s not aligned, field %d has %d rows, expected %d",
+					name, fieldID, fieldRows, num)
+			}
+			num = fieldRows
+		}
+		return num, nil
+	}
+	if _, err := rowNum(i, "target"); err != nil {
+		return err
+	}
+	if _, err := rowNum(other, "other"); err != nil {
+		return err
+	}
+
+	// kept fields missing in i start empty, all checks are done before i is changed
+	targets := make(map[FieldID]FieldData, len(keepFields))
+	for _, fieldID := range keepFields {
+		fieldData := other.Data[fieldID]
+		target, ok := i.Data[fieldID]
+		if !ok {
+			var err error
+			if target, err = newEmptyFieldDataLike(fieldData); err != nil {
+				return errors.Wrapf(err, "failed to create kept field %d", fieldID)
+			}
+		}
+		if err := checkMergeColumn(fieldID, target, fieldData); err != nil {
+			return err
+		}
+		targets[fieldID] = target
+	}
+
+	for fieldID := range i.Data {
+		if _, ok := targets[fieldID]; !ok {
+			delete(i.Data, fieldID)
+		}
+	}
+	for fieldID, target := range targets {
+		i.Data[fieldID] = target
+	}
+	for _, fieldID := range keepFields {
+		if err := i.mergeColumn(fieldID, other.Data[fieldID]); err != nil {
+			return err
+		}
+	}
+	i.Infos = append(i.Infos, other.Infos...)
+	return nil
 }
 
-func (i *InsertData) GetMemorySize() int {
-	var size int
-	if i.Data == nil || len(i.Data) == 0 {
-		return size
+// FilterRows returns a new InsertData with the rows which keep returns true, keep is called once for each row.
+// The same rows are kept in every field, so vectors and arrays are filtered by row instead of element.
+func (i *InsertData) FilterRows(keep func(row int) bool) (*InsertData, error) {
+	rowNum := -1
+	for fieldID, fieldData := range i.Data {
+		if rowNum < 0 {
+			rowNum = fieldData.RowNum()
+		}
+		if fieldData.RowNum() != rowNum {
+			return nil, merr.WrapErrParameterInvalidMsg("row num of field %d is %d, expected %d", fieldID, fieldData.RowNum(), rowNum)
+		}
+	}
+	var kept []int
+	for row := 0; row < rowNum; row++ {
+		if keep(row) {
+			kept = append(kept, row)
+		}
 	}
 
-	for _, data := range i.Data {
-		size += data.GetMemorySize()
+	result := &InsertData{
+		Data:   make(map[FieldID]FieldData, len(i.Data)),
+		Infos:  i.Infos,
+		schema: i.schema,
+	}
+	for fieldID, fieldData := range i.Data {
+		filtered, err := newEmptyFieldDataLike(fieldData)
+		if err != nil {
+			return nil, err
+		}
+		reserveFieldData(filtered, len(kept))
+		for _, row := range kept {
+			if err := filtered.AppendRow(fieldData.GetRow(row)); err != nil {
+				return nil, err
+			}
+		}
+		result.Data[fieldID] = filtered
 	}
+	return result, nil
+}
 
-	return size
+// SortByField reorders the rows of all fields in ascending order of the scalar field fieldID,
+// rows with equal keys keep their relative order. Vector, array and JSON fields could not be the sort key.
+func (i *InsertData) SortByField(fieldID FieldID) error {
+	keyData, ok := i.Data[fieldID]
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	var less func(a, b int) bool
+	switch data := keyData.(type) {
+	case *BoolFieldData:
+		less = func(a, b int) bool { return !data.Data[a] && data.Data[b] }
+	case *Int8FieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	case *Int16FieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	case *Int32FieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	case *Int64FieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	case *FloatFieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	case *DoubleFieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	case *StringFieldData:
+		less = func(a, b int) bool { return data.Data[a] < data.Data[b] }
+	default:
+		return merr.WrapErrParameterInvalidMsg("field %d of type %T could not be sort key", fieldID, keyData)
+	}
+
+	rowNum := keyData.RowNum()
+	perm := make([]int, rowNum)
+	for row := range perm {
+		perm[row] = row
+	}
+	sort.SliceStable(perm, func(a, b int) bool { return less(perm[a], perm[b]) })
+
+	sorted := make(map[FieldID]FieldData, len(i.Data))
+	for id, fieldData := range i.Data {
+		if fieldData.RowNum() != rowNum {
+			return merr.WrapErrParameterInvalidMsg("row num of field %d is %d, expected %d", id, fieldData.RowNum(), rowNum)
+		}
+		permuted, err := newEmptyFieldDataLike(fieldData)
+		if err != nil {
+			return errors.Wrapf(err, "failed to sort field %d", id)
+		}
+		for _, row := range perm {
+			if err := permuted.AppendRow(fieldData.GetRow(row)); err != nil {
+				return errors.Wrapf(err, "failed to sort field %d", id)
+			}
+		}
+		sorted[id] = permuted
+	}
+	// columns are replaced only after all succeed, so that i is untouched on error
+	i.Data = sorted
+	return nil
 }
 
-func (i *InsertData) Append(row map[FieldID]interface{}) error {
-	for fID, v := range row {
-		field, ok := i.Data[fID]
+// Clone returns a deep copy of i, the clone shares no column buffer with i
+// so that either one could be mutated afterwards without affecting the other.
+// Columns of FieldData types unknown to storage are sliced instead, which share the buffers with i.
+func (i *InsertData) Clone() *InsertData {
+	if i == nil {
+		return nil
+	}
+	clone := &InsertData{
+		Data:   make(map[FieldID]FieldData, len(i.Data)),
+		Infos:  append([]BlobInfo(nil), i.Infos...),
+		schema: i.schema,
+	}
+	for fieldID, fieldData := range i.Data {
+		clone.Data[fieldID] = cloneFieldData(fieldData)
+	}
+	return clone
+}
+
+// SelectFields returns a new InsertData with deep copies of the fields in fieldIDs only.
+func (i *InsertData) SelectFields(fieldIDs []FieldID) (*InsertData, error) {
+	result := &InsertData{
+		Data:   make(map[FieldID]FieldData, len(fieldIDs)),
+		Infos:  append([]BlobInfo(nil), i.Infos...),
+		schema: i.schema,
+	}
+	for _, fieldID := range fieldIDs {
+		fieldData, ok := i.Data[fieldID]
 		if !ok {
-			return fmt.Errorf("Missing field when appending row, got %d", fID)
+			return nil, merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
 		}
+		result.Data[fieldID] = cloneFieldData(fieldData)
+	}
+	return result, nil
+}
 
-		if err := field.AppendRow(v); err != nil {
-			return err
+func cloneFieldData(data FieldData) FieldData {
+	switch data := data.(type) {
+	case *BoolFieldData:
+		return &BoolFieldData{Data: append([]bool(nil), data.Data...)}
+	case *Int8FieldData:
+		return &Int8FieldData{Data: append([]int8(nil), data.Data...)}
+	case *Int16FieldData:
+		return &Int16FieldData{Data: append([]int16(nil), data.Data...)}
+	case *Int32FieldData:
+		return &Int32FieldData{Data: append([]int32(nil), data.Data...)}
+	case *Int64FieldData:
+		return &Int64FieldData{Data: append([]int64(nil), data.Data...)}
+	case *FloatFieldData:
+		return &FloatFieldData{Data: append([]float32(nil), data.Data...)}
+	case *DoubleFieldData:
+		return &DoubleFieldData{Data: append([]float64(nil), data.Data...)}
+	case *StringFieldData:
+		return &StringFieldData{Data: append([]string(nil), data.Data...)}
+	case *ArrayFieldData:
+		rows := make([]*schemapb.ScalarField, 0, len(data.Data))
+		for _, row := range data.Data {
+			rows = append(rows, proto.Clone(row).(*schemapb.ScalarField))
+		}
+		return &ArrayFieldData{ElementType: data.ElementType, Data: rows}
+	case *JSONFieldData:
+		return &JSONFieldData{Data: cloneByteRows(data.Data)}
+	case *BinaryVectorFieldData:
+		return &BinaryVectorFieldData{Data: append([]byte(nil), data.Data...), Dim: data.Dim, inferDim: data.inferDim}
+	case *FloatVectorFieldData:
+		return &FloatVectorFieldData{Data: append([]float32(nil), data.Data...), Dim: data.Dim, inferDim: data.inferDim, rejectNonFinite: data.rejectNonFinite}
+	case *Float16VectorFieldData:
+		return &Float16VectorFieldData{Data: append([]byte(nil), data.Data...), Dim: data.Dim, inferDim: data.inferDim}
+	case *QuantizedVectorFieldData:
+		return &QuantizedVectorFieldData{Data: append([]int8(nil), data.Data...), Dim: data.Dim, Scale: data.Scale, Offset: data.Offset}
+	case *ContiguousArrayFieldData:
+		return data.clone()
+	case *NullableFieldData:
+		return &NullableFieldData{
+			FieldData: cloneFieldData(data.FieldData),
+			validity:  append([]uint64(nil), data.validity...),
+			ranks:     append([]int(nil), data.ranks...),
+			rowNum:    data.rowNum,
 		}
+	case *LazyStringFieldData:
+		return data.clone()
+	case *LazyJSONFieldData:
+		return data.clone()
+	default:
+		// nothing to copy the rows with, the slice shares the buffer with data
+		return data.Slice(0, data.RowNum())
 	}
+}
 
-	return nil
+func cloneByteRows(rows [][]byte) [][]byte {
+	cloned := make([][]byte, 0, len(rows))
+	for _, row := range rows {
+		cloned = append(cloned, append([]byte(nil), row...))
+	}
+	return cloned
+}
+
+// newEmptyFieldDataLike returns an empty FieldData with the same type and dim as data,
+// lazily decompressed columns get the plain column type since there is nothing to decompress.
+func newEmptyFieldDataLike(data FieldData) (FieldData, error) {
+	switch data := data.(type) {
+	case *BoolFieldData:
+		return &BoolFieldData{}, nil
+	case *Int8FieldData:
+		return &Int8FieldData{}, nil
+	case *Int16FieldData:
+		return &Int16FieldData{}, nil
+	case *Int32FieldData:
+		return &Int32FieldData{}, nil
+	case *Int64FieldData:
+		return &Int64FieldData{}, nil
+	case *FloatFieldData:
+		return &FloatFieldData{}, nil
+	case *DoubleFieldData:
+		return &DoubleFieldData{}, nil
+	case *StringFieldData:
+		return &StringFieldData{}, nil
+	case *ArrayFieldData:
+		return &ArrayFieldData{ElementType: data.ElementType}, nil
+	case *JSONFieldData:
+		return &JSONFieldData{}, nil
+	case *BinaryVectorFieldData:
+		return &BinaryVectorFieldData{Dim: data.Dim}, nil
+	case *FloatVectorFieldData:
+		return &FloatVectorFieldData{Dim: data.Dim, rejectNonFinite: data.rejectNonFinite}, nil
+	case *Float16VectorFieldData:
+		return &Float16VectorFieldData{Dim: data.Dim}, nil
+	case *QuantizedVectorFieldData:
+		return &QuantizedVectorFieldData{Dim: data.Dim, Scale: data.Scale, Offset: data.Offset}, nil
+	case *ContiguousArrayFieldData:
+		return NewContiguousArrayFieldData(data.ElementType), nil
+	case *LazyStringFieldData:
+		return &StringFieldData{}, nil
+	case *LazyJSONFieldData:
+		return &JSONFieldData{}, nil
+	case *NullableFieldData:
+		inner, err := newEmptyFieldDataLike(data.FieldData)
+		if err != nil {
+			return nil, err
+		}
+		return NewNullableFieldData(inner), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported field data type %T", data)
+	}
 }
 
 // FieldData defines field data interface
@@ -121,6 +1595,12 @@ type FieldData interface {
 	RowNum() int
 	GetRow(i int) any
 	AppendRow(row interface{}) error
+	// Slice returns rows [start, end) which share the underlying buffer with the column,
+	// appending to the returned FieldData never overwrites the column.
+	Slice(start, end int) FieldData
+	// GetRowSize returns the memory size of the i-th row, the sum of all rows is
+	// GetMemorySize without the overhead of the column, e.g. the dim of vectors or the validity bitmap.
+	GetRowSize(i int) int
 }
 
 func NewFieldData(dataType schemapb.DataType, fieldSchema *schemapb.FieldSchema) (FieldData, error) {
@@ -239,14 +1719,22 @@ type JSONFieldData struct {
 type BinaryVectorFieldData struct {
 	Data []byte
 	Dim  int
+	// inferDim means Dim is set by the first appended row
+	inferDim bool
 }
 type FloatVectorFieldData struct {
 	Data []float32
 	Dim  int
+	// inferDim means Dim is set by the first appended row
+	inferDim bool
+	// rejectNonFinite makes AppendRow reject rows containing NaN or Inf
+	rejectNonFinite bool
 }
 type Float16VectorFieldData struct {
 	Data []byte
 	Dim  int
+	// inferDim means Dim is set by the first appended row
+	inferDim bool
 }
 
 // RowNum implements FieldData.RowNum
@@ -283,10 +1771,74 @@ func (data *FloatVectorFieldData) GetRow(i int) interface{} {
 	return data.Data[i*data.Dim : (i+1)*data.Dim]
 }
 
+// GetRowView returns the i-th vector as a sub-slice of the backing array, which saves the allocation
+// of boxing it in GetRow when scanning. The view is read-only and invalidated by appends to data.
+func (data *FloatVectorFieldData) GetRowView(i int) []float32 {
+	return data.Data[i*data.Dim : (i+1)*data.Dim : (i+1)*data.Dim]
+}
+
 func (data *Float16VectorFieldData) GetRow(i int) interface{} {
 	return data.Data[i*data.Dim*2 : (i+1)*data.Dim*2]
 }
 
+// Slice implements FieldData.Slice
+func (data *BoolFieldData) Slice(start, end int) FieldData {
+	return &BoolFieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *Int8FieldData) Slice(start, end int) FieldData {
+	return &Int8FieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *Int16FieldData) Slice(start, end int) FieldData {
+	return &Int16FieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *Int32FieldData) Slice(start, end int) FieldData {
+	return &Int32FieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *Int64FieldData) Slice(start, end int) FieldData {
+	return &Int64FieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *FloatFieldData) Slice(start, end int) FieldData {
+	return &FloatFieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *DoubleFieldData) Slice(start, end int) FieldData {
+	return &DoubleFieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *StringFieldData) Slice(start, end int) FieldData {
+	return &StringFieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *ArrayFieldData) Slice(start, end int) FieldData {
+	return &ArrayFieldData{ElementType: data.ElementType, Data: data.Data[start:end:end]}
+}
+
+func (data *JSONFieldData) Slice(start, end int) FieldData {
+	return &JSONFieldData{Data: data.Data[start:end:end]}
+}
+
+func (data *BinaryVectorFieldData) Slice(start, end int) FieldData {
+	return &BinaryVectorFieldData{Data: data.Data[start*data.Dim/8 : end*data.Dim/8 : end*data.Dim/8], Dim: data.Dim, inferDim: data.inferDim}
+}
+
+func (data *FloatVectorFieldData) Slice(start, end int) FieldData {
+	return &FloatVectorFieldData{
+		Data:            data.Data[start*data.Dim : end*data.Dim : end*data.Dim],
+		Dim:             data.Dim,
+		inferDim:        data.inferDim,
+		rejectNonFinite: data.rejectNonFinite,
+	}
+}
+
+func (data *Float16VectorFieldData) Slice(start, end int) FieldData {
+	return &Float16VectorFieldData{Data: data.Data[start*data.Dim*2 : end*data.Dim*2 : end*data.Dim*2], Dim: data.Dim, inferDim: data.inferDim}
+}
+
 // AppendRow implements FieldData.AppendRow
 func (data *BoolFieldData) AppendRow(row interface{}) error {
 	v, ok := row.(bool)
@@ -380,31 +1932,169 @@ func (data *JSONFieldData) AppendRow(row interface{}) error {
 
 func (data *BinaryVectorFieldData) AppendRow(row interface{}) error {
 	v, ok := row.([]byte)
-	if !ok || len(v) != data.Dim/8 {
+	if ok && data.inferDim && data.Dim == 0 && len(v) > 0 {
+		data.Dim = len(v) * 8
+	}
+	if !ok {
 		return merr.WrapErrParameterInvalid("[]byte", row, "Wrong row type")
 	}
+	if len(v) != data.Dim/8 {
+		return errVectorDimNotMatch(data.Dim, len(v)*8)
+	}
 	data.Data = append(data.Data, v...)
 	return nil
 }
 
 func (data *FloatVectorFieldData) AppendRow(row interface{}) error {
 	v, ok := row.([]float32)
-	if !ok || len(v) != data.Dim {
+	if ok && data.inferDim && data.Dim == 0 && len(v) > 0 {
+		data.Dim = len(v)
+	}
+	if !ok {
 		return merr.WrapErrParameterInvalid("[]float32", row, "Wrong row type")
 	}
+	if len(v) != data.Dim {
+		return errVectorDimNotMatch(data.Dim, len(v))
+	}
+	if data.rejectNonFinite {
+		if err := checkFiniteVector(v); err != nil {
+			return err
+		}
+	}
 	data.Data = append(data.Data, v...)
 	return nil
 }
 
+// checkFiniteVector returns error if any element of v is NaN or Inf.
+func checkFiniteVector(v []float32) error {
+	for i, f := range v {
+		if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+			return merr.WrapErrParameterInvalidMsg("float vector contains %v at offset %d", f, i)
+		}
+	}
+	return nil
+}
+
 func (data *Float16VectorFieldData) AppendRow(row interface{}) error {
 	v, ok := row.([]byte)
-	if !ok || len(v) != data.Dim*2 {
+	if ok && data.inferDim && data.Dim == 0 && len(v) > 0 && len(v)%2 == 0 {
+		data.Dim = len(v) / 2
+	}
+	if !ok {
 		return merr.WrapErrParameterInvalid("[]byte", row, "Wrong row type")
 	}
+	if len(v) != data.Dim*2 {
+		return errVectorDimNotMatch(data.Dim, len(v)/2)
+	}
 	data.Data = append(data.Data, v...)
 	return nil
 }
 
+// BulkAppender is implemented by the field data which could append many rows from a typed slice at once.
+type BulkAppender interface {
+	// AppendRows appends all rows in the typed slice rows, e.g. []int64 for Int64FieldData and
+	// the flattened []float32 of n*dim elements for FloatVectorFieldData. Nothing is appended if rows is invalid.
+	AppendRows(rows interface{}) error
+}
+
+// appendTypedRows appends rows to dst if rows is of []T.
+func appendTypedRows[T any](dst *[]T, rows interface{}) error {
+	v, ok := rows.([]T)
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("wrong rows type %T, expected %T", rows, *dst)
+	}
+	*dst = append(*dst, v...)
+	return nil
+}
+
+// checkFlattenedVectors checks the flattened vectors of length elements consist of whole rows of width elements.
+func checkFlattenedVectors(length, width int) error {
+	if width <= 0 {
+		return merr.WrapErrParameterInvalidMsg("vector dim is unknown")
+	}
+	if length%width != 0 {
+		return merr.WrapErrParameterInvalidMsg("length %d of flattened vectors is not a multiple of %d", length, width)
+	}
+	return nil
+}
+
+// AppendRows implements BulkAppender.AppendRows
+func (data *BoolFieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *Int8FieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *Int16FieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *Int32FieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *Int64FieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *FloatFieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *DoubleFieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *StringFieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *ArrayFieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *JSONFieldData) AppendRows(rows interface{}) error {
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *BinaryVectorFieldData) AppendRows(rows interface{}) error {
+	if v, ok := rows.([]byte); ok {
+		if err := checkFlattenedVectors(len(v), data.Dim/8); err != nil {
+			return err
+		}
+	}
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *FloatVectorFieldData) AppendRows(rows interface{}) error {
+	if v, ok := rows.([]float32); ok {
+		if err := checkFlattenedVectors(len(v), data.Dim); err != nil {
+			return err
+		}
+		if data.rejectNonFinite {
+			if err := checkFiniteVector(v); err != nil {
+				return err
+			}
+		}
+	}
+	return appendTypedRows(&data.Data, rows)
+}
+
+func (data *Float16VectorFieldData) AppendRows(rows interface{}) error {
+	if v, ok := rows.([]byte); ok {
+		if err := checkFlattenedVectors(len(v), data.Dim*2); err != nil {
+			return err
+		}
+	}
+	return appendTypedRows(&data.Data, rows)
+}
+
+func errVectorDimNotMatch(expected, actual int) error {
+	return merr.WrapErrParameterInvalidMsg("vector dim not match, expected %d, actual %d", expected, actual)
+}
+
 // GetMemorySize implements FieldData.GetMemorySize
 func (data *BoolFieldData) GetMemorySize() int          { return binary.Size(data.Data) }
 func (data *Int8FieldData) GetMemorySize() int          { return binary.Size(data.Data) }
@@ -431,25 +2121,8 @@ func (data *StringFieldData) GetMemorySize() int {
 
 func (data *ArrayFieldData) GetMemorySize() int {
 	var size int
-	for _, val := range data.Data {
-		switch data.ElementType {
-		case schemapb.DataType_Bool:
-			size += binary.Size(val.GetBoolData().GetData())
-		case schemapb.DataType_Int8:
-			size += binary.Size(val.GetIntData().GetData()) / 4
-		case schemapb.DataType_Int16:
-			size += binary.Size(val.GetIntData().GetData()) / 2
-		case schemapb.DataType_Int32:
-			size += binary.Size(val.GetIntData().GetData())
-		case schemapb.DataType_Int64:
-			size += binary.Size(val.GetLongData().GetData())
-		case schemapb.DataType_Float:
-			size += binary.Size(val.GetFloatData().GetData())
-		case schemapb.DataType_Double:
-			size += binary.Size(val.GetDoubleData().GetData())
-		case schemapb.DataType_String, schemapb.DataType_VarChar:
-			size += (&StringFieldData{Data: val.GetStringData().GetData()}).GetMemorySize()
-		}
+	for i := range data.Data {
+		size += data.GetRowSize(i)
 	}
 	return size
 }
@@ -461,3 +2134,45 @@ func (data *JSONFieldData) GetMemorySize() int {
 	}
 	return size
 }
+
+// GetRowSize implements FieldData.GetRowSize
+func (data *BoolFieldData) GetRowSize(i int) int          { return 1 }
+func (data *Int8FieldData) GetRowSize(i int) int          { return 1 }
+func (data *Int16FieldData) GetRowSize(i int) int         { return 2 }
+func (data *Int32FieldData) GetRowSize(i int) int         { return 4 }
+func (data *Int64FieldData) GetRowSize(i int) int         { return 8 }
+func (data *FloatFieldData) GetRowSize(i int) int         { return 4 }
+func (data *DoubleFieldData) GetRowSize(i int) int        { return 8 }
+func (data *BinaryVectorFieldData) GetRowSize(i int) int  { return data.Dim / 8 }
+func (data *FloatVectorFieldData) GetRowSize(i int) int   { return data.Dim * 4 }
+func (data *Float16VectorFieldData) GetRowSize(i int) int { return data.Dim * 2 }
+func (data *StringFieldData) GetRowSize(i int) int        { return len(data.Data[i]) + 16 }
+func (data *JSONFieldData) GetRowSize(i int) int          { return len(data.Data[i]) + 16 }
+
+// stringArrayOverhead is the size of the slice header holding the elements of a string array row.
+const stringArrayOverhead = 24
+
+func (data *ArrayFieldData) GetRowSize(i int) int {
+	val := data.Data[i]
+	switch data.ElementType {
+	case schemapb.DataType_Bool:
+		return binary.Size(val.GetBoolData().GetData())
+	case schemapb.DataType_Int8:
+		return binary.Size(val.GetIntData().GetData()) / 4
+	case schemapb.DataType_Int16:
+		return binary.Size(val.GetIntData().GetData()) / 2
+	case schemapb.DataType_Int32:
+		return binary.Size(val.GetIntData().GetData())
+	case schemapb.DataType_Int64:
+		return binary.Size(val.GetLongData().GetData())
+	case schemapb.DataType_Float:
+		return binary.Size(val.GetFloatData().GetData())
+	case schemapb.DataType_Double:
+		return binary.Size(val.GetDoubleData().GetData())
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		// elements are counted like StringFieldData, plus the slice holding them
+		return (&StringFieldData{Data: val.GetStringData().GetData()}).GetMemorySize() + stringArrayOverhead
+	default:
+		return 0
+	}
+}
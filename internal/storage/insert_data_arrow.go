@@ -0,0 +1,237 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"math"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/golang/protobuf/proto"
+	"github.com/samber/lo"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// arrowSupportedTypes are the data types convertible between InsertData and arrow,
+// the arrow types are the same as the ones of binlog payload, see milvusDataTypeToArrowType.
+var arrowSupportedTypes = []schemapb.DataType{
+	schemapb.DataType_Bool,
+	schemapb.DataType_Int8,
+	schemapb.DataType_Int16,
+	schemapb.DataType_Int32,
+	schemapb.DataType_Int64,
+	schemapb.DataType_Float,
+	schemapb.DataType_Double,
+	schemapb.DataType_String,
+	schemapb.DataType_VarChar,
+	schemapb.DataType_Array,
+	schemapb.DataType_JSON,
+	schemapb.DataType_BinaryVector,
+	schemapb.DataType_FloatVector,
+	schemapb.DataType_Float16Vector,
+}
+
+// ToArrow converts the fields of schema into an arrow record, columns are in schema order and named by field name.
+// Vectors are stored as FixedSizeBinary, arrays as the marshaled ScalarField and JSON as Binary.
+// The caller shall release the returned record.
+func (i *InsertData) ToArrow(schema *schemapb.CollectionSchema) (arrow.Record, error) {
+	if schema == nil {
+		return nil, merr.WrapErrParameterInvalidMsg("nil schema")
+	}
+	rowNum := i.GetRowNum()
+	fields := make([]arrow.Field, 0, len(schema.GetFields()))
+	columns := make([]arrow.Array, 0, len(schema.GetFields()))
+	defer func() {
+		for _, column := range columns {
+			column.Release()
+		}
+	}()
+	for _, field := range schema.GetFields() {
+		fieldData, ok := i.Data[field.GetFieldID()]
+		if !ok {
+			return nil, merr.WrapErrParameterInvalidMsg("field %d not found in insert data", field.GetFieldID())
+		}
+		if fieldData.RowNum() != rowNum {
+			return nil, merr.WrapErrParameterInvalidMsg("row num of field %d not match, expected %d, actual %d", field.GetFieldID(), rowNum, fieldData.RowNum())
+		}
+		column, err := fieldDataToArrow(field.GetDataType(), fieldData)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, arrow.Field{Name: field.GetName(), Type: column.DataType()})
+		columns = append(columns, column)
+	}
+	return array.NewRecord(arrow.NewSchema(fields, nil), columns, int64(rowNum)), nil
+}
+
+// FromArrow creates InsertData of schema from the record produced by ToArrow,
+// columns are looked up by field name.
+func FromArrow(schema *schemapb.CollectionSchema, record arrow.Record) (*InsertData, error) {
+	data, err := NewInsertData(schema)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range schema.GetFields() {
+		indices := record.Schema().FieldIndices(field.GetName())
+		if len(indices) != 1 {
+			return nil, merr.WrapErrParameterInvalidMsg("expect one column of field %s, got %d", field.GetName(), len(indices))
+		}
+		if err := appendArrowColumn(field.GetDataType(), record.Column(indices[0]), data.Data[field.GetFieldID()]); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func fieldDataToArrow(dataType schemapb.DataType, fieldData FieldData) (arrow.Array, error) {
+	if !lo.Contains(arrowSupportedTypes, dataType) {
+		return nil, merr.WrapErrParameterInvalidMsg("data type %s not supported by arrow conversion", dataType.String())
+	}
+	builder := array.NewBuilder(memory.DefaultAllocator, milvusDataTypeToArrowType(dataType, getFieldDataDim(fieldData)))
+	defer builder.Release()
+	builder.Reserve(fieldData.RowNum())
+	for row := 0; row < fieldData.RowNum(); row++ {
+		if err := appendArrowValue(builder, fieldData.GetRow(row)); err != nil {
+			return nil, err
+		}
+	}
+	return builder.NewArray(), nil
+}
+
+// appendArrowValue appends a row value of FieldData to the builder created by milvusDataTypeToArrowType.
+func appendArrowValue(builder array.Builder, value any) error {
+	switch builder := builder.(type) {
+	case *array.BooleanBuilder:
+		builder.Append(value.(bool))
+	case *array.Int8Builder:
+		builder.Append(value.(int8))
+	case *array.Int16Builder:
+		builder.Append(value.(int16))
+	case *array.Int32Builder:
+		builder.Append(value.(int32))
+	case *array.Int64Builder:
+		builder.Append(value.(int64))
+	case *array.Float32Builder:
+		builder.Append(value.(float32))
+	case *array.Float64Builder:
+		builder.Append(value.(float64))
+	case *array.StringBuilder:
+		builder.Append(value.(string))
+	case *array.BinaryBuilder:
+		switch value := value.(type) {
+		case *schemapb.ScalarField:
+			bytes, err := proto.Marshal(value)
+			if err != nil {
+				return err
+			}
+			builder.Append(bytes)
+		case []byte:
+			builder.Append(value)
+		default:
+			return merr.WrapErrParameterInvalidMsg("unexpected value %T of arrow builder %T", value, builder)
+		}
+	case *array.FixedSizeBinaryBuilder:
+		switch value := value.(type) {
+		case []float32:
+			bytes := make([]byte, len(value)*4)
+			for j, v := range value {
+				common.Endian.PutUint32(bytes[j*4:], math.Float32bits(v))
+			}
+			builder.Append(bytes)
+		case []byte:
+			builder.Append(value)
+		default:
+			return merr.WrapErrParameterInvalidMsg("unexpected value %T of arrow builder %T", value, builder)
+		}
+	default:
+		return merr.WrapErrParameterInvalidMsg("unexpected arrow builder %T", builder)
+	}
+	return nil
+}
+
+// appendArrowColumn appends the rows of column produced by fieldDataToArrow to fieldData.
+func appendArrowColumn(dataType schemapb.DataType, column arrow.Array, fieldData FieldData) error {
+	if !lo.Contains(arrowSupportedTypes, dataType) {
+		return merr.WrapErrParameterInvalidMsg("data type %s not supported by arrow conversion", dataType.String())
+	}
+	expected := milvusDataTypeToArrowType(dataType, getFieldDataDim(fieldData))
+	if !arrow.TypeEqual(expected, column.DataType()) {
+		return merr.WrapErrParameterInvalidMsg("arrow type %s not match data type %s, expected %s", column.DataType(), dataType.String(), expected)
+	}
+	for row := 0; row < column.Len(); row++ {
+		if column.IsNull(row) {
+			return merr.WrapErrParameterInvalidMsg("null value at row %d not supported", row)
+		}
+		value, err := arrowValue(dataType, column, row)
+		if err != nil {
+			return err
+		}
+		if err := fieldData.AppendRow(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// arrowValue returns the row value of column in the form of FieldData.GetRow.
+func arrowValue(dataType schemapb.DataType, column arrow.Array, row int) (any, error) {
+	switch column := column.(type) {
+	case *array.Boolean:
+		return column.Value(row), nil
+	case *array.Int8:
+		return column.Value(row), nil
+	case *array.Int16:
+		return column.Value(row), nil
+	case *array.Int32:
+		return column.Value(row), nil
+	case *array.Int64:
+		return column.Value(row), nil
+	case *array.Float32:
+		return column.Value(row), nil
+	case *array.Float64:
+		return column.Value(row), nil
+	case *array.String:
+		return column.Value(row), nil
+	case *array.Binary:
+		// the value is only valid during the lifetime of column
+		bytes := append([]byte(nil), column.Value(row)...)
+		if dataType == schemapb.DataType_Array {
+			value := &schemapb.ScalarField{}
+			if err := proto.Unmarshal(bytes, value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
+		return bytes, nil
+	case *array.FixedSizeBinary:
+		bytes := append([]byte(nil), column.Value(row)...)
+		if dataType == schemapb.DataType_FloatVector {
+			vector := make([]float32, len(bytes)/4)
+			for j := range vector {
+				vector[j] = math.Float32frombits(common.Endian.Uint32(bytes[j*4:]))
+			}
+			return vector, nil
+		}
+		return bytes, nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unexpected arrow array %T", column)
+	}
+}
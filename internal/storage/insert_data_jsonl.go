@@ -0,0 +1,114 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+)
+
+// ToJSONL writes at most maxRows rows as newline-delimited JSON objects, all rows are written if maxRows <= 0.
+// Fields are keyed by name if InsertData is created with schema, otherwise by field id.
+// Vectors are written as arrays, binary and float16 vectors as base64 strings, null values as null.
+func (i *InsertData) ToJSONL(w io.Writer, maxRows int) error {
+	names := make(map[FieldID]string, len(i.Data))
+	for fieldID := range i.Data {
+		names[fieldID] = strconv.FormatInt(fieldID, 10)
+	}
+	for _, field := range i.schema.GetFields() {
+		if _, ok := names[field.GetFieldID()]; ok {
+			names[field.GetFieldID()] = field.GetName()
+		}
+	}
+
+	rowNum := 0
+	for _, fieldData := range i.Data {
+		if fieldData.RowNum() > rowNum {
+			rowNum = fieldData.RowNum()
+		}
+	}
+	if maxRows > 0 && maxRows < rowNum {
+		rowNum = maxRows
+	}
+
+	encoder := json.NewEncoder(w)
+	for row := 0; row < rowNum; row++ {
+		obj := make(map[string]any, len(i.Data))
+		for fieldID, fieldData := range i.Data {
+			if row >= fieldData.RowNum() {
+				obj[names[fieldID]] = nil
+				continue
+			}
+			obj[names[fieldID]] = jsonlValue(fieldData, fieldData.GetRow(row))
+		}
+		if err := encoder.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonlValue converts row value of fieldData into the form written by ToJSONL.
+func jsonlValue(fieldData FieldData, value any) any {
+	if value == nil {
+		return nil
+	}
+	if nullable, ok := fieldData.(*NullableFieldData); ok {
+		fieldData = nullable.FieldData
+	}
+	switch fieldData.(type) {
+	case *JSONFieldData:
+		if bs := value.([]byte); json.Valid(bs) {
+			return json.RawMessage(bs)
+		}
+		return string(value.([]byte))
+	case *ArrayFieldData:
+		return scalarFieldToSlice(value.(*schemapb.ScalarField))
+	default:
+		return value
+	}
+}
+
+func scalarFieldToSlice(field *schemapb.ScalarField) any {
+	switch data := field.GetData().(type) {
+	case *schemapb.ScalarField_BoolData:
+		return data.BoolData.GetData()
+	case *schemapb.ScalarField_IntData:
+		return data.IntData.GetData()
+	case *schemapb.ScalarField_LongData:
+		return data.LongData.GetData()
+	case *schemapb.ScalarField_FloatData:
+		return data.FloatData.GetData()
+	case *schemapb.ScalarField_DoubleData:
+		return data.DoubleData.GetData()
+	case *schemapb.ScalarField_StringData:
+		return data.StringData.GetData()
+	case *schemapb.ScalarField_BytesData:
+		return data.BytesData.GetData()
+	case *schemapb.ScalarField_JsonData:
+		rows := make([]json.RawMessage, 0, len(data.JsonData.GetData()))
+		for _, bs := range data.JsonData.GetData() {
+			rows = append(rows, bs)
+		}
+		return rows
+	default:
+		return nil
+	}
+}
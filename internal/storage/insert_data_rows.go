@@ -0,0 +1,405 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// rowFormatVersion is the first byte of the buffer produced by EncodeRows.
+const rowFormatVersion byte = 1
+
+// rowFieldHeader describes a field in the row format.
+type rowFieldHeader struct {
+	fieldID     FieldID
+	dataType    schemapb.DataType
+	elementType schemapb.DataType
+	dim         int
+}
+
+// EncodeRows encodes the rows at indices into a compact row oriented buffer, which is cheaper than
+// proto columns for small batches. The layout is self-describing:
+//
+//	version | field count | (field id, data type, element type, dim) per field | row count | values row by row
+//
+// Integers are varint encoded, variable length values are length prefixed, vectors take dim sized values.
+func (i *InsertData) EncodeRows(indices []int) ([]byte, error) {
+	fields := i.SortedFields()
+
+	buf := []byte{rowFormatVersion}
+	buf = binary.AppendUvarint(buf, uint64(len(fields)))
+	headers := make([]rowFieldHeader, 0, len(fields))
+	for _, field := range fields {
+		header, err := getRowFieldHeader(field.FieldID, field.Data)
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendVarint(buf, header.fieldID)
+		buf = binary.AppendUvarint(buf, uint64(header.dataType))
+		buf = binary.AppendUvarint(buf, uint64(header.elementType))
+		buf = binary.AppendUvarint(buf, uint64(header.dim))
+		headers = append(headers, header)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(indices)))
+	for _, idx := range indices {
+		for _, header := range headers {
+			fieldData := i.Data[header.fieldID]
+			if idx < 0 || idx >= fieldData.RowNum() {
+				return nil, merr.WrapErrParameterInvalidMsg("row %d out of range of field %d, row num %d", idx, header.fieldID, fieldData.RowNum())
+			}
+			var err error
+			buf, err = appendRowValue(buf, header, fieldData.GetRow(idx))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// DecodeRows appends the rows encoded by EncodeRows. Fields of the buffer must match the existing fields,
+// nothing is appended if the buffer is malformed or mismatched.
+func (i *InsertData) DecodeRows(data []byte) error {
+	r := &rowReader{buf: data}
+	version, err := r.next(1)
+	if err != nil {
+		return err
+	}
+	if version[0] != rowFormatVersion {
+		return merr.WrapErrParameterInvalidMsg("unsupported row format version %d", version[0])
+	}
+
+	fieldNum, err := r.uvarint()
+	if err != nil {
+		return err
+	}
+	if len(i.Data) > 0 && int(fieldNum) != len(i.Data) {
+		return merr.WrapErrParameterInvalidMsg("field num not match, expected %d, actual %d", len(i.Data), fieldNum)
+	}
+	headers := make([]rowFieldHeader, 0, fieldNum)
+	decoded := make(map[FieldID]FieldData, fieldNum)
+	for n := uint64(0); n < fieldNum; n++ {
+		header, err := r.fieldHeader()
+		if err != nil {
+			return err
+		}
+		fieldData, err := newRowFieldData(header)
+		if err != nil {
+			return err
+		}
+		if len(i.Data) > 0 {
+			expected, ok := i.Data[header.fieldID]
+			if !ok {
+				return merr.WrapErrParameterInvalidMsg("field %d not found in destination", header.fieldID)
+			}
+			if reflect.TypeOf(expected) != reflect.TypeOf(fieldData) || getFieldDataDim(expected) != header.dim {
+				return merr.WrapErrParameterInvalidMsg("field %d not match, expected %T(dim %d), actual %T(dim %d)",
+					header.fieldID, expected, getFieldDataDim(expected), fieldData, header.dim)
+			}
+		}
+		headers = append(headers, header)
+		decoded[header.fieldID] = fieldData
+	}
+
+	rowNum, err := r.uvarint()
+	if err != nil {
+		return err
+	}
+	for row := uint64(0); row < rowNum; row++ {
+		for _, header := range headers {
+			value, err := r.rowValue(header)
+			if err != nil {
+				return merr.WrapErrParameterInvalidMsg("failed to decode row %d of field %d: %s", row, header.fieldID, err.Error())
+			}
+			if err := decoded[header.fieldID].AppendRow(value); err != nil {
+				return err
+			}
+		}
+	}
+	if len(r.buf) > 0 {
+		return merr.WrapErrParameterInvalidMsg("%d trailing bytes after rows", len(r.buf))
+	}
+
+	if i.Data == nil {
+		i.Data = make(map[FieldID]FieldData, len(decoded))
+	}
+	for fieldID, fieldData := range decoded {
+		if _, ok := i.Data[fieldID]; !ok {
+			i.Data[fieldID] = fieldData
+			continue
+		}
+		MergeFieldData(i, fieldID, fieldData)
+	}
+	return nil
+}
+
+func getRowFieldHeader(fieldID FieldID, data FieldData) (rowFieldHeader, error) {
+	header := rowFieldHeader{fieldID: fieldID, dim: getFieldDataDim(data)}
+	switch data := data.(type) {
+	case *BoolFieldData:
+		header.dataType = schemapb.DataType_Bool
+	case *Int8FieldData:
+		header.dataType = schemapb.DataType_Int8
+	case *Int16FieldData:
+		header.dataType = schemapb.DataType_Int16
+	case *Int32FieldData:
+		header.dataType = schemapb.DataType_Int32
+	case *Int64FieldData:
+		header.dataType = schemapb.DataType_Int64
+	case *FloatFieldData:
+		header.dataType = schemapb.DataType_Float
+	case *DoubleFieldData:
+		header.dataType = schemapb.DataType_Double
+	case *StringFieldData:
+		header.dataType = schemapb.DataType_VarChar
+	case *JSONFieldData:
+		header.dataType = schemapb.DataType_JSON
+	case *ArrayFieldData:
+		header.dataType = schemapb.DataType_Array
+		header.elementType = data.ElementType
+	case *FloatVectorFieldData:
+		header.dataType = schemapb.DataType_FloatVector
+	case *BinaryVectorFieldData:
+		header.dataType = schemapb.DataType_BinaryVector
+	case *Float16VectorFieldData:
+		header.dataType = schemapb.DataType_Float16Vector
+	default:
+		return header, merr.WrapErrParameterInvalidMsg("unsupported field data type %T of field %d", data, fieldID)
+	}
+	return header, nil
+}
+
+func newRowFieldData(header rowFieldHeader) (FieldData, error) {
+	fieldSchema := &schemapb.FieldSchema{
+		FieldID:     header.fieldID,
+		DataType:    header.dataType,
+		ElementType: header.elementType,
+		TypeParams: []*commonpb.KeyValuePair{
+			{Key: common.DimKey, Value: strconv.Itoa(header.dim)},
+		},
+	}
+	return NewFieldData(header.dataType, fieldSchema)
+}
+
+func appendRowValue(buf []byte, header rowFieldHeader, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case int8:
+		return binary.AppendVarint(buf, int64(v)), nil
+	case int16:
+		return binary.AppendVarint(buf, int64(v)), nil
+	case int32:
+		return binary.AppendVarint(buf, int64(v)), nil
+	case int64:
+		return binary.AppendVarint(buf, v), nil
+	case float32:
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(v)), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v)), nil
+	case string:
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		return append(buf, v...), nil
+	case []float32:
+		for _, f := range v {
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(f))
+		}
+		return buf, nil
+	case []byte:
+		// vectors take fixed size, only json needs the length
+		if header.dataType == schemapb.DataType_JSON {
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+		}
+		return append(buf, v...), nil
+	case *schemapb.ScalarField:
+		bs, err := proto.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(bs)))
+		return append(buf, bs...), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported row value type %T of field %d", value, header.fieldID)
+	}
+}
+
+// rowReader consumes the buffer produced by EncodeRows.
+type rowReader struct {
+	buf []byte
+}
+
+func (r *rowReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.buf) < n {
+		return nil, merr.WrapErrParameterInvalidMsg("unexpected end of row buffer")
+	}
+	bs := r.buf[:n]
+	r.buf = r.buf[n:]
+	return bs, nil
+}
+
+func (r *rowReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		return 0, merr.WrapErrParameterInvalidMsg("malformed uvarint in row buffer")
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *rowReader) varint() (int64, error) {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		return 0, merr.WrapErrParameterInvalidMsg("malformed varint in row buffer")
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *rowReader) fieldHeader() (rowFieldHeader, error) {
+	var header rowFieldHeader
+	fieldID, err := r.varint()
+	if err != nil {
+		return header, err
+	}
+	dataType, err := r.uvarint()
+	if err != nil {
+		return header, err
+	}
+	elementType, err := r.uvarint()
+	if err != nil {
+		return header, err
+	}
+	dim, err := r.uvarint()
+	if err != nil {
+		return header, err
+	}
+	return rowFieldHeader{
+		fieldID:     fieldID,
+		dataType:    schemapb.DataType(dataType),
+		elementType: schemapb.DataType(elementType),
+		dim:         int(dim),
+	}, nil
+}
+
+func (r *rowReader) lengthPrefixed() ([]byte, error) {
+	size, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(r.buf)) {
+		return nil, merr.WrapErrParameterInvalidMsg("unexpected end of row buffer")
+	}
+	return r.next(int(size))
+}
+
+func (r *rowReader) rowValue(header rowFieldHeader) (any, error) {
+	switch header.dataType {
+	case schemapb.DataType_Bool:
+		bs, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return bs[0] != 0, nil
+	case schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32, schemapb.DataType_Int64:
+		v, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		switch header.dataType {
+		case schemapb.DataType_Int8:
+			return int8(v), nil
+		case schemapb.DataType_Int16:
+			return int16(v), nil
+		case schemapb.DataType_Int32:
+			return int32(v), nil
+		default:
+			return v, nil
+		}
+	case schemapb.DataType_Float:
+		bs, err := r.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(bs)), nil
+	case schemapb.DataType_Double:
+		bs, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(bs)), nil
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		bs, err := r.lengthPrefixed()
+		if err != nil {
+			return nil, err
+		}
+		return string(bs), nil
+	case schemapb.DataType_JSON:
+		bs, err := r.lengthPrefixed()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, bs...), nil
+	case schemapb.DataType_Array:
+		bs, err := r.lengthPrefixed()
+		if err != nil {
+			return nil, err
+		}
+		scalar := &schemapb.ScalarField{}
+		if err := proto.Unmarshal(bs, scalar); err != nil {
+			return nil, err
+		}
+		return scalar, nil
+	case schemapb.DataType_FloatVector:
+		bs, err := r.next(header.dim * 4)
+		if err != nil {
+			return nil, err
+		}
+		vector := make([]float32, header.dim)
+		for idx := range vector {
+			vector[idx] = math.Float32frombits(binary.LittleEndian.Uint32(bs[idx*4:]))
+		}
+		return vector, nil
+	case schemapb.DataType_BinaryVector:
+		bs, err := r.next(header.dim / 8)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, bs...), nil
+	case schemapb.DataType_Float16Vector:
+		bs, err := r.next(header.dim * 2)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, bs...), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported data type %s", header.dataType.String())
+	}
+}
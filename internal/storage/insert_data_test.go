@@ -1,14 +1,30 @@
 package storage
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/golang/protobuf/proto"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
 	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/common"
 	"github.com/milvus-io/milvus/pkg/log"
 	"github.com/milvus-io/milvus/pkg/util/merr"
+	"github.com/milvus-io/milvus/pkg/util/tsoutil"
 )
 
 func TestInsertDataSuite(t *testing.T) {
@@ -147,6 +163,1997 @@ func (s *InsertDataSuite) TestMemorySize() {
 	s.Equal(s.iDataTwoRows.Data[Float16VectorField].GetMemorySize(), 20)
 }
 
+func (s *InsertDataSuite) TestMemorySizeByField() {
+	sizes := s.iDataTwoRows.MemorySizeByField()
+	s.Len(sizes, len(s.iDataTwoRows.Data))
+	s.Equal(36, sizes[FloatVectorField])
+	s.Equal(38, sizes[StringField])
+
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	s.Equal(s.iDataTwoRows.GetMemorySize(), total)
+
+	s.Empty((&InsertData{}).MemorySizeByField())
+}
+
+func (s *InsertDataSuite) TestSortedFields() {
+	fields := s.iDataTwoRows.SortedFields()
+	s.Len(fields, len(s.iDataTwoRows.Data))
+	for idx, field := range fields {
+		s.Same(s.iDataTwoRows.Data[field.FieldID], field.Data)
+		if idx > 0 {
+			s.Less(fields[idx-1].FieldID, field.FieldID)
+		}
+	}
+	s.Empty((&InsertData{}).SortedFields())
+
+	// same fields inserted in reverse order
+	reversed := &InsertData{Data: make(map[FieldID]FieldData)}
+	for idx := len(fields) - 1; idx >= 0; idx-- {
+		reversed.Data[fields[idx].FieldID] = fields[idx].Data
+	}
+
+	first, err := s.iDataTwoRows.EncodeRows([]int{0, 1})
+	s.NoError(err)
+	for _, data := range []*InsertData{s.iDataTwoRows, reversed} {
+		buf, err := data.EncodeRows([]int{0, 1})
+		s.NoError(err)
+		s.Equal(first, buf)
+	}
+
+	first, err = EncodeInsertData(TransportFormatMsgpack, s.iDataTwoRows)
+	s.NoError(err)
+	for _, data := range []*InsertData{s.iDataTwoRows, reversed} {
+		buf, err := EncodeInsertData(TransportFormatMsgpack, data)
+		s.NoError(err)
+		s.Equal(first, buf)
+	}
+}
+
+func (s *InsertDataSuite) TestArrow() {
+	s.Run("round trip", func() {
+		record, err := s.iDataTwoRows.ToArrow(s.schema)
+		s.Require().NoError(err)
+		defer record.Release()
+		s.EqualValues(2, record.NumRows())
+		s.EqualValues(len(s.schema.GetFields()), record.NumCols())
+
+		// through arrow ipc stream
+		var buf bytes.Buffer
+		writer := ipc.NewWriter(&buf, ipc.WithSchema(record.Schema()))
+		s.Require().NoError(writer.Write(record))
+		s.Require().NoError(writer.Close())
+		reader, err := ipc.NewReader(&buf)
+		s.Require().NoError(err)
+		defer reader.Release()
+		s.Require().True(reader.Next())
+
+		decoded, err := FromArrow(s.schema, reader.Record())
+		s.Require().NoError(err)
+		s.True(s.iDataTwoRows.Equal(decoded))
+	})
+
+	s.Run("empty", func() {
+		record, err := s.iDataEmpty.ToArrow(s.schema)
+		s.Require().NoError(err)
+		defer record.Release()
+		decoded, err := FromArrow(s.schema, record)
+		s.NoError(err)
+		s.True(decoded.IsEmpty())
+	})
+
+	s.Run("missing field", func() {
+		schema := proto.Clone(s.schema).(*schemapb.CollectionSchema)
+		schema.Fields = append(schema.Fields, &schemapb.FieldSchema{FieldID: 1000, Name: "missing", DataType: schemapb.DataType_Int64})
+		_, err := s.iDataTwoRows.ToArrow(schema)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+
+		record, err := s.iDataTwoRows.ToArrow(s.schema)
+		s.Require().NoError(err)
+		defer record.Release()
+		_, err = FromArrow(schema, record)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+	})
+
+	s.Run("type mismatch", func() {
+		record, err := s.iDataTwoRows.ToArrow(s.schema)
+		s.Require().NoError(err)
+		defer record.Release()
+		schema := proto.Clone(s.schema).(*schemapb.CollectionSchema)
+		for _, field := range schema.GetFields() {
+			if field.GetFieldID() == BoolField {
+				field.DataType = schemapb.DataType_Int8
+			}
+		}
+		_, err = FromArrow(schema, record)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+	})
+}
+
+func (s *InsertDataSuite) TestValidate() {
+	s.NoError(s.iDataTwoRows.Validate(s.schema))
+	s.NoError(s.iDataEmpty.Validate(s.schema))
+	s.ErrorIs(s.iDataTwoRows.Validate(nil), merr.ErrParameterInvalid)
+
+	s.Run("missing field", func() {
+		data := s.iDataTwoRows.Clone()
+		delete(data.Data, StringField)
+		err := data.Validate(s.schema)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, "missing")
+	})
+
+	s.Run("extra field", func() {
+		data := s.iDataTwoRows.Clone()
+		data.Data[1000] = &Int64FieldData{Data: []int64{1, 2}}
+		err := data.Validate(s.schema)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, "not found in schema")
+	})
+
+	s.Run("type mismatch", func() {
+		data := s.iDataTwoRows.Clone()
+		data.Data[BoolField] = &Int8FieldData{Data: []int8{1, 2}}
+		s.ErrorIs(data.Validate(s.schema), merr.ErrParameterInvalid)
+
+		data = s.iDataTwoRows.Clone()
+		data.Data[ArrayField] = &ArrayFieldData{ElementType: schemapb.DataType_Int64, Data: data.Data[ArrayField].(*ArrayFieldData).Data}
+		s.ErrorIs(data.Validate(s.schema), merr.ErrParameterInvalid)
+	})
+
+	s.Run("dim mismatch", func() {
+		data := s.iDataTwoRows.Clone()
+		data.Data[FloatVectorField] = &FloatVectorFieldData{Data: []float32{1, 2, 3, 4}, Dim: 2}
+		err := data.Validate(s.schema)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, "dim")
+	})
+
+	s.Run("ragged column", func() {
+		data := s.iDataTwoRows.Clone()
+		data.Data[Int64Field] = &Int64FieldData{Data: []int64{1}}
+		s.ErrorIs(data.Validate(s.schema), merr.ErrParameterInvalid)
+	})
+
+	s.Run("compacted", func() {
+		data := s.iDataTwoRows.Clone()
+		rows := []*schemapb.ScalarField{
+			{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{3, 3}}}},
+			{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{1}}}},
+		}
+		data.Data[ArrayField] = &ArrayFieldData{ElementType: schemapb.DataType_Int32, Data: rows}
+		s.Require().NoError(data.CompactArrayField(ArrayField))
+		s.NoError(data.Validate(s.schema))
+
+		codec := NewInsertCodecWithSchema(genTestCollectionMeta())
+		blobs, err := codec.Serialize(PartitionID, SegmentID, data)
+		s.Require().NoError(err)
+		_, _, result, err := codec.Deserialize(blobs)
+		s.Require().NoError(err)
+		// rows are sorted by row id, which is 3 and 1
+		s.Equal(2, result.Data[ArrayField].RowNum())
+		s.True(proto.Equal(rows[1], result.Data[ArrayField].GetRow(0).(*schemapb.ScalarField)))
+		s.True(proto.Equal(rows[0], result.Data[ArrayField].GetRow(1).(*schemapb.ScalarField)))
+	})
+
+	s.Run("unsupported by codec", func() {
+		data := s.iDataTwoRows.Clone()
+		data.Data[StringField] = NewNullableFieldData(data.Data[StringField])
+		err := data.Validate(s.schema)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, "unsupported by codec")
+
+		data = s.iDataTwoRows.Clone()
+		_, _, err = data.QuantizeVectorField(FloatVectorField)
+		s.Require().NoError(err)
+		s.ErrorContains(data.Validate(s.schema), "unsupported by codec")
+	})
+}
+
+func (s *InsertDataSuite) TestFloatVectorGetRowView() {
+	data := s.iDataTwoRows.Data[FloatVectorField].(*FloatVectorFieldData)
+	for i := 0; i < data.RowNum(); i++ {
+		s.Equal(data.GetRow(i), data.GetRowView(i))
+	}
+
+	view := data.GetRowView(0)
+	s.Len(view, data.Dim)
+	s.Equal(data.Dim, cap(view))
+	// the view shares the backing array
+	data.Data[0] = 100
+	s.EqualValues(100, view[0])
+
+	s.Equal(0, int(testing.AllocsPerRun(10, func() { _ = data.GetRowView(1) })))
+}
+
+func (s *InsertDataSuite) TestFindTimestampConflicts() {
+	conflicts, err := s.iDataTwoRows.FindTimestampConflicts(Int64Field)
+	s.NoError(err)
+	s.Empty(conflicts)
+
+	err = s.iDataTwoRows.Append(map[FieldID]interface{}{
+		RowIDField:         int64(4),
+		TimestampField:     int64(3),
+		BoolField:          true,
+		Int8Field:          int8(3),
+		Int16Field:         int16(3),
+		Int32Field:         int32(3),
+		Int64Field:         int64(3),
+		FloatField:         float32(3),
+		DoubleField:        float64(3),
+		StringField:        "str",
+		BinaryVectorField:  []byte{0},
+		FloatVectorField:   []float32{4, 5, 6, 7},
+		Float16VectorField: []byte{0, 0, 0, 0, 255, 255, 255, 255},
+		ArrayField: &schemapb.ScalarField{
+			Data: &schemapb.ScalarField_IntData{
+				IntData: &schemapb.IntArray{Data: []int32{1, 2, 3}},
+			},
+		},
+		JSONField: []byte(`{"batch":4}`),
+	})
+	s.Require().NoError(err)
+
+	conflicts, err = s.iDataTwoRows.FindTimestampConflicts(Int64Field)
+	s.NoError(err)
+	s.Require().Len(conflicts, 1)
+	s.Equal(int64(3), conflicts[0].PK)
+	s.Equal(int64(3), conflicts[0].Timestamp)
+	s.Equal([]int{0, 2}, conflicts[0].Offsets)
+
+	_, err = s.iDataTwoRows.FindTimestampConflicts(999)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	_, err = s.iDataTwoRows.FindTimestampConflicts(FloatField)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestEstimateSerializedSize() {
+	meta := genTestCollectionMeta()
+	codec := NewInsertCodecWithSchema(meta)
+
+	data, err := NewInsertData(meta.Schema)
+	s.Require().NoError(err)
+	for i := 0; i < 1000; i++ {
+		err = data.Append(map[FieldID]interface{}{
+			RowIDField:         int64(i),
+			TimestampField:     int64(i + 1),
+			BoolField:          rand.Intn(2) == 0,
+			Int8Field:          int8(rand.Int()),
+			Int16Field:         int16(rand.Int()),
+			Int32Field:         rand.Int31(),
+			Int64Field:         rand.Int63(),
+			FloatField:         rand.Float32(),
+			DoubleField:        rand.Float64(),
+			StringField:        fmt.Sprintf("str-%d", rand.Int()),
+			BinaryVectorField:  []byte{byte(rand.Int())},
+			FloatVectorField:   []float32{rand.Float32(), rand.Float32(), rand.Float32(), rand.Float32()},
+			Float16VectorField: []byte{byte(rand.Int()), 0, byte(rand.Int()), 0, byte(rand.Int()), 0, byte(rand.Int()), 0},
+			ArrayField: &schemapb.ScalarField{
+				Data: &schemapb.ScalarField_IntData{
+					IntData: &schemapb.IntArray{Data: []int32{1, 2, rand.Int31()}},
+				},
+			},
+			JSONField: []byte(fmt.Sprintf(`{"batch":%d}`, rand.Int())),
+		})
+		s.Require().NoError(err)
+	}
+
+	for _, iData := range []*InsertData{s.iDataTwoRows, data} {
+		estimated, err := iData.EstimateSerializedSize(codec)
+		s.Require().NoError(err)
+
+		blobs, err := codec.Serialize(PartitionID, SegmentID, iData)
+		s.Require().NoError(err)
+		var actual int
+		for _, blob := range blobs {
+			actual += len(blob.Value)
+		}
+		s.InDelta(actual, estimated, float64(actual)*0.5)
+	}
+
+	_, err = s.iDataEmpty.EstimateSerializedSize(&InsertCodec{})
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestScalarsToVector() {
+	const targetField FieldID = 200
+	sources := []FieldID{201, 202, 203, 204}
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		201: &FloatFieldData{Data: []float32{1, 5}},
+		202: &FloatFieldData{Data: []float32{2, 6}},
+		203: &FloatFieldData{Data: []float32{3, 7}},
+		204: &FloatFieldData{Data: []float32{4, 8}},
+	}}
+
+	err := iData.ScalarsToVector(sources, targetField)
+	s.Require().NoError(err)
+	vectors, ok := iData.Data[targetField].(*FloatVectorFieldData)
+	s.Require().True(ok)
+	s.Equal(4, vectors.Dim)
+	s.Equal(2, vectors.RowNum())
+	s.Equal([]float32{1, 2, 3, 4}, vectors.GetRow(0))
+	s.Equal([]float32{5, 6, 7, 8}, vectors.GetRow(1))
+
+	// target already filled
+	err = iData.ScalarsToVector(sources, targetField)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	err = s.iDataTwoRows.ScalarsToVector([]FieldID{Int8Field, DoubleField}, targetField)
+	s.NoError(err)
+	s.Equal([]float32{3, 3}, s.iDataTwoRows.Data[targetField].GetRow(0))
+
+	err = s.iDataTwoRows.ScalarsToVector([]FieldID{Int8Field, StringField}, 300)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	err = s.iDataTwoRows.ScalarsToVector([]FieldID{Int8Field, 999}, 300)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	err = s.iDataTwoRows.ScalarsToVector(nil, 300)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	iData.Data[204] = &FloatFieldData{Data: []float32{4}}
+	err = iData.ScalarsToVector(sources, 300)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestDistinctTerms() {
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		StringField: &StringFieldData{Data: []string{"milvus", "vector db", "milvus", "vector vector search", ""}},
+		Int64Field:  &Int64FieldData{Data: []int64{1, 2, 3, 4, 5}},
+	}}
+
+	terms, counts, err := iData.DistinctTerms(StringField, false)
+	s.NoError(err)
+	s.Equal([]string{"", "milvus", "vector db", "vector vector search"}, terms)
+	s.Equal([]int{1, 2, 1, 1}, counts)
+
+	terms, counts, err = iData.DistinctTerms(StringField, true)
+	s.NoError(err)
+	s.Equal([]string{"db", "milvus", "search", "vector"}, terms)
+	s.Equal([]int{1, 2, 1, 2}, counts)
+
+	_, _, err = iData.DistinctTerms(Int64Field, false)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	_, _, err = iData.DistinctTerms(999, false)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	nullable := NewNullableFieldData(&StringFieldData{Data: []string{"milvus"}})
+	nullable.AppendNull()
+	s.Require().NoError(nullable.AppendRow("vector db"))
+	iData.Data[StringField] = nullable
+	terms, counts, err = iData.DistinctTerms(StringField, true)
+	s.NoError(err)
+	s.Equal([]string{"db", "milvus", "vector"}, terms)
+	s.Equal([]int{1, 1, 1}, counts)
+}
+
+func (s *InsertDataSuite) TestRowHashes() {
+	row := map[FieldID]interface{}{
+		RowIDField:         int64(5),
+		TimestampField:     int64(5),
+		BoolField:          true,
+		Int8Field:          int8(3),
+		Int16Field:         int16(3),
+		Int32Field:         int32(3),
+		Int64Field:         int64(3),
+		FloatField:         float32(3),
+		DoubleField:        float64(3),
+		StringField:        "str",
+		BinaryVectorField:  []byte{0},
+		FloatVectorField:   []float32{4, 5, 6, 7},
+		Float16VectorField: []byte{0, 0, 0, 0, 255, 255, 255, 255},
+		ArrayField: &schemapb.ScalarField{
+			Data: &schemapb.ScalarField_IntData{
+				IntData: &schemapb.IntArray{Data: []int32{1, 2, 3}},
+			},
+		},
+		JSONField: []byte(`{"batch":3}`),
+	}
+	// row 0 and row 2 differ only in row id and timestamp
+	err := s.iDataTwoRows.Append(row)
+	s.Require().NoError(err)
+
+	hashes, err := s.iDataTwoRows.RowHashes([]FieldID{RowIDField, TimestampField})
+	s.NoError(err)
+	s.Len(hashes, 3)
+	s.Equal(hashes[0], hashes[2])
+	s.NotEqual(hashes[0], hashes[1])
+
+	again, err := s.iDataTwoRows.RowHashes([]FieldID{TimestampField, RowIDField})
+	s.NoError(err)
+	s.Equal(hashes, again)
+
+	withTs, err := s.iDataTwoRows.RowHashes(nil)
+	s.NoError(err)
+	s.NotEqual(withTs[0], withTs[2])
+
+	_, err = s.iDataEmpty.RowHashes(lo.Keys(s.iDataEmpty.Data))
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	s.iDataTwoRows.Data[RowIDField] = &Int64FieldData{Data: []int64{1}}
+	_, err = s.iDataTwoRows.RowHashes(nil)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestFilterExpired() {
+	now := time.Now()
+	expired := int64(tsoutil.ComposeTSByTime(now.Add(-2*time.Hour), 0))
+	fresh := int64(tsoutil.ComposeTSByTime(now.Add(-time.Minute), 0))
+	s.iDataTwoRows.Data[TimestampField] = &Int64FieldData{Data: []int64{expired, fresh}}
+
+	result, dropped, err := s.iDataTwoRows.FilterExpired(tsoutil.ComposeTSByTime(now, 0), time.Hour)
+	s.Require().NoError(err)
+	s.Equal(1, dropped)
+	s.Equal(1, result.GetRowNum())
+	for fieldID, fieldData := range result.Data {
+		s.Equal(s.iDataTwoRows.Data[fieldID].GetRow(1), fieldData.GetRow(0))
+	}
+	// original data is not changed
+	s.Equal(2, s.iDataTwoRows.GetRowNum())
+
+	result, dropped, err = s.iDataTwoRows.FilterExpired(tsoutil.ComposeTSByTime(now, 0), 0)
+	s.NoError(err)
+	s.Equal(0, dropped)
+	s.Equal(2, result.GetRowNum())
+
+	_, _, err = (&InsertData{Data: map[FieldID]FieldData{}}).FilterExpired(0, time.Hour)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestFilterRows() {
+	calls := 0
+	result, err := s.iDataTwoRows.FilterRows(func(row int) bool {
+		calls++
+		return row == 1
+	})
+	s.Require().NoError(err)
+	s.Equal(2, calls)
+	s.Equal(1, result.GetRowNum())
+	s.Len(result.Data, len(s.iDataTwoRows.Data))
+	for fieldID, fieldData := range result.Data {
+		s.Equal(1, fieldData.RowNum(), "field %d", fieldID)
+		s.Equal(s.iDataTwoRows.Data[fieldID].GetRow(1), fieldData.GetRow(0), "field %d", fieldID)
+	}
+	s.Equal(s.iDataTwoRows.Data[FloatVectorField].(*FloatVectorFieldData).Data[4:],
+		result.Data[FloatVectorField].(*FloatVectorFieldData).Data)
+	s.Equal(s.iDataTwoRows.Data[BinaryVectorField].(*BinaryVectorFieldData).Data[1:],
+		result.Data[BinaryVectorField].(*BinaryVectorFieldData).Data)
+	s.Equal(2, s.iDataTwoRows.GetRowNum())
+
+	result, err = s.iDataTwoRows.FilterRows(func(int) bool { return false })
+	s.Require().NoError(err)
+	s.Zero(result.GetRowNum())
+	s.Equal(s.iDataEmpty.GetMemorySize(), result.GetMemorySize())
+
+	s.NoError(s.iDataTwoRows.Data[Int64Field].AppendRow(int64(100)))
+	_, err = s.iDataTwoRows.FilterRows(func(int) bool { return true })
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestEncodeDecodeInto() {
+	for _, format := range []string{TransportFormatGob, TransportFormatMsgpack} {
+		s.Run(format, func() {
+			buf, err := EncodeInsertData(format, s.iDataTwoRows)
+			s.Require().NoError(err)
+
+			dst, err := NewInsertData(s.schema)
+			s.Require().NoError(err)
+			err = DecodeInto(format, buf, dst)
+			s.Require().NoError(err)
+			s.Equal(2, dst.GetRowNum())
+			for fieldID, fieldData := range s.iDataTwoRows.Data {
+				for i := 0; i < fieldData.RowNum(); i++ {
+					if fieldID == ArrayField {
+						s.True(proto.Equal(fieldData.GetRow(i).(*schemapb.ScalarField), dst.Data[fieldID].GetRow(i).(*schemapb.ScalarField)))
+						continue
+					}
+					s.Equal(fieldData.GetRow(i), dst.Data[fieldID].GetRow(i))
+				}
+			}
+
+			// decoded rows are appended
+			err = DecodeInto(format, buf, dst)
+			s.NoError(err)
+			s.Equal(4, dst.GetRowNum())
+
+			// schema mismatch
+			dst, err = NewInsertData(s.schema)
+			s.Require().NoError(err)
+			dst.Data[Int64Field] = &Int32FieldData{}
+			err = DecodeInto(format, buf, dst)
+			s.ErrorIs(err, merr.ErrParameterInvalid)
+
+			delete(dst.Data, Int64Field)
+			err = DecodeInto(format, buf, dst)
+			s.ErrorIs(err, merr.ErrParameterInvalid)
+
+			err = DecodeInto(format, buf[:len(buf)/2], s.iDataEmpty)
+			s.ErrorIs(err, merr.ErrParameterInvalid)
+		})
+	}
+
+	_, err := EncodeInsertData("json", s.iDataTwoRows)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	err = DecodeInto("json", nil, s.iDataEmpty)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestToJSONL() {
+	var buf bytes.Buffer
+	err := s.iDataTwoRows.ToJSONL(&buf, 0)
+	s.Require().NoError(err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	s.Require().Len(lines, 2)
+	rows := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		row := make(map[string]any)
+		s.Require().NoError(json.Unmarshal([]byte(line), &row))
+		rows = append(rows, row)
+	}
+	s.EqualValues(3, rows[0]["field_int64"])
+	s.EqualValues(1, rows[1]["field_int64"])
+	s.Equal(true, rows[0]["field_bool"])
+	s.Equal("str", rows[0]["field_string"])
+	s.Equal([]any{4.0, 5.0, 6.0, 7.0}, rows[0]["field_float_vector"])
+	s.Equal(base64.StdEncoding.EncodeToString([]byte{0}), rows[0]["field_binary_vector"])
+	s.Equal([]any{1.0, 2.0, 3.0}, rows[0]["field_int32_array"])
+	s.Equal(map[string]any{"batch": 3.0}, rows[0]["field_json"])
+
+	s.Run("max rows", func() {
+		buf.Reset()
+		err := s.iDataTwoRows.ToJSONL(&buf, 1)
+		s.NoError(err)
+		s.Equal(1, strings.Count(buf.String(), "\n"))
+	})
+
+	s.Run("nulls and no schema", func() {
+		data := &InsertData{Data: map[FieldID]FieldData{100: &Int64FieldData{}}}
+		err := data.AppendRowWithValidity(map[FieldID]any{100: int64(1)}, map[FieldID]bool{100: false})
+		s.Require().NoError(err)
+		buf.Reset()
+		err = data.ToJSONL(&buf, 0)
+		s.NoError(err)
+		s.JSONEq(`{"100":null}`, buf.String())
+	})
+}
+
+func (s *InsertDataSuite) TestInferJSONSchema() {
+	data := &InsertData{Data: map[FieldID]FieldData{
+		JSONField: &JSONFieldData{Data: [][]byte{
+			[]byte(`{"name":"a","age":1,"vip":true,"tags":["x"],"addr":{"city":"c"},"id":1,"note":null}`),
+			[]byte(`{"name":"b","age":2.5,"vip":false,"tags":[],"addr":{},"id":"2"}`),
+			[]byte(`{"name":"c","extra":null}`),
+		}},
+		Int64Field: &Int64FieldData{Data: []int64{1, 2, 3}},
+	}}
+
+	types, err := data.InferJSONSchema(JSONField)
+	s.Require().NoError(err)
+	s.Equal(map[string]string{
+		"name": JSONTypeString,
+		"age":  JSONTypeNumber,
+		"vip":  JSONTypeBool,
+		"tags": JSONTypeArray,
+		"addr": JSONTypeObject,
+		"id":   JSONTypeConflict,
+	}, types)
+
+	_, err = data.InferJSONSchema(Int64Field)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = data.InferJSONSchema(StringField)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	data.Data[JSONField] = &JSONFieldData{Data: [][]byte{[]byte(`[1,2]`)}}
+	_, err = data.InferJSONSchema(JSONField)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestMergeWithFields() {
+	keepFields := []FieldID{RowIDField, TimestampField, Int64Field, FloatVectorField}
+	err := s.iDataOneRow.MergeWithFields(s.iDataTwoRows, keepFields)
+	s.Require().NoError(err)
+	s.Len(s.iDataOneRow.Data, len(keepFields))
+	s.NotContains(s.iDataOneRow.Data, FieldID(StringField))
+	for _, fieldID := range keepFields {
+		s.Equal(3, s.iDataOneRow.Data[fieldID].RowNum())
+	}
+	s.Equal([]int64{3, 3, 1}, s.iDataOneRow.Data[Int64Field].(*Int64FieldData).Data)
+	// other is not changed
+	s.Contains(s.iDataTwoRows.Data, FieldID(StringField))
+
+	s.Run("missing field in other", func() {
+		err := s.iDataOneRow.MergeWithFields(&InsertData{Data: map[FieldID]FieldData{}}, keepFields)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+	})
+
+	s.Run("not aligned", func() {
+		other := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField: &Int64FieldData{Data: []int64{1, 2}},
+			Int64Field: &Int64FieldData{Data: []int64{1}},
+		}}
+		err := s.iDataEmpty.MergeWithFields(other, []FieldID{RowIDField, Int64Field})
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		// target untouched on error
+		s.Contains(s.iDataEmpty.Data, FieldID(StringField))
+	})
+
+	s.Run("nil other", func() {
+		s.ErrorIs(s.iDataEmpty.MergeWithFields(nil, keepFields), merr.ErrParameterInvalid)
+	})
+
+	s.Run("type not match", func() {
+		target := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:  &Int64FieldData{Data: []int64{1}},
+			Int64Field:  &Int64FieldData{Data: []int64{1}},
+			StringField: &StringFieldData{Data: []string{"a"}},
+		}}
+		other := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField: &Int64FieldData{Data: []int64{2}},
+			Int64Field: &Int32FieldData{Data: []int32{2}},
+		}}
+		err := target.MergeWithFields(other, []FieldID{RowIDField, Int64Field})
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		// target untouched on error
+		s.Len(target.Data, 3)
+		s.Equal([]int64{1}, target.Data[RowIDField].(*Int64FieldData).Data)
+	})
+
+	s.Run("wrapped columns", func() {
+		nullable := func(rows ...any) *NullableFieldData {
+			data := NewNullableFieldData(&Int64FieldData{})
+			for _, row := range rows {
+				s.Require().NoError(data.AppendRow(row))
+			}
+			return data
+		}
+		target := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField: &Int64FieldData{Data: []int64{1}},
+			Int64Field: nullable(int64(1)),
+		}}
+		other := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:  &Int64FieldData{Data: []int64{2, 3}},
+			Int64Field:  nullable(nil, int64(3)),
+			StringField: &StringFieldData{Data: []string{"a", "b"}},
+		}}
+		keepFields := []FieldID{RowIDField, Int64Field}
+		s.Require().NoError(target.MergeWithFields(other, keepFields))
+		s.Len(target.Data, len(keepFields))
+		for _, fieldID := range keepFields {
+			s.Equal(3, target.Data[fieldID].RowNum())
+		}
+		s.Equal([]any{int64(1), nil, int64(3)}, []any{
+			target.Data[Int64Field].GetRow(0), target.Data[Int64Field].GetRow(1), target.Data[Int64Field].GetRow(2),
+		})
+	})
+}
+
+func (s *InsertDataSuite) TestGetPrimaryKeys() {
+	s.Run("int64", func() {
+		schema := &schemapb.CollectionSchema{Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, DataType: schemapb.DataType_Int64, IsPrimaryKey: true},
+			{FieldID: 101, DataType: schemapb.DataType_VarChar},
+		}}
+		data, err := NewInsertData(schema)
+		s.Require().NoError(err)
+		for _, pk := range []int64{3, 1, 2} {
+			s.Require().NoError(data.Append(map[FieldID]interface{}{100: pk, 101: "a"}))
+		}
+
+		pks, err := data.GetPrimaryKeys(schema)
+		s.NoError(err)
+		s.Equal(schemapb.DataType_Int64, pks.Type())
+		s.Equal(3, pks.Len())
+		s.Equal([]int64{3, 1, 2}, pks.(*Int64PrimaryKeys).Values())
+		s.True(pks.Get(1).EQ(NewInt64PrimaryKey(1)))
+	})
+
+	s.Run("varchar", func() {
+		schema := &schemapb.CollectionSchema{Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, DataType: schemapb.DataType_Int64},
+			{FieldID: 101, DataType: schemapb.DataType_VarChar, IsPrimaryKey: true},
+		}}
+		data, err := NewInsertData(schema)
+		s.Require().NoError(err)
+		for _, pk := range []string{"c", "a", "b"} {
+			s.Require().NoError(data.Append(map[FieldID]interface{}{100: int64(1), 101: pk}))
+		}
+
+		pks, err := data.GetPrimaryKeys(schema)
+		s.NoError(err)
+		s.Equal(schemapb.DataType_VarChar, pks.Type())
+		s.Equal([]string{"c", "a", "b"}, pks.(*VarcharPrimaryKeys).Values())
+		s.True(pks.Get(0).EQ(NewVarCharPrimaryKey("c")))
+	})
+
+	s.Run("no_pk", func() {
+		schema := &schemapb.CollectionSchema{Fields: []*schemapb.FieldSchema{
+			{FieldID: 100, DataType: schemapb.DataType_Int64},
+		}}
+		data, err := NewInsertData(schema)
+		s.Require().NoError(err)
+		_, err = data.GetPrimaryKeys(schema)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		_, err = data.GetPrimaryKeys(nil)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+	})
+}
+
+func (s *InsertDataSuite) TestGenerateDeletes() {
+	deleteData, err := s.iDataTwoRows.GenerateDeletes(Int64Field, TimestampField, func(row RowView) bool {
+		v, ok := row.Get(Int8Field)
+		return ok && v.(int8) == 1
+	})
+	s.Require().NoError(err)
+	s.EqualValues(1, deleteData.RowCount)
+	s.Equal([]PrimaryKey{NewInt64PrimaryKey(1)}, deleteData.Pks)
+	s.Equal([]Timestamp{1}, deleteData.Tss)
+
+	deleteData, err = s.iDataTwoRows.GenerateDeletes(StringField, TimestampField, func(row RowView) bool {
+		return row.Index() >= 0
+	})
+	s.Require().NoError(err)
+	s.EqualValues(2, deleteData.RowCount)
+	s.Equal([]PrimaryKey{NewVarCharPrimaryKey("str"), NewVarCharPrimaryKey("str")}, deleteData.Pks)
+	s.Equal([]Timestamp{3, 1}, deleteData.Tss)
+
+	_, err = s.iDataTwoRows.GenerateDeletes(FloatField, TimestampField, func(RowView) bool { return true })
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = s.iDataTwoRows.GenerateDeletes(Int64Field, FloatField, func(RowView) bool { return true })
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = s.iDataTwoRows.GenerateDeletes(Int64Field, 999, func(RowView) bool { return true })
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestAppendReusing() {
+	data := &InsertData{Data: map[FieldID]FieldData{
+		Int64Field:       &Int64FieldData{},
+		JSONField:        &JSONFieldData{},
+		FloatVectorField: &FloatVectorFieldData{Dim: 2},
+	}}
+	jsonBuf := make([]byte, 0, 16)
+	vector := make([]float32, 2)
+	row := make(map[FieldID]interface{})
+	for idx := 0; idx < 3; idx++ {
+		jsonBuf = append(jsonBuf[:0], fmt.Sprintf(`{"i":%d}`, idx)...)
+		vector[0], vector[1] = float32(idx), float32(idx+1)
+		row[Int64Field] = int64(idx)
+		row[JSONField] = jsonBuf
+		row[FloatVectorField] = vector
+		s.Require().NoError(data.AppendReusing(row))
+	}
+
+	s.Equal([]int64{0, 1, 2}, data.Data[Int64Field].(*Int64FieldData).Data)
+	s.Equal([][]byte{[]byte(`{"i":0}`), []byte(`{"i":1}`), []byte(`{"i":2}`)}, data.Data[JSONField].(*JSONFieldData).Data)
+	s.Equal([]float32{0, 1, 1, 2, 2, 3}, data.Data[FloatVectorField].(*FloatVectorFieldData).Data)
+
+	// no field is appended if any field is missing
+	row[StringField] = "str"
+	s.Error(data.AppendReusing(row))
+	s.Equal(3, data.Data[Int64Field].RowNum())
+}
+
+func BenchmarkFloatVectorGetRow(b *testing.B) {
+	const dim = 128
+	data := &FloatVectorFieldData{Data: make([]float32, 1024*dim), Dim: dim}
+	var sum float32
+	b.Run("GetRow", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sum += data.GetRow(i % 1024).([]float32)[0]
+		}
+	})
+	b.Run("GetRowView", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sum += data.GetRowView(i % 1024)[0]
+		}
+	})
+	_ = sum
+}
+
+func BenchmarkAppendReusing(b *testing.B) {
+	data := &InsertData{Data: map[FieldID]FieldData{
+		RowIDField:       &Int64FieldData{},
+		Int64Field:       &Int64FieldData{},
+		FloatVectorField: &FloatVectorFieldData{Dim: 4},
+	}}
+	vector := []float32{1, 2, 3, 4}
+	row := make(map[FieldID]interface{}, len(data.Data))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		row[RowIDField] = int64(n)
+		row[Int64Field] = int64(n)
+		row[FloatVectorField] = vector
+		if err := data.AppendReusing(row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendRows(b *testing.B) {
+	const rowNum = 1024
+	rows := make([]map[FieldID]interface{}, 0, rowNum)
+	for i := 0; i < rowNum; i++ {
+		rows = append(rows, map[FieldID]interface{}{
+			RowIDField:       int64(i),
+			Int64Field:       int64(i),
+			FloatVectorField: []float32{1, 2, 3, 4},
+		})
+	}
+	newData := func() *InsertData {
+		return &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:       &Int64FieldData{},
+			Int64Field:       &Int64FieldData{},
+			FloatVectorField: &FloatVectorFieldData{Dim: 4},
+		}}
+	}
+
+	b.Run("append", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			data := newData()
+			for _, row := range rows {
+				if err := data.Append(row); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("append rows", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			if err := newData().AppendRows(rows); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkNewInsertDataWithCap(b *testing.B) {
+	const rowNum = 50000
+	schema := &schemapb.CollectionSchema{Fields: []*schemapb.FieldSchema{
+		{FieldID: RowIDField, DataType: schemapb.DataType_Int64},
+		{FieldID: Int64Field, DataType: schemapb.DataType_Int64},
+		{FieldID: FloatVectorField, DataType: schemapb.DataType_FloatVector, TypeParams: []*commonpb.KeyValuePair{{Key: common.DimKey, Value: "4"}}},
+	}}
+	rows := make([]map[FieldID]interface{}, 0, rowNum)
+	for i := 0; i < rowNum; i++ {
+		rows = append(rows, map[FieldID]interface{}{
+			RowIDField:       int64(i),
+			Int64Field:       int64(i),
+			FloatVectorField: []float32{1, 2, 3, 4},
+		})
+	}
+	appendRows := func(b *testing.B, data *InsertData) {
+		for _, row := range rows {
+			if err := data.Append(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("default", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			data, err := NewInsertData(schema)
+			if err != nil {
+				b.Fatal(err)
+			}
+			appendRows(b, data)
+		}
+	})
+
+	b.Run("with cap", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			data, err := NewInsertDataWithCap(schema, rowNum)
+			if err != nil {
+				b.Fatal(err)
+			}
+			appendRows(b, data)
+		}
+	})
+}
+
+func BenchmarkRowIterator(b *testing.B) {
+	const rowNum = 1024
+	data := &InsertData{Data: map[FieldID]FieldData{
+		Int64Field:       &Int64FieldData{},
+		FloatVectorField: &FloatVectorFieldData{Dim: 4},
+	}}
+	for i := 0; i < rowNum; i++ {
+		if err := data.Append(map[FieldID]interface{}{Int64Field: int64(i + 1000), FloatVectorField: []float32{1, 2, 3, 4}}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("get row", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			var sum int64
+			for row := 0; row < rowNum; row++ {
+				sum += data.Data[Int64Field].GetRow(row).(int64)
+				_ = data.Data[FloatVectorField].GetRow(row).([]float32)
+			}
+		}
+	})
+
+	b.Run("iterator", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			var sum int64
+			it := data.RowIterator()
+			for it.Next() {
+				value, _ := it.GetInt64(Int64Field)
+				sum += value
+				_, _ = it.GetFloatVector(FloatVectorField)
+			}
+		}
+	})
+}
+
+func BenchmarkArrayFieldAppendRow(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		column := &ArrayFieldData{ElementType: schemapb.DataType_Int64}
+		for i := 0; i < 1024; i++ {
+			// ArrayFieldData keeps the row, so each row needs its own message
+			row := &schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{
+				LongData: &schemapb.LongArray{Data: []int64{int64(i), int64(i + 1)}},
+			}}
+			if err := column.AppendRow(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkContiguousArrayFieldAppendRow(b *testing.B) {
+	b.ReportAllocs()
+	elements := &schemapb.LongArray{Data: make([]int64, 2)}
+	row := &schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{LongData: elements}}
+	for n := 0; n < b.N; n++ {
+		column := NewContiguousArrayFieldData(schemapb.DataType_Int64)
+		for i := 0; i < 1024; i++ {
+			// elements are copied into the backing store, the row message is reused
+			elements.Data[0], elements.Data[1] = int64(i), int64(i+1)
+			if err := column.AppendRow(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func (s *InsertDataSuite) TestValidateVectorField() {
+	s.NoError(s.iDataTwoRows.ValidateVectorField(FloatVectorField, 4))
+	s.NoError(s.iDataTwoRows.ValidateVectorField(BinaryVectorField, 8))
+	s.NoError(s.iDataTwoRows.ValidateVectorField(Float16VectorField, 4))
+
+	err := s.iDataTwoRows.ValidateVectorField(FloatVectorField, 8)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	s.ErrorContains(err, "row 0 of field 109 has dim 4, expected 8")
+
+	s.Run("incomplete row", func() {
+		data := &InsertData{Data: map[FieldID]FieldData{
+			FloatVectorField: &FloatVectorFieldData{Data: []float32{1, 2, 3, 4, 5, 6}, Dim: 4},
+		}}
+		err := data.ValidateVectorField(FloatVectorField, 4)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, "row 1 of field 109 is incomplete")
+	})
+
+	s.Run("null row", func() {
+		data := &InsertData{Data: map[FieldID]FieldData{
+			FloatVectorField: &FloatVectorFieldData{Dim: 2},
+		}}
+		valid := map[FieldID]bool{FloatVectorField: true}
+		s.Require().NoError(data.AppendRowWithValidity(map[FieldID]any{FloatVectorField: []float32{1, 2}}, valid))
+		s.Require().NoError(data.AppendRowWithValidity(map[FieldID]any{FloatVectorField: []float32{3, 4}}, valid))
+		valid[FloatVectorField] = false
+		s.Require().NoError(data.AppendRowWithValidity(nil, valid))
+		err := data.ValidateVectorField(FloatVectorField, 2)
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, "row 2 of field 109 is null")
+	})
+
+	s.ErrorIs(s.iDataTwoRows.ValidateVectorField(Int64Field, 4), merr.ErrParameterInvalid)
+	s.ErrorIs(s.iDataTwoRows.ValidateVectorField(999, 4), merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestColumnDowncast() {
+	data := &InsertData{Data: map[FieldID]FieldData{
+		Int64Field: &Int64FieldData{Data: []int64{-32768, 0, 32767}},
+		FloatField: &FloatFieldData{Data: []float32{1}},
+	}}
+	values, err := data.ColumnDowncast(Int64Field, schemapb.DataType_Int16)
+	s.Require().NoError(err)
+	s.Equal([]int16{-32768, 0, 32767}, values)
+
+	values, err = data.ColumnDowncast(Int64Field, schemapb.DataType_Int32)
+	s.NoError(err)
+	s.Equal([]int32{-32768, 0, 32767}, values)
+
+	_, err = data.ColumnDowncast(Int64Field, schemapb.DataType_Int8)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	s.ErrorContains(err, "value -32768 of row 0")
+
+	data.Data[Int64Field].(*Int64FieldData).Data[2] = 32768
+	_, err = data.ColumnDowncast(Int64Field, schemapb.DataType_Int16)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	_, err = data.ColumnDowncast(FloatField, schemapb.DataType_Int16)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = data.ColumnDowncast(Int64Field, schemapb.DataType_Float)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestCompactArrayField() {
+	for _, elementType := range []schemapb.DataType{
+		schemapb.DataType_Bool, schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32,
+		schemapb.DataType_Int64, schemapb.DataType_Float, schemapb.DataType_Double, schemapb.DataType_VarChar,
+	} {
+		s.Run(elementType.String(), func() {
+			rows := []*schemapb.ScalarField{}
+			for i := 0; i < 4; i++ {
+				row := &schemapb.ScalarField{}
+				switch elementType {
+				case schemapb.DataType_Bool:
+					row.Data = &schemapb.ScalarField_BoolData{BoolData: &schemapb.BoolArray{Data: make([]bool, i)}}
+				case schemapb.DataType_Int8, schemapb.DataType_Int16, schemapb.DataType_Int32:
+					row.Data = &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: make([]int32, i)}}
+				case schemapb.DataType_Int64:
+					row.Data = &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: make([]int64, i)}}
+				case schemapb.DataType_Float:
+					row.Data = &schemapb.ScalarField_FloatData{FloatData: &schemapb.FloatArray{Data: make([]float32, i)}}
+				case schemapb.DataType_Double:
+					row.Data = &schemapb.ScalarField_DoubleData{DoubleData: &schemapb.DoubleArray{Data: make([]float64, i)}}
+				case schemapb.DataType_VarChar:
+					strs := make([]string, i)
+					for j := range strs {
+						strs[j] = fmt.Sprintf("s%d", j)
+					}
+					row.Data = &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: strs}}
+				}
+				rows = append(rows, row)
+			}
+
+			array := &ArrayFieldData{ElementType: elementType, Data: rows}
+			data := &InsertData{Data: map[FieldID]FieldData{ArrayField: array}}
+			s.Require().NoError(data.CompactArrayField(ArrayField))
+
+			compacted, ok := data.Data[ArrayField].(*ContiguousArrayFieldData)
+			s.Require().True(ok)
+			s.Equal(array.RowNum(), compacted.RowNum())
+			s.Equal(array.GetMemorySize(), compacted.GetMemorySize())
+			for i := 0; i < array.RowNum(); i++ {
+				s.True(proto.Equal(array.GetRow(i).(*schemapb.ScalarField), compacted.GetRow(i).(*schemapb.ScalarField)))
+			}
+			s.Equal(array.RowNum(), compacted.ToArrayFieldData().RowNum())
+
+			// appending to the compacted column must not leak into rows returned before
+			first := compacted.GetRow(1)
+			s.NoError(compacted.AppendRow(rows[3]))
+			s.True(proto.Equal(rows[1], first.(*schemapb.ScalarField)))
+			s.True(proto.Equal(rows[3], compacted.GetRow(4).(*schemapb.ScalarField)))
+		})
+	}
+
+	s.Run("element type mismatch", func() {
+		column := NewContiguousArrayFieldData(schemapb.DataType_Int64)
+		err := column.AppendRow(&schemapb.ScalarField{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{1}}}})
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.Equal(0, column.RowNum())
+	})
+
+	s.Run("not array field", func() {
+		s.ErrorIs(s.iDataOneRow.CompactArrayField(Int64Field), merr.ErrParameterInvalid)
+		s.ErrorIs(s.iDataOneRow.CompactArrayField(999), merr.ErrParameterInvalid)
+	})
+}
+
+func (s *InsertDataSuite) TestInferDim() {
+	schema := &schemapb.CollectionSchema{
+		Fields: []*schemapb.FieldSchema{
+			{FieldID: RowIDField, DataType: schemapb.DataType_Int64},
+			{FieldID: FloatVectorField, DataType: schemapb.DataType_FloatVector},
+			{FieldID: BinaryVectorField, DataType: schemapb.DataType_BinaryVector},
+			{FieldID: Float16VectorField, DataType: schemapb.DataType_Float16Vector, TypeParams: []*commonpb.KeyValuePair{
+				{Key: common.DimKey, Value: "2"},
+			}},
+		},
+	}
+	_, err := NewInsertData(schema)
+	s.Error(err)
+
+	data, err := NewInsertData(schema, WithInferDim())
+	s.Require().NoError(err)
+	s.NoError(data.Append(map[FieldID]interface{}{
+		RowIDField:         int64(1),
+		FloatVectorField:   []float32{1, 2, 3},
+		BinaryVectorField:  []byte{1, 2},
+		Float16VectorField: []byte{1, 2, 3, 4},
+	}))
+	s.Equal(3, data.Data[FloatVectorField].(*FloatVectorFieldData).Dim)
+	s.Equal(16, data.Data[BinaryVectorField].(*BinaryVectorFieldData).Dim)
+	// dim in schema is kept
+	s.Equal(2, data.Data[Float16VectorField].(*Float16VectorFieldData).Dim)
+
+	s.NoError(data.Data[FloatVectorField].AppendRow([]float32{4, 5, 6}))
+	s.Error(data.Data[FloatVectorField].AppendRow([]float32{1, 2, 3, 4}))
+	s.Equal(2, data.Data[FloatVectorField].RowNum())
+}
+
+func (s *InsertDataSuite) TestRejectNonFinite() {
+	invalid := [][]float32{
+		{1, float32(math.NaN()), 3, 4},
+		{1, 2, float32(math.Inf(1)), 4},
+		{float32(math.Inf(-1)), 2, 3, 4},
+	}
+
+	// not checked by default
+	s.NoError(s.iDataEmpty.Data[FloatVectorField].AppendRow(invalid[0]))
+
+	data, err := NewInsertData(s.schema, WithRejectNonFinite())
+	s.Require().NoError(err)
+	field := data.Data[FloatVectorField]
+	for _, row := range invalid {
+		s.ErrorIs(field.AppendRow(row), merr.ErrParameterInvalid)
+	}
+	s.NoError(field.AppendRow([]float32{1, 2, 3, float32(math.MaxFloat32)}))
+	s.Equal(1, field.RowNum())
+
+	// the check is kept by derived columns and columnar append
+	s.ErrorIs(field.Slice(0, 0).AppendRow(invalid[0]), merr.ErrParameterInvalid)
+	s.ErrorIs(cloneFieldData(field).AppendRow(invalid[1]), merr.ErrParameterInvalid)
+	s.ErrorIs(data.AppendColumn(FloatVectorField, append([]float32{1, 2, 3, 4}, invalid[2]...)), merr.ErrParameterInvalid)
+	s.Equal(1, field.RowNum())
+}
+
+func (s *InsertDataSuite) TestEncodeDecodeRows() {
+	buf, err := s.iDataTwoRows.EncodeRows([]int{1, 0})
+	s.Require().NoError(err)
+
+	decoded := &InsertData{}
+	s.Require().NoError(decoded.DecodeRows(buf))
+	s.Equal(len(s.iDataTwoRows.Data), len(decoded.Data))
+	for fieldID, fieldData := range s.iDataTwoRows.Data {
+		s.Require().Contains(decoded.Data, fieldID)
+		s.Equal(2, decoded.Data[fieldID].RowNum())
+		for idx, row := range []int{1, 0} {
+			expected, actual := fieldData.GetRow(row), decoded.Data[fieldID].GetRow(idx)
+			if scalar, ok := expected.(*schemapb.ScalarField); ok {
+				s.True(proto.Equal(scalar, actual.(*schemapb.ScalarField)), "field %d", fieldID)
+				continue
+			}
+			s.Equal(expected, actual, "field %d", fieldID)
+		}
+	}
+
+	// append to existing data with the same fields
+	s.NoError(s.iDataOneRow.DecodeRows(buf))
+	s.Equal(3, s.iDataOneRow.GetRowNum())
+
+	// malformed buffer appends nothing
+	s.Error(s.iDataOneRow.DecodeRows(buf[:len(buf)-1]))
+	s.Equal(3, s.iDataOneRow.GetRowNum())
+
+	_, err = s.iDataTwoRows.EncodeRows([]int{2})
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestMerge() {
+	size := s.iDataOneRow.GetMemorySize() + s.iDataTwoRows.GetMemorySize()
+	// vector fields carry a fixed dim overhead that is not duplicated by merging
+	for _, fieldData := range s.iDataTwoRows.Data {
+		switch fieldData.(type) {
+		case *BinaryVectorFieldData, *FloatVectorFieldData, *Float16VectorFieldData:
+			size -= 4
+		}
+	}
+	s.NoError(s.iDataOneRow.Merge(s.iDataTwoRows))
+	s.Equal(3, s.iDataOneRow.GetRowNum())
+	s.Equal(size, s.iDataOneRow.GetMemorySize())
+	for fieldID, fieldData := range s.iDataTwoRows.Data {
+		s.Equal(3, s.iDataOneRow.Data[fieldID].RowNum())
+		s.Equal(fieldData.GetRow(1), s.iDataOneRow.Data[fieldID].GetRow(2))
+	}
+
+	// empty other is a no-op
+	s.NoError(s.iDataOneRow.Merge(s.iDataEmpty))
+	s.NoError(s.iDataOneRow.Merge(nil))
+	s.NoError(s.iDataOneRow.Merge(&InsertData{}))
+	s.Equal(3, s.iDataOneRow.GetRowNum())
+
+	s.Run("field set mismatch", func() {
+		other := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField: &Int64FieldData{Data: []int64{1}},
+		}}
+		s.ErrorIs(s.iDataOneRow.Merge(other), merr.ErrParameterInvalid)
+		s.Equal(3, s.iDataOneRow.GetRowNum())
+	})
+
+	s.Run("field type mismatch", func() {
+		target := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:       &Int64FieldData{Data: []int64{1}},
+			FloatVectorField: &FloatVectorFieldData{Data: []float32{1, 2}, Dim: 2},
+		}}
+		other := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:       &Int32FieldData{Data: []int32{2}},
+			FloatVectorField: &FloatVectorFieldData{Data: []float32{1, 2}, Dim: 2},
+		}}
+		s.ErrorIs(target.Merge(other), merr.ErrParameterInvalid)
+
+		other.Data[RowIDField] = &Int64FieldData{Data: []int64{2}}
+		other.Data[FloatVectorField] = &FloatVectorFieldData{Data: []float32{1, 2, 3}, Dim: 3}
+		s.ErrorIs(target.Merge(other), merr.ErrParameterInvalid)
+		s.Equal(1, target.GetRowNum())
+	})
+}
+
+// unknownFieldData is a FieldData implemented out of storage.
+type unknownFieldData struct {
+	*Int64FieldData
+}
+
+func (s *InsertDataSuite) TestClone() {
+	cases := []struct {
+		name   string
+		data   FieldData
+		mutate func(FieldData)
+	}{
+		{"bool", s.iDataTwoRows.Data[BoolField], func(d FieldData) { d.(*BoolFieldData).Data[0] = !d.(*BoolFieldData).Data[0] }},
+		{"int8", s.iDataTwoRows.Data[Int8Field], func(d FieldData) { d.(*Int8FieldData).Data[0]++ }},
+		{"int16", s.iDataTwoRows.Data[Int16Field], func(d FieldData) { d.(*Int16FieldData).Data[0]++ }},
+		{"int32", s.iDataTwoRows.Data[Int32Field], func(d FieldData) { d.(*Int32FieldData).Data[0]++ }},
+		{"int64", s.iDataTwoRows.Data[Int64Field], func(d FieldData) { d.(*Int64FieldData).Data[0]++ }},
+		{"float", s.iDataTwoRows.Data[FloatField], func(d FieldData) { d.(*FloatFieldData).Data[0]++ }},
+		{"double", s.iDataTwoRows.Data[DoubleField], func(d FieldData) { d.(*DoubleFieldData).Data[0]++ }},
+		{"string", s.iDataTwoRows.Data[StringField], func(d FieldData) { d.(*StringFieldData).Data[0] = "mutated" }},
+		{"array", s.iDataTwoRows.Data[ArrayField], func(d FieldData) {
+			d.(*ArrayFieldData).Data[0].GetIntData().Data[0] = 100
+		}},
+		{"json", s.iDataTwoRows.Data[JSONField], func(d FieldData) { d.(*JSONFieldData).Data[0][0] = '[' }},
+		{"binary vector", s.iDataTwoRows.Data[BinaryVectorField], func(d FieldData) { d.(*BinaryVectorFieldData).Data[0] = 0xff }},
+		{"float vector", s.iDataTwoRows.Data[FloatVectorField], func(d FieldData) { d.(*FloatVectorFieldData).Data[0] = 100 }},
+		{"float16 vector", s.iDataTwoRows.Data[Float16VectorField], func(d FieldData) { d.(*Float16VectorFieldData).Data[0] = 0xff }},
+		{"quantized vector", &QuantizedVectorFieldData{Data: []int8{1, 2}, Dim: 2, Scale: 0.5}, func(d FieldData) {
+			d.(*QuantizedVectorFieldData).Data[0] = 100
+		}},
+		{"contiguous array", func() FieldData {
+			data := NewContiguousArrayFieldData(schemapb.DataType_Int32)
+			s.Require().NoError(data.AppendRow(&schemapb.ScalarField{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{1, 2}}}}))
+			return data
+		}(), func(d FieldData) { d.(*ContiguousArrayFieldData).ints[0] = 100 }},
+		{"nullable", func() FieldData {
+			data := NewNullableFieldData(&Int64FieldData{Data: []int64{1}})
+			data.AppendNull()
+			return data
+		}(), func(d FieldData) { d.(*NullableFieldData).FieldData.(*Int64FieldData).Data[0] = 100 }},
+	}
+
+	for _, c := range cases {
+		s.Run(c.name, func() {
+			iData := &InsertData{
+				Data:  map[FieldID]FieldData{RowIDField: &Int64FieldData{Data: make([]int64, c.data.RowNum())}, 200: c.data},
+				Infos: []BlobInfo{{Length: c.data.RowNum()}},
+			}
+			clone := iData.Clone()
+			s.Equal(iData.GetRowNum(), clone.GetRowNum())
+			s.Equal(iData.GetMemorySize(), clone.GetMemorySize())
+			s.Equal(iData.Infos, clone.Infos)
+			s.Equal(c.data, clone.Data[200])
+
+			rowNum, memorySize := clone.GetRowNum(), clone.GetMemorySize()
+			rows := lo.Map(lo.Range(rowNum), func(i int, _ int) string { return fmt.Sprint(clone.Data[200].GetRow(i)) })
+
+			c.mutate(c.data)
+			s.NoError(iData.Append(map[FieldID]interface{}{RowIDField: int64(0), 200: c.data.GetRow(0)}))
+			s.Equal(rowNum, clone.GetRowNum())
+			s.Equal(memorySize, clone.GetMemorySize())
+			for i, row := range rows {
+				s.Equal(row, fmt.Sprint(clone.Data[200].GetRow(i)))
+			}
+		})
+	}
+	s.Nil((*InsertData)(nil).Clone())
+
+	// columns unknown to storage keep their rows
+	unknown := &InsertData{Data: map[FieldID]FieldData{RowIDField: &unknownFieldData{&Int64FieldData{Data: []int64{1, 2}}}}}
+	s.Equal([]int64{1, 2}, unknown.Clone().Data[RowIDField].(*Int64FieldData).Data)
+	selected, err := unknown.SelectFields([]FieldID{RowIDField})
+	s.Require().NoError(err)
+	s.Equal(2, selected.Data[RowIDField].RowNum())
+}
+
+func (s *InsertDataSuite) TestSlice() {
+	for row := 0; row < 2; row++ {
+		sliced, err := s.iDataTwoRows.Slice(row, row+1)
+		s.Require().NoError(err)
+		s.Equal(1, sliced.GetRowNum())
+		s.Equal(len(s.iDataTwoRows.Data), len(sliced.Data))
+		for fieldID, fieldData := range s.iDataTwoRows.Data {
+			s.Equal(1, sliced.Data[fieldID].RowNum(), "field %d", fieldID)
+			s.Equal(fieldData.GetRow(row), sliced.Data[fieldID].GetRow(0), "field %d", fieldID)
+		}
+	}
+
+	empty, err := s.iDataTwoRows.Slice(1, 1)
+	s.NoError(err)
+	s.Equal(0, empty.GetRowNum())
+
+	// appending to the sliced never overwrites the origin
+	int64Row := s.iDataTwoRows.Data[Int64Field].GetRow(1)
+	vectorRow := append([]float32(nil), s.iDataTwoRows.Data[FloatVectorField].GetRow(1).([]float32)...)
+	sliced, err := s.iDataTwoRows.Slice(0, 1)
+	s.Require().NoError(err)
+	s.NoError(sliced.Data[Int64Field].AppendRow(int64(100)))
+	s.NoError(sliced.Data[FloatVectorField].AppendRow([]float32{100, 100, 100, 100}))
+	s.Equal(int64Row, s.iDataTwoRows.Data[Int64Field].GetRow(1))
+	s.Equal(vectorRow, s.iDataTwoRows.Data[FloatVectorField].GetRow(1))
+
+	for _, r := range [][2]int{{-1, 1}, {0, 3}, {2, 1}} {
+		_, err := s.iDataTwoRows.Slice(r[0], r[1])
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+	}
+
+	s.Run("nullable and contiguous array", func() {
+		nullable := NewNullableFieldData(&Int64FieldData{Data: []int64{1}})
+		nullable.AppendNull()
+		s.NoError(nullable.AppendRow(int64(3)))
+		contiguous := NewContiguousArrayFieldData(schemapb.DataType_Int64)
+		for _, row := range [][]int64{{1}, {2, 3}, {}} {
+			s.NoError(contiguous.AppendRow(&schemapb.ScalarField{Data: &schemapb.ScalarField_LongData{LongData: &schemapb.LongArray{Data: row}}}))
+		}
+		for _, fieldData := range []FieldData{nullable, contiguous} {
+			for start := 0; start < 3; start++ {
+				for end := start; end <= 3; end++ {
+					sliced := fieldData.Slice(start, end)
+					s.Equal(end-start, sliced.RowNum())
+					for row := start; row < end; row++ {
+						s.Equal(fieldData.GetRow(row), sliced.GetRow(row-start))
+					}
+				}
+			}
+		}
+	})
+}
+
+func (s *InsertDataSuite) TestTruncate() {
+	truncated := s.iDataTwoRows.Clone()
+	s.Require().Equal(2, truncated.GetRowNum())
+	s.Require().NoError(truncated.Truncate(1))
+	s.Equal(1, truncated.GetRowNum())
+	s.Equal(len(s.iDataOneRow.Data), len(truncated.Data))
+	for fieldID, fieldData := range s.iDataOneRow.Data {
+		s.Equal(1, truncated.Data[fieldID].RowNum(), "field %d", fieldID)
+		s.Equal(fieldData.GetRow(0), truncated.Data[fieldID].GetRow(0), "field %d", fieldID)
+	}
+	s.Less(truncated.GetMemorySize(), s.iDataTwoRows.GetMemorySize())
+	s.Equal(s.iDataOneRow.GetMemorySize(), truncated.GetMemorySize())
+
+	// appending after truncating never overwrites the dropped rows
+	vectorRow := append([]float32(nil), s.iDataTwoRows.Data[FloatVectorField].GetRow(1).([]float32)...)
+	s.NoError(truncated.Data[FloatVectorField].AppendRow([]float32{100, 100, 100, 100}))
+	s.Equal(vectorRow, s.iDataTwoRows.Data[FloatVectorField].GetRow(1))
+
+	s.ErrorIs(s.iDataTwoRows.Clone().Truncate(3), merr.ErrParameterInvalid)
+	s.ErrorIs(s.iDataTwoRows.Clone().Truncate(-1), merr.ErrParameterInvalid)
+
+	empty := s.iDataTwoRows.Clone()
+	s.NoError(empty.Truncate(0))
+	s.Equal(0, empty.GetRowNum())
+}
+
+func (s *InsertDataSuite) TestGetRowSize() {
+	nullable := NewNullableFieldData(&StringFieldData{Data: []string{"a"}})
+	nullable.AppendNull()
+	s.NoError(nullable.AppendRow("bcd"))
+	contiguous := NewContiguousArrayFieldData(schemapb.DataType_VarChar)
+	s.NoError(contiguous.AppendRow(&schemapb.ScalarField{Data: &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: []string{"a", "bc"}}}}))
+	quantized := &QuantizedVectorFieldData{Data: []int8{1, 2, 3, 4}, Dim: 2}
+
+	// fixed overhead of the column which is not counted in any row
+	overhead := func(fieldData FieldData) int {
+		switch fieldData.(type) {
+		case *BinaryVectorFieldData, *FloatVectorFieldData, *Float16VectorFieldData:
+			return 4
+		case *QuantizedVectorFieldData:
+			return 12
+		case *NullableFieldData:
+			// one word of validity bitmap and its rank
+			return 16
+		default:
+			return 0
+		}
+	}
+
+	columns := lo.Values(s.iDataTwoRows.Data)
+	columns = append(columns,
+		nullable, contiguous, quantized,
+	)
+	for _, fieldData := range columns {
+		var size int
+		for i := 0; i < fieldData.RowNum(); i++ {
+			size += fieldData.GetRowSize(i)
+		}
+		s.Equal(fieldData.GetMemorySize()-overhead(fieldData), size, "%T", fieldData)
+	}
+
+	s.Equal(len("a")+16, nullable.GetRowSize(0))
+	s.Equal(0, nullable.GetRowSize(1))
+	s.Equal(len(`{"batch":2}`)+16, s.iDataTwoRows.Data[JSONField].GetRowSize(0))
+}
+
+func (s *InsertDataSuite) TestAppendRows() {
+	rows := []map[FieldID]interface{}{
+		{RowIDField: int64(1), Int64Field: int64(10), StringField: "a", JSONField: []byte(`{"a":1}`), FloatVectorField: []float32{1, 2}},
+		{RowIDField: int64(2), Int64Field: int64(20), StringField: "bb", JSONField: []byte(`{"a":2}`), FloatVectorField: []float32{3, 4}},
+		{RowIDField: int64(3), Int64Field: int64(30), StringField: "ccc", JSONField: []byte(`{"a":3}`), FloatVectorField: []float32{5, 6}},
+	}
+	newData := func() *InsertData {
+		return &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:       &Int64FieldData{},
+			Int64Field:       NewNullableFieldData(&Int64FieldData{}),
+			StringField:      &StringFieldData{},
+			JSONField:        &JSONFieldData{},
+			FloatVectorField: &FloatVectorFieldData{Dim: 2},
+		}}
+	}
+
+	single, batch := newData(), newData()
+	s.NoError(single.Append(rows[0]))
+	s.NoError(batch.Append(rows[0]))
+	for _, row := range rows {
+		s.NoError(single.Append(row))
+	}
+	s.NoError(batch.AppendRows(rows))
+	s.Equal(4, batch.GetRowNum())
+	s.Equal(single.GetMemorySize(), batch.GetMemorySize())
+	for fieldID, fieldData := range single.Data {
+		for i := 0; i < fieldData.RowNum(); i++ {
+			s.Equal(fieldData.GetRow(i), batch.Data[fieldID].GetRow(i))
+		}
+	}
+
+	s.Run("transactional", func() {
+		size := batch.GetMemorySize()
+		invalid := append([]map[FieldID]interface{}{}, rows...)
+		invalid = append(invalid, map[FieldID]interface{}{RowIDField: int64(4), FloatVectorField: []float32{1}})
+		s.Error(batch.AppendRows(invalid))
+		invalid[3] = map[FieldID]interface{}{RowIDField: int64(4), 999: int64(1)}
+		s.Error(batch.AppendRows(invalid))
+		s.Equal(4, batch.GetRowNum())
+		s.Equal(4, batch.Data[FloatVectorField].RowNum())
+		s.Equal(size, batch.GetMemorySize())
+	})
+
+	s.Run("merge failure", func() {
+		iData := &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:  &Int64FieldData{Data: []int64{1, 2}},
+			StringField: &StringFieldData{Data: []string{"a", "b"}},
+			Int64Field:  &cappedFieldData{Int64FieldData: &Int64FieldData{Data: []int64{10, 20}}, cap: 3},
+		}}
+		// rows fit in the staged columns, while the field fails to merge them beyond its cap
+		err := iData.AppendRows([]map[FieldID]interface{}{
+			{RowIDField: int64(3), StringField: "c", Int64Field: int64(30)},
+			{RowIDField: int64(4), StringField: "d", Int64Field: int64(40)},
+		})
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.Equal([]int64{1, 2}, iData.Data[RowIDField].(*Int64FieldData).Data)
+		s.Equal([]string{"a", "b"}, iData.Data[StringField].(*StringFieldData).Data)
+		s.Equal([]int64{10, 20}, iData.Data[Int64Field].(*cappedFieldData).Data)
+	})
+
+	s.Run("infer dim", func() {
+		iData, err := NewInsertData(&schemapb.CollectionSchema{Fields: []*schemapb.FieldSchema{
+			{FieldID: RowIDField, DataType: schemapb.DataType_Int64},
+			{FieldID: FloatVectorField, DataType: schemapb.DataType_FloatVector},
+		}}, WithInferDim())
+		s.Require().NoError(err)
+		s.NoError(iData.AppendRows([]map[FieldID]interface{}{{RowIDField: int64(1)}}))
+		s.NoError(iData.AppendRows([]map[FieldID]interface{}{
+			{RowIDField: int64(2), FloatVectorField: []float32{1, 2}},
+			{RowIDField: int64(3), FloatVectorField: []float32{3, 4}},
+		}))
+		s.Equal(2, iData.Data[FloatVectorField].(*FloatVectorFieldData).Dim)
+		s.Equal(2, iData.Data[FloatVectorField].RowNum())
+		s.Equal(3, iData.GetRowNum())
+	})
+}
+
+// cappedFieldData is a FieldData unknown to storage, which rejects rows beyond its cap.
+type cappedFieldData struct {
+	*Int64FieldData
+	cap int
+}
+
+func (data *cappedFieldData) AppendRow(row interface{}) error {
+	if data.RowNum() >= data.cap {
+		return merr.WrapErrParameterInvalidMsg("row num exceeds cap %d", data.cap)
+	}
+	return data.Int64FieldData.AppendRow(row)
+}
+
+func (data *cappedFieldData) Slice(start, end int) FieldData {
+	return &cappedFieldData{Int64FieldData: data.Int64FieldData.Slice(start, end).(*Int64FieldData), cap: data.cap}
+}
+
+func (s *InsertDataSuite) TestAppendColumn() {
+	newData := func() *InsertData {
+		return &InsertData{Data: map[FieldID]FieldData{
+			RowIDField:       &Int64FieldData{},
+			TimestampField:   &Int64FieldData{},
+			Int64Field:       NewNullableFieldData(&Int64FieldData{}),
+			FloatVectorField: &FloatVectorFieldData{Dim: 2},
+		}}
+	}
+
+	rowWise, columnar := newData(), newData()
+	for i := 0; i < 3; i++ {
+		s.NoError(rowWise.Append(map[FieldID]interface{}{
+			RowIDField:       int64(i),
+			TimestampField:   int64(100 + i),
+			Int64Field:       int64(10 * i),
+			FloatVectorField: []float32{float32(i), float32(i + 1)},
+		}))
+	}
+
+	s.NoError(columnar.AppendColumn(RowIDField, []int64{0, 1}))
+	s.NoError(columnar.AppendColumn(TimestampField, []int64{100, 101}))
+	s.NoError(columnar.AppendColumn(Int64Field, []int64{0, 10}))
+	s.Error(columnar.ValidateRowNum())
+	s.NoError(columnar.AppendColumn(FloatVectorField, []float32{0, 1, 1, 2}))
+	s.NoError(columnar.ValidateRowNum())
+
+	// append the rest, mixing with row-wise append
+	s.NoError(columnar.Append(map[FieldID]interface{}{
+		RowIDField:       int64(2),
+		TimestampField:   int64(102),
+		Int64Field:       int64(20),
+		FloatVectorField: []float32{2, 3},
+	}))
+	s.NoError(columnar.ValidateRowNum())
+	s.False(columnar.IsEmpty())
+	s.Equal(3, columnar.GetRowNum())
+	s.Equal(rowWise.GetMemorySize(), columnar.GetMemorySize())
+	s.True(rowWise.Equal(columnar))
+
+	s.Run("column not retained", func() {
+		data := newData()
+		column := []int64{1, 2}
+		s.NoError(data.AppendColumn(RowIDField, column))
+		column[0] = 100
+		s.EqualValues(1, data.Data[RowIDField].GetRow(0))
+	})
+
+	s.Run("invalid", func() {
+		data := newData()
+		s.Error(data.AppendColumn(999, []int64{1}))
+		s.ErrorIs(data.AppendColumn(RowIDField, []int32{1}), merr.ErrParameterInvalid)
+		s.ErrorIs(data.AppendColumn(FloatVectorField, []float32{1, 2, 3}), merr.ErrParameterInvalid)
+		s.ErrorIs(data.AppendColumn(FloatVectorField, [][]float32{{1, 2}}), merr.ErrParameterInvalid)
+		s.True(data.IsEmpty())
+		s.Zero(data.Data[FloatVectorField].RowNum())
+	})
+}
+
+func (s *InsertDataSuite) TestBulkAppendRows() {
+	columns := make(map[FieldID]FieldData, len(s.iDataTwoRows.Data))
+	for fieldID, fieldData := range s.iDataTwoRows.Data {
+		columns[fieldID] = fieldData
+	}
+	columns[102] = NewNullableFieldData(&Int64FieldData{Data: []int64{7, 8}})
+
+	for fieldID, column := range columns {
+		rowWise, bulk := column.Slice(0, 0), column.Slice(0, 0)
+		for i := 0; i < column.RowNum(); i++ {
+			s.Require().NoError(rowWise.AppendRow(column.GetRow(i)))
+		}
+
+		// append twice to make sure rows are appended after existing ones
+		rows := bulkRowsOf(column)
+		s.Require().NoError(bulk.(BulkAppender).AppendRows(rows), "field %d", fieldID)
+		s.Require().NoError(bulk.(BulkAppender).AppendRows(rows), "field %d", fieldID)
+		for i := 0; i < column.RowNum(); i++ {
+			s.Require().NoError(rowWise.AppendRow(column.GetRow(i)))
+		}
+
+		s.Equal(rowWise.RowNum(), bulk.RowNum(), "field %d", fieldID)
+		s.Equal(rowWise.GetMemorySize(), bulk.GetMemorySize(), "field %d", fieldID)
+		for i := 0; i < rowWise.RowNum(); i++ {
+			s.Equal(rowWise.GetRow(i), bulk.GetRow(i), "field %d", fieldID)
+		}
+
+		// rows of wrong type are rejected without appending anything
+		s.ErrorIs(bulk.(BulkAppender).AppendRows(struct{}{}), merr.ErrParameterInvalid)
+		s.Equal(rowWise.RowNum(), bulk.RowNum())
+	}
+
+	s.Run("flattened vectors", func() {
+		vectors := &FloatVectorFieldData{Dim: 4}
+		s.ErrorIs(vectors.AppendRows([]float32{1, 2, 3}), merr.ErrParameterInvalid)
+		s.ErrorIs((&FloatVectorFieldData{}).AppendRows([]float32{1, 2}), merr.ErrParameterInvalid)
+		s.ErrorIs((&BinaryVectorFieldData{Dim: 16}).AppendRows([]byte{1, 2, 3}), merr.ErrParameterInvalid)
+		s.NoError(vectors.AppendRows([]float32{}))
+		s.Zero(vectors.RowNum())
+	})
+}
+
+// bulkRowsOf returns all rows of column in the form accepted by BulkAppender.AppendRows.
+func bulkRowsOf(column FieldData) interface{} {
+	if nullable, ok := column.(*NullableFieldData); ok {
+		return bulkRowsOf(nullable.FieldData)
+	}
+	return reflect.ValueOf(column).Elem().FieldByName("Data").Interface()
+}
+
+func (s *InsertDataSuite) TestSelectFields() {
+	selected, err := s.iDataTwoRows.SelectFields([]FieldID{RowIDField, Int64Field, FloatVectorField, JSONField})
+	s.Require().NoError(err)
+	s.Equal(4, len(selected.Data))
+	s.Equal(s.iDataTwoRows.GetRowNum(), selected.GetRowNum())
+	s.Equal(s.iDataTwoRows.Infos, selected.Infos)
+	memorySize := 0
+	for _, fieldID := range []FieldID{RowIDField, Int64Field, FloatVectorField, JSONField} {
+		s.Equal(s.iDataTwoRows.Data[fieldID], selected.Data[fieldID])
+		memorySize += s.iDataTwoRows.Data[fieldID].GetMemorySize()
+	}
+	s.Equal(memorySize, selected.GetMemorySize())
+
+	// selected columns are deep copied
+	s.iDataTwoRows.Data[FloatVectorField].(*FloatVectorFieldData).Data[0] = 100
+	s.NotEqual(float32(100), selected.Data[FloatVectorField].(*FloatVectorFieldData).Data[0])
+
+	empty, err := s.iDataTwoRows.SelectFields(nil)
+	s.NoError(err)
+	s.Empty(empty.Data)
+
+	_, err = s.iDataTwoRows.SelectFields([]FieldID{RowIDField, 999})
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestAppendVectorDimNotMatch() {
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		FloatVectorField:   &FloatVectorFieldData{Dim: 4},
+		BinaryVectorField:  &BinaryVectorFieldData{Dim: 16},
+		Float16VectorField: &Float16VectorFieldData{Dim: 4},
+	}}
+
+	cases := []struct {
+		fieldID FieldID
+		row     interface{}
+		msg     string
+	}{
+		{FloatVectorField, []float32{1, 2, 3}, "expected 4, actual 3"},
+		{BinaryVectorField, []byte{1}, "expected 16, actual 8"},
+		{Float16VectorField, []byte{1, 2, 3, 4, 5, 6}, "expected 4, actual 3"},
+	}
+	for _, c := range cases {
+		err := iData.Append(map[FieldID]interface{}{c.fieldID: c.row})
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, fmt.Sprintf("field %d", c.fieldID))
+		s.ErrorContains(err, c.msg)
+		s.Equal(0, iData.Data[c.fieldID].RowNum())
+
+		err = iData.AppendRows([]map[FieldID]interface{}{{c.fieldID: c.row}})
+		s.ErrorIs(err, merr.ErrParameterInvalid)
+		s.ErrorContains(err, c.msg)
+	}
+}
+
+func (s *InsertDataSuite) TestSortByField() {
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		Int64Field:        &Int64FieldData{Data: []int64{3, 1, 2}},
+		StringField:       &StringFieldData{Data: []string{"c", "a", "b"}},
+		FloatVectorField:  &FloatVectorFieldData{Data: []float32{3, 3, 1, 1, 2, 2}, Dim: 2},
+		BinaryVectorField: &BinaryVectorFieldData{Data: []byte{3, 1, 2}, Dim: 8},
+		JSONField:         &JSONFieldData{Data: [][]byte{[]byte(`{"k":3}`), []byte(`{"k":1}`), []byte(`{"k":2}`)}},
+		ArrayField: &ArrayFieldData{ElementType: schemapb.DataType_Int32, Data: []*schemapb.ScalarField{
+			{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{3, 3, 3}}}},
+			{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{1}}}},
+			{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: []int32{2, 2}}}},
+		}},
+	}}
+
+	s.Require().NoError(iData.SortByField(Int64Field))
+	s.Equal([]int64{1, 2, 3}, iData.Data[Int64Field].(*Int64FieldData).Data)
+	s.Equal([]string{"a", "b", "c"}, iData.Data[StringField].(*StringFieldData).Data)
+	s.Equal([]float32{1, 1, 2, 2, 3, 3}, iData.Data[FloatVectorField].(*FloatVectorFieldData).Data)
+	s.Equal([]byte{1, 2, 3}, iData.Data[BinaryVectorField].(*BinaryVectorFieldData).Data)
+	s.Equal([][]byte{[]byte(`{"k":1}`), []byte(`{"k":2}`), []byte(`{"k":3}`)}, iData.Data[JSONField].(*JSONFieldData).Data)
+	arrayData := iData.Data[ArrayField].(*ArrayFieldData)
+	s.Equal([]int32{1}, arrayData.Data[0].GetIntData().GetData())
+	s.Equal([]int32{2, 2}, arrayData.Data[1].GetIntData().GetData())
+	s.Equal([]int32{3, 3, 3}, arrayData.Data[2].GetIntData().GetData())
+
+	// stable for equal keys
+	iData = &InsertData{Data: map[FieldID]FieldData{
+		Int64Field:  &Int64FieldData{Data: []int64{2, 1, 2, 1}},
+		StringField: &StringFieldData{Data: []string{"a", "b", "c", "d"}},
+	}}
+	s.Require().NoError(iData.SortByField(Int64Field))
+	s.Equal([]string{"b", "d", "a", "c"}, iData.Data[StringField].(*StringFieldData).Data)
+
+	s.ErrorIs(iData.SortByField(999), merr.ErrParameterInvalid)
+	for _, fieldID := range []FieldID{FloatVectorField, ArrayField, JSONField} {
+		s.ErrorIs(s.iDataTwoRows.SortByField(fieldID), merr.ErrParameterInvalid)
+	}
+
+	// misaligned fields leave the data untouched
+	iData.Data[FloatField] = &FloatFieldData{Data: []float32{1}}
+	s.ErrorIs(iData.SortByField(Int64Field), merr.ErrParameterInvalid)
+	s.Equal([]int64{1, 1, 2, 2}, iData.Data[Int64Field].(*Int64FieldData).Data)
+}
+
+func (s *InsertDataSuite) TestSortByFieldWrappedColumns() {
+	nullable := NewNullableFieldData(&Int32FieldData{Data: []int32{3}})
+	nullable.AppendNull()
+	s.Require().NoError(nullable.AppendRow(int32(2)))
+	contiguous := NewContiguousArrayFieldData(schemapb.DataType_Int32)
+	for _, row := range [][]int32{{3, 3, 3}, {1}, {2, 2}} {
+		s.Require().NoError(contiguous.AppendRow(&schemapb.ScalarField{Data: &schemapb.ScalarField_IntData{IntData: &schemapb.IntArray{Data: row}}}))
+	}
+	quantized := &QuantizedVectorFieldData{Data: []int8{3, 3, 1, 1, 2, 2}, Dim: 2, Scale: 0.5, Offset: 1}
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		Int64Field:       &Int64FieldData{Data: []int64{3, 1, 2}},
+		Int32Field:       nullable,
+		ArrayField:       contiguous,
+		FloatVectorField: quantized,
+	}}
+
+	s.Require().NoError(iData.SortByField(Int64Field))
+	sortedNullable, ok := iData.Data[Int32Field].(*NullableFieldData)
+	s.Require().True(ok)
+	s.Equal([]bool{false, true, true}, sortedNullable.ValidData())
+	s.Equal([]any{nil, int32(2), int32(3)}, []any{sortedNullable.GetRow(0), sortedNullable.GetRow(1), sortedNullable.GetRow(2)})
+	sortedContiguous, ok := iData.Data[ArrayField].(*ContiguousArrayFieldData)
+	s.Require().True(ok)
+	s.Equal([]int32{1}, sortedContiguous.GetRow(0).(*schemapb.ScalarField).GetIntData().GetData())
+	s.Equal([]int32{3, 3, 3}, sortedContiguous.GetRow(2).(*schemapb.ScalarField).GetIntData().GetData())
+	sortedQuantized, ok := iData.Data[FloatVectorField].(*QuantizedVectorFieldData)
+	s.Require().True(ok)
+	s.Equal([]int8{1, 1, 2, 2, 3, 3}, sortedQuantized.Data)
+	s.Equal(quantized.Scale, sortedQuantized.Scale)
+	s.Equal(quantized.Offset, sortedQuantized.Offset)
+
+	result, err := iData.FilterRows(func(row int) bool { return row != 1 })
+	s.Require().NoError(err)
+	s.Equal([]int64{1, 3}, result.Data[Int64Field].(*Int64FieldData).Data)
+	s.Equal([]bool{false, true}, result.Data[Int32Field].(*NullableFieldData).ValidData())
+
+	iData.Data[JSONField] = &unknownFieldData{&Int64FieldData{Data: []int64{1, 2, 3}}}
+	_, err = iData.FilterRows(func(int) bool { return true })
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestJSONExtractPath() {
+	data := &JSONFieldData{Data: [][]byte{
+		[]byte(`{"meta": {"level": 1, "name": "a"}}`),
+		[]byte(`{"meta": {"level": 2.5}}`),
+		[]byte(`{"meta": {}}`),
+		[]byte(`{"meta": null}`),
+		[]byte(`{"meta": {"level": null, "name": "d"}}`),
+		[]byte(`{"other": 1}`),
+	}}
+
+	// integers and floats are extracted as double
+	column, err := data.ExtractPath("meta.level")
+	s.Require().NoError(err)
+	nullable := column.(*NullableFieldData)
+	s.Equal(6, nullable.RowNum())
+	s.IsType(&DoubleFieldData{}, nullable.FieldData)
+	s.Equal([]bool{true, true, false, false, false, false}, nullable.ValidData())
+	s.Equal(float64(1), nullable.GetRow(0))
+	s.Equal(2.5, nullable.GetRow(1))
+	s.Nil(nullable.GetRow(2))
+
+	column, err = data.ExtractPath("meta.name")
+	s.Require().NoError(err)
+	nullable = column.(*NullableFieldData)
+	s.IsType(&StringFieldData{}, nullable.FieldData)
+	s.Equal([]bool{true, false, false, false, true, false}, nullable.ValidData())
+	s.Equal("d", nullable.GetRow(4))
+
+	column, err = data.ExtractPath("other")
+	s.Require().NoError(err)
+	nullable = column.(*NullableFieldData)
+	s.IsType(&Int64FieldData{}, nullable.FieldData)
+	s.Equal(int64(1), nullable.GetRow(5))
+
+	// mixed types
+	mixed := &JSONFieldData{Data: [][]byte{[]byte(`{"k": 1}`), []byte(`{"k": "1"}`)}}
+	_, err = mixed.ExtractPath("k")
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = data.ExtractPath("meta")
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+
+	_, err = data.ExtractPath("missing")
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = data.ExtractPath("meta..level")
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = (&JSONFieldData{Data: [][]byte{[]byte(`{`)}}).ExtractPath("k")
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+}
+
+func (s *InsertDataSuite) TestNewInsertDataWithCap() {
+	idata, err := NewInsertDataWithCap(s.schema, 100)
+	s.Require().NoError(err)
+	empty, err := NewInsertData(s.schema)
+	s.Require().NoError(err)
+	s.Equal(len(empty.Data), len(idata.Data))
+	s.Equal(empty.GetMemorySize(), idata.GetMemorySize())
+	s.Equal(100, cap(idata.Data[Int64Field].(*Int64FieldData).Data))
+	floatVector := idata.Data[FloatVectorField].(*FloatVectorFieldData)
+	s.Equal(100*floatVector.Dim, cap(floatVector.Data))
+	binaryVector := idata.Data[BinaryVectorField].(*BinaryVectorFieldData)
+	s.Equal(100*binaryVector.Dim/8, cap(binaryVector.Data))
+	s.Equal(100, cap(idata.Data[StringField].(*StringFieldData).Data))
+
+	// memory size reflects appended rows only
+	for row := 0; row < s.iDataTwoRows.GetRowNum(); row++ {
+		values := make(map[FieldID]interface{})
+		for fieldID, fieldData := range s.iDataTwoRows.Data {
+			values[fieldID] = fieldData.GetRow(row)
+		}
+		s.Require().NoError(idata.Append(values))
+		s.Require().NoError(empty.Append(values))
+	}
+	s.Equal(empty.GetMemorySize(), idata.GetMemorySize())
+	s.Equal(100, cap(idata.Data[Int64Field].(*Int64FieldData).Data))
+
+	_, err = NewInsertDataWithCap(s.schema, -1)
+	s.ErrorIs(err, merr.ErrParameterInvalid)
+	_, err = NewInsertDataWithCap(nil, 100)
+	s.Error(err)
+}
+
+func (s *InsertDataSuite) TestEqual() {
+	s.True(s.iDataTwoRows.Equal(s.iDataTwoRows.Clone()))
+	s.True(s.iDataEmpty.Equal(&InsertData{}))
+	s.True((*InsertData)(nil).Equal(s.iDataEmpty))
+	s.False(s.iDataEmpty.Equal(s.iDataOneRow))
+	s.False(s.iDataOneRow.Equal(s.iDataTwoRows))
+
+	// field ids in different insertion order
+	reordered := &InsertData{Data: make(map[FieldID]FieldData)}
+	fieldIDs := lo.Keys(s.iDataTwoRows.Data)
+	sort.Slice(fieldIDs, func(a, b int) bool { return fieldIDs[a] > fieldIDs[b] })
+	for _, fieldID := range fieldIDs {
+		reordered.Data[fieldID] = s.iDataTwoRows.Data[fieldID]
+	}
+	s.True(s.iDataTwoRows.Equal(reordered))
+
+	missing := s.iDataTwoRows.Clone()
+	delete(missing.Data, JSONField)
+	s.False(s.iDataTwoRows.Equal(missing))
+	s.False(missing.Equal(s.iDataTwoRows))
+
+	// single element difference of each field
+	for fieldID := range s.iDataTwoRows.Data {
+		changed := s.iDataTwoRows.Clone()
+		switch data := changed.Data[fieldID].(type) {
+		case *BoolFieldData:
+			data.Data[1] = !data.Data[1]
+		case *Int8FieldData:
+			data.Data[1]++
+		case *Int16FieldData:
+			data.Data[1]++
+		case *Int32FieldData:
+			data.Data[1]++
+		case *Int64FieldData:
+			data.Data[1]++
+		case *FloatFieldData:
+			data.Data[1]++
+		case *DoubleFieldData:
+			data.Data[1]++
+		case *StringFieldData:
+			data.Data[1] += "x"
+		case *BinaryVectorFieldData:
+			data.Data[len(data.Data)-1]++
+		case *FloatVectorFieldData:
+			data.Data[len(data.Data)-1]++
+		case *Float16VectorFieldData:
+			data.Data[len(data.Data)-1]++
+		case *ArrayFieldData:
+			data.Data[1].GetIntData().Data[0]++
+		case *JSONFieldData:
+			data.Data[1][len(data.Data[1])-2]++
+		default:
+			s.FailNow("unexpected field data type", "%T", data)
+		}
+		s.False(s.iDataTwoRows.Equal(changed), "field %d", fieldID)
+		s.False(changed.Equal(s.iDataTwoRows), "field %d", fieldID)
+	}
+}
+
+func (s *InsertDataSuite) TestRowIterator() {
+	it := s.iDataTwoRows.RowIterator()
+	rows := 0
+	for it.Next() {
+		row := it.Index()
+		s.Equal(rows, row)
+		rows++
+		for fieldID, fieldData := range s.iDataTwoRows.Data {
+			value, ok := it.Get(fieldID)
+			s.True(ok)
+			s.Equal(fieldData.GetRow(row), value)
+		}
+
+		getters := map[FieldID]func(FieldID) (any, bool){
+			BoolField:          func(id FieldID) (any, bool) { return it.GetBool(id) },
+			Int8Field:          func(id FieldID) (any, bool) { return it.GetInt8(id) },
+			Int16Field:         func(id FieldID) (any, bool) { return it.GetInt16(id) },
+			Int32Field:         func(id FieldID) (any, bool) { return it.GetInt32(id) },
+			Int64Field:         func(id FieldID) (any, bool) { return it.GetInt64(id) },
+			RowIDField:         func(id FieldID) (any, bool) { return it.GetInt64(id) },
+			TimestampField:     func(id FieldID) (any, bool) { return it.GetInt64(id) },
+			FloatField:         func(id FieldID) (any, bool) { return it.GetFloat(id) },
+			DoubleField:        func(id FieldID) (any, bool) { return it.GetDouble(id) },
+			StringField:        func(id FieldID) (any, bool) { return it.GetString(id) },
+			JSONField:          func(id FieldID) (any, bool) { return it.GetJSON(id) },
+			ArrayField:         func(id FieldID) (any, bool) { return it.GetArray(id) },
+			BinaryVectorField:  func(id FieldID) (any, bool) { return it.GetBinaryVector(id) },
+			FloatVectorField:   func(id FieldID) (any, bool) { return it.GetFloatVector(id) },
+			Float16VectorField: func(id FieldID) (any, bool) { return it.GetFloat16Vector(id) },
+		}
+		s.Equal(len(s.iDataTwoRows.Data), len(getters))
+		for fieldID, get := range getters {
+			value, ok := get(fieldID)
+			s.True(ok, "field %d", fieldID)
+			s.Equal(s.iDataTwoRows.Data[fieldID].GetRow(row), value, "field %d", fieldID)
+		}
+
+		// accessors of mismatched type or absent field
+		_, ok := it.GetInt64(FloatVectorField)
+		s.False(ok)
+		_, ok = it.GetFloatVector(999)
+		s.False(ok)
+		_, ok = it.Get(999)
+		s.False(ok)
+
+		// vectors are shared with the column without copying
+		vector, _ := it.GetFloatVector(FloatVectorField)
+		s.Same(&s.iDataTwoRows.Data[FloatVectorField].(*FloatVectorFieldData).Data[row*4], &vector[0])
+		s.Equal(len(vector), cap(vector))
+	}
+	s.NoError(it.Err())
+	s.Equal(2, rows)
+
+	s.False(s.iDataEmpty.RowIterator().Next())
+
+	s.Run("modified", func() {
+		data := s.iDataTwoRows.Clone()
+		it := data.RowIterator()
+		s.True(it.Next())
+		s.NoError(data.Data[Int64Field].AppendRow(int64(100)))
+		s.False(it.Next())
+		s.ErrorIs(it.Err(), ErrInsertDataModified)
+
+		data = s.iDataTwoRows.Clone()
+		it = data.RowIterator()
+		s.True(it.Next())
+		data.Data[Int64Field] = &Int64FieldData{Data: []int64{1, 2}}
+		s.False(it.Next())
+		s.ErrorIs(it.Err(), ErrInsertDataModified)
+	})
+
+	s.Run("misaligned", func() {
+		data := s.iDataTwoRows.Clone()
+		s.NoError(data.Data[Int64Field].AppendRow(int64(100)))
+		it := data.RowIterator()
+		s.False(it.Next())
+		s.ErrorIs(it.Err(), merr.ErrParameterInvalid)
+	})
+}
+
 func (s *InsertDataSuite) SetupTest() {
 	var err error
 	s.iDataEmpty, err = NewInsertData(s.schema)
@@ -314,6 +2321,30 @@ func (s *ArrayFieldDataSuite) TestArrayFieldData() {
 	err = insertData.Append(fieldIDToData)
 	s.NoError(err)
 	s.Equal(1, insertData.GetRowNum())
-	s.Equal(114, insertData.GetMemorySize())
+	s.Equal(138, insertData.GetMemorySize())
 	s.False(insertData.IsEmpty())
 }
+
+func (s *ArrayFieldDataSuite) TestVarCharArrayMemorySize() {
+	rows := [][]string{{}, {"a"}, {"bc", "defgh"}, {"", strings.Repeat("x", 100), "ij"}}
+	array := &ArrayFieldData{ElementType: schemapb.DataType_VarChar}
+	expected := 0
+	for i, strs := range rows {
+		s.NoError(array.AppendRow(&schemapb.ScalarField{
+			Data: &schemapb.ScalarField_StringData{StringData: &schemapb.StringArray{Data: strs}},
+		}))
+
+		rowSize := stringArrayOverhead
+		for _, str := range strs {
+			rowSize += len(str) + 16
+		}
+		s.Equal(rowSize, array.GetRowSize(i))
+		expected += rowSize
+	}
+	s.Equal(expected, array.GetMemorySize())
+
+	// compaction keeps the accounted size
+	data := &InsertData{Data: map[FieldID]FieldData{ArrayField: array}}
+	s.Require().NoError(data.CompactArrayField(ArrayField))
+	s.Equal(expected, data.Data[ArrayField].GetMemorySize())
+}
@@ -0,0 +1,460 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"math"
+	"reflect"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// Formats supported by EncodeInsertData and DecodeInto,
+// used to transport InsertData in memory queues rather than persisting it.
+const (
+	TransportFormatGob     = "gob"
+	TransportFormatMsgpack = "msgpack"
+)
+
+// columnEnvelope is the transport form of a single field.
+type columnEnvelope struct {
+	FieldID     int64
+	DataType    int64
+	ElementType int64
+	Dim         int64
+	RowNum      int64
+	Payload     []byte
+}
+
+// EncodeInsertData encodes data in provided format, which could be decoded by DecodeInto.
+func EncodeInsertData(format string, data *InsertData) ([]byte, error) {
+	columns := make([]columnEnvelope, 0, len(data.Data))
+	for _, field := range data.SortedFields() {
+		column, err := encodeColumn(field.Data)
+		if err != nil {
+			return nil, err
+		}
+		column.FieldID = field.FieldID
+		columns = append(columns, column)
+	}
+
+	switch format {
+	case TransportFormatGob:
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(columns); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case TransportFormatMsgpack:
+		return encodeColumnsMsgpack(columns), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported transport format %s", format)
+	}
+}
+
+// DecodeInto decodes the output of EncodeInsertData and appends the rows to dst.
+// The decoded fields shall match the fields of dst in id, type and dim.
+func DecodeInto(format string, data []byte, dst *InsertData) error {
+	var columns []columnEnvelope
+	var err error
+	switch format {
+	case TransportFormatGob:
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(&columns)
+	case TransportFormatMsgpack:
+		columns, err = decodeColumnsMsgpack(data)
+	default:
+		return merr.WrapErrParameterInvalidMsg("unsupported transport format %s", format)
+	}
+	if err != nil {
+		return merr.WrapErrParameterInvalidMsg("failed to decode %s buffer: %s", format, err.Error())
+	}
+
+	if len(columns) != len(dst.Data) {
+		return merr.WrapErrParameterInvalidMsg("field num not match, expected %d, actual %d", len(dst.Data), len(columns))
+	}
+	decoded := make(map[FieldID]FieldData, len(columns))
+	for _, column := range columns {
+		fieldData, err := decodeColumn(column)
+		if err != nil {
+			return err
+		}
+		expected, ok := dst.Data[column.FieldID]
+		if !ok {
+			return merr.WrapErrParameterInvalidMsg("field %d not found in destination", column.FieldID)
+		}
+		if reflect.TypeOf(expected) != reflect.TypeOf(fieldData) || getFieldDataDim(expected) != getFieldDataDim(fieldData) {
+			return merr.WrapErrParameterInvalidMsg("field %d not match, expected %T(dim %d), actual %T(dim %d)",
+				column.FieldID, expected, getFieldDataDim(expected), fieldData, getFieldDataDim(fieldData))
+		}
+		decoded[column.FieldID] = fieldData
+	}
+
+	for fieldID, fieldData := range decoded {
+		MergeFieldData(dst, fieldID, fieldData)
+	}
+	return nil
+}
+
+func getFieldDataDim(data FieldData) int {
+	switch data := data.(type) {
+	case *BinaryVectorFieldData:
+		return data.Dim
+	case *FloatVectorFieldData:
+		return data.Dim
+	case *Float16VectorFieldData:
+		return data.Dim
+	case *QuantizedVectorFieldData:
+		return data.Dim
+	default:
+		return 0
+	}
+}
+
+func encodeFixedColumn(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, common.Endian, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeColumn(data FieldData) (columnEnvelope, error) {
+	column := columnEnvelope{
+		Dim:    int64(getFieldDataDim(data)),
+		RowNum: int64(data.RowNum()),
+	}
+	var err error
+	switch data := data.(type) {
+	case *BoolFieldData:
+		column.DataType = int64(schemapb.DataType_Bool)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *Int8FieldData:
+		column.DataType = int64(schemapb.DataType_Int8)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *Int16FieldData:
+		column.DataType = int64(schemapb.DataType_Int16)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *Int32FieldData:
+		column.DataType = int64(schemapb.DataType_Int32)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *Int64FieldData:
+		column.DataType = int64(schemapb.DataType_Int64)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *FloatFieldData:
+		column.DataType = int64(schemapb.DataType_Float)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *DoubleFieldData:
+		column.DataType = int64(schemapb.DataType_Double)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *FloatVectorFieldData:
+		column.DataType = int64(schemapb.DataType_FloatVector)
+		column.Payload, err = encodeFixedColumn(data.Data)
+	case *BinaryVectorFieldData:
+		column.DataType = int64(schemapb.DataType_BinaryVector)
+		column.Payload = data.Data
+	case *Float16VectorFieldData:
+		column.DataType = int64(schemapb.DataType_Float16Vector)
+		column.Payload = data.Data
+	case *StringFieldData:
+		column.DataType = int64(schemapb.DataType_VarChar)
+		rows := make([][]byte, 0, len(data.Data))
+		for _, row := range data.Data {
+			rows = append(rows, []byte(row))
+		}
+		column.Payload = encodeVarLenColumn(rows)
+	case *JSONFieldData:
+		column.DataType = int64(schemapb.DataType_JSON)
+		column.Payload = encodeVarLenColumn(data.Data)
+	case *ArrayFieldData:
+		column.DataType = int64(schemapb.DataType_Array)
+		column.ElementType = int64(data.ElementType)
+		rows := make([][]byte, 0, len(data.Data))
+		for _, row := range data.Data {
+			bs, err := proto.Marshal(row)
+			if err != nil {
+				return column, err
+			}
+			rows = append(rows, bs)
+		}
+		column.Payload = encodeVarLenColumn(rows)
+	default:
+		return column, merr.WrapErrParameterInvalidMsg("unsupported field data type %T", data)
+	}
+	return column, err
+}
+
+func decodeFixedColumn[T any](column columnEnvelope, size int) ([]T, error) {
+	values := make([]T, int(column.RowNum)*size)
+	if err := binary.Read(bytes.NewReader(column.Payload), common.Endian, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func decodeColumn(column columnEnvelope) (FieldData, error) {
+	var fieldData FieldData
+	var err error
+	switch schemapb.DataType(column.DataType) {
+	case schemapb.DataType_Bool:
+		data := &BoolFieldData{}
+		data.Data, err = decodeFixedColumn[bool](column, 1)
+		fieldData = data
+	case schemapb.DataType_Int8:
+		data := &Int8FieldData{}
+		data.Data, err = decodeFixedColumn[int8](column, 1)
+		fieldData = data
+	case schemapb.DataType_Int16:
+		data := &Int16FieldData{}
+		data.Data, err = decodeFixedColumn[int16](column, 1)
+		fieldData = data
+	case schemapb.DataType_Int32:
+		data := &Int32FieldData{}
+		data.Data, err = decodeFixedColumn[int32](column, 1)
+		fieldData = data
+	case schemapb.DataType_Int64:
+		data := &Int64FieldData{}
+		data.Data, err = decodeFixedColumn[int64](column, 1)
+		fieldData = data
+	case schemapb.DataType_Float:
+		data := &FloatFieldData{}
+		data.Data, err = decodeFixedColumn[float32](column, 1)
+		fieldData = data
+	case schemapb.DataType_Double:
+		data := &DoubleFieldData{}
+		data.Data, err = decodeFixedColumn[float64](column, 1)
+		fieldData = data
+	case schemapb.DataType_FloatVector:
+		data := &FloatVectorFieldData{Dim: int(column.Dim)}
+		data.Data, err = decodeFixedColumn[float32](column, data.Dim)
+		fieldData = data
+	case schemapb.DataType_BinaryVector:
+		fieldData = &BinaryVectorFieldData{Dim: int(column.Dim), Data: column.Payload}
+	case schemapb.DataType_Float16Vector:
+		fieldData = &Float16VectorFieldData{Dim: int(column.Dim), Data: column.Payload}
+	case schemapb.DataType_VarChar:
+		var rows [][]byte
+		rows, err = decodeVarLenColumn(column.Payload, int(column.RowNum))
+		data := &StringFieldData{Data: make([]string, 0, len(rows))}
+		for _, row := range rows {
+			data.Data = append(data.Data, string(row))
+		}
+		fieldData = data
+	case schemapb.DataType_JSON:
+		data := &JSONFieldData{}
+		data.Data, err = decodeVarLenColumn(column.Payload, int(column.RowNum))
+		fieldData = data
+	case schemapb.DataType_Array:
+		var rows [][]byte
+		rows, err = decodeVarLenColumn(column.Payload, int(column.RowNum))
+		data := &ArrayFieldData{
+			ElementType: schemapb.DataType(column.ElementType),
+			Data:        make([]*schemapb.ScalarField, 0, len(rows)),
+		}
+		for _, row := range rows {
+			scalar := &schemapb.ScalarField{}
+			if err = proto.Unmarshal(row, scalar); err != nil {
+				break
+			}
+			data.Data = append(data.Data, scalar)
+		}
+		fieldData = data
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported data type %d of field %d", column.DataType, column.FieldID)
+	}
+	if err != nil {
+		return nil, merr.WrapErrParameterInvalidMsg("failed to decode field %d: %s", column.FieldID, err.Error())
+	}
+	if fieldData.RowNum() != int(column.RowNum) {
+		return nil, merr.WrapErrParameterInvalidMsg("row num of field %d not match, expected %d, actual %d",
+			column.FieldID, column.RowNum, fieldData.RowNum())
+	}
+	return fieldData, nil
+}
+
+// encodeColumnsMsgpack encodes columns as a msgpack array of 6-element arrays.
+func encodeColumnsMsgpack(columns []columnEnvelope) []byte {
+	var buf []byte
+	buf = appendMsgpackArrayHeader(buf, len(columns))
+	for _, column := range columns {
+		buf = appendMsgpackArrayHeader(buf, 6)
+		buf = appendMsgpackInt(buf, column.FieldID)
+		buf = appendMsgpackInt(buf, column.DataType)
+		buf = appendMsgpackInt(buf, column.ElementType)
+		buf = appendMsgpackInt(buf, column.Dim)
+		buf = appendMsgpackInt(buf, column.RowNum)
+		buf = appendMsgpackBin(buf, column.Payload)
+	}
+	return buf
+}
+
+func decodeColumnsMsgpack(data []byte) ([]columnEnvelope, error) {
+	r := &msgpackReader{buf: data}
+	n, err := r.readArrayHeader()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]columnEnvelope, 0, n)
+	for i := 0; i < n; i++ {
+		fields, err := r.readArrayHeader()
+		if err != nil {
+			return nil, err
+		}
+		if fields != 6 {
+			return nil, merr.WrapErrParameterInvalidMsg("invalid column element num %d", fields)
+		}
+		var column columnEnvelope
+		for _, v := range []*int64{&column.FieldID, &column.DataType, &column.ElementType, &column.Dim, &column.RowNum} {
+			if *v, err = r.readInt(); err != nil {
+				return nil, err
+			}
+		}
+		if column.Payload, err = r.readBin(); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	if len(r.buf) > 0 {
+		return nil, merr.WrapErrParameterInvalidMsg("unexpected trailing bytes")
+	}
+	return columns, nil
+}
+
+func appendMsgpackArrayHeader(buf []byte, n int) []byte {
+	buf = append(buf, 0xdd)
+	return binary.BigEndian.AppendUint32(buf, uint32(n))
+}
+
+func appendMsgpackInt(buf []byte, v int64) []byte {
+	buf = append(buf, 0xd3)
+	return binary.BigEndian.AppendUint64(buf, uint64(v))
+}
+
+func appendMsgpackBin(buf []byte, v []byte) []byte {
+	buf = append(buf, 0xc6)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+	return append(buf, v...)
+}
+
+// msgpackReader reads the subset of msgpack used by columnEnvelope.
+type msgpackReader struct {
+	buf []byte
+}
+
+func (r *msgpackReader) next(n int) ([]byte, error) {
+	if len(r.buf) < n {
+		return nil, merr.WrapErrParameterInvalidMsg("unexpected end of msgpack buffer")
+	}
+	bs := r.buf[:n]
+	r.buf = r.buf[n:]
+	return bs, nil
+}
+
+func (r *msgpackReader) readUint(size int) (uint64, error) {
+	bs, err := r.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, b := range bs {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+func (r *msgpackReader) readArrayHeader() (int, error) {
+	head, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch {
+	case head[0]&0xf0 == 0x90:
+		n = uint64(head[0] & 0x0f)
+	case head[0] == 0xdc:
+		n, err = r.readUint(2)
+	case head[0] == 0xdd:
+		n, err = r.readUint(4)
+	default:
+		return 0, merr.WrapErrParameterInvalidMsg("expect msgpack array, got 0x%x", head[0])
+	}
+	return int(n), err
+}
+
+func (r *msgpackReader) readInt() (int64, error) {
+	head, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case head[0] <= 0x7f:
+		return int64(head[0]), nil
+	case head[0] >= 0xe0:
+		return int64(int8(head[0])), nil
+	}
+	var v uint64
+	switch head[0] {
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err = r.readUint(1 << (head[0] - 0xcc))
+		if err == nil && v > math.MaxInt64 {
+			return 0, merr.WrapErrParameterInvalidMsg("msgpack integer overflow")
+		}
+		return int64(v), err
+	case 0xd0:
+		v, err = r.readUint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err = r.readUint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err = r.readUint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err = r.readUint(8)
+		return int64(v), err
+	default:
+		return 0, merr.WrapErrParameterInvalidMsg("expect msgpack integer, got 0x%x", head[0])
+	}
+}
+
+func (r *msgpackReader) readBin() ([]byte, error) {
+	head, err := r.next(1)
+	if err != nil {
+		return nil, err
+	}
+	var n uint64
+	switch head[0] {
+	case 0xc4:
+		n, err = r.readUint(1)
+	case 0xc5:
+		n, err = r.readUint(2)
+	case 0xc6:
+		n, err = r.readUint(4)
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("expect msgpack bin, got 0x%x", head[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(n))
+}
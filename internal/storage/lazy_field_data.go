@@ -0,0 +1,287 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/milvus-io/milvus/pkg/util/compressor"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+var (
+	_ FieldData = (*LazyStringFieldData)(nil)
+	_ FieldData = (*LazyJSONFieldData)(nil)
+)
+
+// CompressVarLenColumn encodes a varchar or JSON column and compresses it with provided compressor.
+// The result could be loaded lazily via NewLazyStringFieldData or NewLazyJSONFieldData.
+func CompressVarLenColumn(data FieldData, c compressor.Compressor) ([]byte, error) {
+	var rows [][]byte
+	switch data := data.(type) {
+	case *StringFieldData:
+		rows = make([][]byte, 0, len(data.Data))
+		for _, row := range data.Data {
+			rows = append(rows, []byte(row))
+		}
+	case *JSONFieldData:
+		rows = data.Data
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("unsupported var length column type %T", data)
+	}
+	return c.CompressBytes(encodeVarLenColumn(rows), nil), nil
+}
+
+// encodeVarLenColumn encodes rows as length prefixed values.
+func encodeVarLenColumn(rows [][]byte) []byte {
+	var size int
+	for _, row := range rows {
+		size += binary.MaxVarintLen64 + len(row)
+	}
+	buf := make([]byte, 0, size)
+	for _, row := range rows {
+		buf = binary.AppendUvarint(buf, uint64(len(row)))
+		buf = append(buf, row...)
+	}
+	return buf
+}
+
+func decodeVarLenColumn(buf []byte, rowNum int) ([][]byte, error) {
+	rows := make([][]byte, 0, rowNum)
+	for len(buf) > 0 {
+		length, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < length {
+			return nil, merr.WrapErrIoFailedReason("corrupted var length column")
+		}
+		rows = append(rows, buf[n:n+int(length)])
+		buf = buf[n+int(length):]
+	}
+	if len(rows) != rowNum {
+		return nil, merr.WrapErrIoFailedReason("var length column row num not match")
+	}
+	return rows, nil
+}
+
+// lazyColumn keeps the compressed bytes of a column and decompresses them on first access.
+type lazyColumn struct {
+	once         sync.Once
+	decompressed atomic.Bool
+	rowNum       int
+	compressed   []byte
+	decompressor compressor.Decompressor
+	err          error
+}
+
+// load decompresses the column once and passes the decoded rows to fill.
+func (c *lazyColumn) load(fill func(rows [][]byte)) error {
+	c.once.Do(func() {
+		buf, err := c.decompressor.DecompressBytes(c.compressed, nil)
+		if err != nil {
+			c.err = err
+			return
+		}
+		rows, err := decodeVarLenColumn(buf, c.rowNum)
+		if err != nil {
+			c.err = err
+			return
+		}
+		fill(rows)
+		// release compressed bytes since decompressed ones are cached
+		c.compressed = nil
+		c.decompressed.Store(true)
+	})
+	return c.err
+}
+
+// cloneInto fills clone with the state of c, the compressed bytes are shared since they are never mutated.
+// The clone is marked as decompressed if c is, the caller shall fill the decompressed values.
+func (c *lazyColumn) cloneInto(clone *lazyColumn) {
+	clone.rowNum = c.rowNum
+	clone.compressed = c.compressed
+	clone.decompressor = c.decompressor
+	clone.err = c.err
+	if c.IsDecompressed() {
+		clone.once.Do(func() {})
+		clone.decompressed.Store(true)
+	}
+}
+
+// IsDecompressed returns whether the column has been decompressed.
+func (c *lazyColumn) IsDecompressed() bool {
+	return c.decompressed.Load()
+}
+
+// LazyStringFieldData is a varchar column which is decompressed on first access.
+// GetRow and AppendRow panic if decompression fails, use Column to handle the error.
+type LazyStringFieldData struct {
+	lazyColumn
+	data *StringFieldData
+}
+
+// NewLazyStringFieldData creates a varchar column from the output of CompressVarLenColumn.
+func NewLazyStringFieldData(rowNum int, compressed []byte, decompressor compressor.Decompressor) *LazyStringFieldData {
+	return &LazyStringFieldData{
+		lazyColumn: lazyColumn{
+			rowNum:       rowNum,
+			compressed:   compressed,
+			decompressor: decompressor,
+		},
+	}
+}
+
+// Column decompresses the column if needed and returns the values.
+func (data *LazyStringFieldData) Column() ([]string, error) {
+	err := data.load(func(rows [][]byte) {
+		values := make([]string, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, string(row))
+		}
+		data.data = &StringFieldData{Data: values}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.data.Data, nil
+}
+
+func (data *LazyStringFieldData) clone() *LazyStringFieldData {
+	clone := &LazyStringFieldData{}
+	data.cloneInto(&clone.lazyColumn)
+	if clone.IsDecompressed() {
+		clone.data = &StringFieldData{Data: append([]string(nil), data.data.Data...)}
+	}
+	return clone
+}
+
+func (data *LazyStringFieldData) mustColumn() *StringFieldData {
+	if _, err := data.Column(); err != nil {
+		panic(err)
+	}
+	return data.data
+}
+
+func (data *LazyStringFieldData) RowNum() int {
+	if data.IsDecompressed() {
+		return data.data.RowNum()
+	}
+	return data.rowNum
+}
+
+func (data *LazyStringFieldData) GetRow(i int) any {
+	return data.mustColumn().GetRow(i)
+}
+
+func (data *LazyStringFieldData) AppendRow(row interface{}) error {
+	return data.mustColumn().AppendRow(row)
+}
+
+func (data *LazyStringFieldData) Slice(start, end int) FieldData {
+	return data.mustColumn().Slice(start, end)
+}
+
+// GetRowSize returns the decompressed size of the i-th row.
+func (data *LazyStringFieldData) GetRowSize(i int) int {
+	return data.mustColumn().GetRowSize(i)
+}
+
+// GetMemorySize returns the compressed size until the column is decompressed.
+func (data *LazyStringFieldData) GetMemorySize() int {
+	if data.IsDecompressed() {
+		return data.data.GetMemorySize()
+	}
+	return len(data.compressed)
+}
+
+// LazyJSONFieldData is a JSON column which is decompressed on first access.
+// GetRow and AppendRow panic if decompression fails, use Column to handle the error.
+type LazyJSONFieldData struct {
+	lazyColumn
+	data *JSONFieldData
+}
+
+// NewLazyJSONFieldData creates a JSON column from the output of CompressVarLenColumn.
+func NewLazyJSONFieldData(rowNum int, compressed []byte, decompressor compressor.Decompressor) *LazyJSONFieldData {
+	return &LazyJSONFieldData{
+		lazyColumn: lazyColumn{
+			rowNum:       rowNum,
+			compressed:   compressed,
+			decompressor: decompressor,
+		},
+	}
+}
+
+// Column decompresses the column if needed and returns the values.
+func (data *LazyJSONFieldData) Column() ([][]byte, error) {
+	err := data.load(func(rows [][]byte) {
+		data.data = &JSONFieldData{Data: rows}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.data.Data, nil
+}
+
+func (data *LazyJSONFieldData) clone() *LazyJSONFieldData {
+	clone := &LazyJSONFieldData{}
+	data.cloneInto(&clone.lazyColumn)
+	if clone.IsDecompressed() {
+		clone.data = &JSONFieldData{Data: cloneByteRows(data.data.Data)}
+	}
+	return clone
+}
+
+func (data *LazyJSONFieldData) mustColumn() *JSONFieldData {
+	if _, err := data.Column(); err != nil {
+		panic(err)
+	}
+	return data.data
+}
+
+func (data *LazyJSONFieldData) RowNum() int {
+	if data.IsDecompressed() {
+		return data.data.RowNum()
+	}
+	return data.rowNum
+}
+
+func (data *LazyJSONFieldData) GetRow(i int) any {
+	return data.mustColumn().GetRow(i)
+}
+
+func (data *LazyJSONFieldData) AppendRow(row interface{}) error {
+	return data.mustColumn().AppendRow(row)
+}
+
+func (data *LazyJSONFieldData) Slice(start, end int) FieldData {
+	return data.mustColumn().Slice(start, end)
+}
+
+// GetRowSize returns the decompressed size of the i-th row.
+func (data *LazyJSONFieldData) GetRowSize(i int) int {
+	return data.mustColumn().GetRowSize(i)
+}
+
+// GetMemorySize returns the compressed size until the column is decompressed.
+func (data *LazyJSONFieldData) GetMemorySize() int {
+	if data.IsDecompressed() {
+		return data.data.GetMemorySize()
+	}
+	return len(data.compressed)
+}
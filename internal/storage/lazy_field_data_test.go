@@ -0,0 +1,233 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"io"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/internal/proto/etcdpb"
+	"github.com/milvus-io/milvus/pkg/util/compressor"
+)
+
+// countingDecompressor wraps a zstd decompressor and counts the decompress calls.
+type countingDecompressor struct {
+	*compressor.ZstdDecompressor
+	count int
+	err   error
+}
+
+func (d *countingDecompressor) DecompressBytes(src, dst []byte) ([]byte, error) {
+	d.count++
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.ZstdDecompressor.DecompressBytes(src, dst)
+}
+
+type LazyFieldDataSuite struct {
+	suite.Suite
+
+	compressor   *compressor.ZstdCompressor
+	decompressor *countingDecompressor
+}
+
+func (s *LazyFieldDataSuite) SetupTest() {
+	c, err := compressor.NewZstdCompressor(io.Discard)
+	s.Require().NoError(err)
+	s.compressor = c
+
+	d, err := compressor.NewZstdDecompressor(nil)
+	s.Require().NoError(err)
+	s.decompressor = &countingDecompressor{ZstdDecompressor: d}
+}
+
+func (s *LazyFieldDataSuite) TestLazyString() {
+	origin := &StringFieldData{Data: []string{"a", "bb", "", "a"}}
+	compressed, err := CompressVarLenColumn(origin, s.compressor)
+	s.Require().NoError(err)
+
+	data := NewLazyStringFieldData(origin.RowNum(), compressed, s.decompressor)
+	s.Equal(4, data.RowNum())
+	s.Equal(len(compressed), data.GetMemorySize())
+	s.False(data.IsDecompressed())
+	s.Equal(0, s.decompressor.count)
+
+	s.Equal("bb", data.GetRow(1))
+	s.True(data.IsDecompressed())
+	s.Equal(1, s.decompressor.count)
+
+	column, err := data.Column()
+	s.NoError(err)
+	s.Equal(origin.Data, column)
+	s.Equal(origin.GetMemorySize(), data.GetMemorySize())
+
+	s.NoError(data.AppendRow("ccc"))
+	s.Equal(5, data.RowNum())
+	s.Equal(1, s.decompressor.count)
+}
+
+func (s *LazyFieldDataSuite) TestLazyJSON() {
+	origin := &JSONFieldData{Data: [][]byte{[]byte(`{"batch":1}`), []byte(`{"batch":2}`)}}
+	compressed, err := CompressVarLenColumn(origin, s.compressor)
+	s.Require().NoError(err)
+
+	data := NewLazyJSONFieldData(origin.RowNum(), compressed, s.decompressor)
+	s.Equal(2, data.RowNum())
+	s.Equal(0, s.decompressor.count)
+
+	column, err := data.Column()
+	s.NoError(err)
+	s.Equal(origin.Data, column)
+	s.Equal([]byte(`{"batch":2}`), data.GetRow(1))
+	s.Equal(1, s.decompressor.count)
+}
+
+func (s *LazyFieldDataSuite) TestClone() {
+	origin := &StringFieldData{Data: []string{"a", "bb"}}
+	compressed, err := CompressVarLenColumn(origin, s.compressor)
+	s.Require().NoError(err)
+
+	data := NewLazyStringFieldData(origin.RowNum(), compressed, s.decompressor)
+	clone := data.clone()
+	s.False(clone.IsDecompressed())
+	s.Equal(data.GetMemorySize(), clone.GetMemorySize())
+
+	s.Equal("a", data.GetRow(0))
+	s.NoError(data.AppendRow("ccc"))
+	s.Equal(3, data.RowNum())
+
+	decompressedClone := data.clone()
+	s.True(decompressedClone.IsDecompressed())
+	s.Equal(1, s.decompressor.count)
+	s.Equal(data.GetMemorySize(), decompressedClone.GetMemorySize())
+
+	s.Equal(2, clone.RowNum())
+	s.Equal("bb", clone.GetRow(1))
+	s.Equal(2, s.decompressor.count)
+
+	s.NoError(data.AppendRow("dddd"))
+	s.Equal(3, decompressedClone.RowNum())
+	s.Equal("ccc", decompressedClone.GetRow(2))
+	s.Equal(2, s.decompressor.count)
+}
+
+func (s *LazyFieldDataSuite) TestDecompressFail() {
+	s.decompressor.err = errors.New("mocked")
+	compressed, err := CompressVarLenColumn(&StringFieldData{Data: []string{"a"}}, s.compressor)
+	s.Require().NoError(err)
+
+	data := NewLazyStringFieldData(1, compressed, s.decompressor)
+	_, err = data.Column()
+	s.Error(err)
+	s.False(data.IsDecompressed())
+	s.Panics(func() { data.GetRow(0) })
+
+	_, err = NewLazyStringFieldData(2, compressed, &countingDecompressor{ZstdDecompressor: s.decompressor.ZstdDecompressor}).Column()
+	s.Error(err)
+
+	_, err = CompressVarLenColumn(&Int64FieldData{}, s.compressor)
+	s.Error(err)
+}
+
+func (s *LazyFieldDataSuite) TestSerialize() {
+	schema := &etcdpb.CollectionMeta{
+		ID: CollectionID,
+		Schema: &schemapb.CollectionSchema{
+			Fields: []*schemapb.FieldSchema{
+				{FieldID: RowIDField, Name: "row_id", DataType: schemapb.DataType_Int64},
+				{FieldID: TimestampField, Name: "Timestamp", DataType: schemapb.DataType_Int64},
+				{FieldID: StringField, Name: "field_varchar", DataType: schemapb.DataType_VarChar},
+				{FieldID: JSONField, Name: "field_json", DataType: schemapb.DataType_JSON},
+			},
+		},
+	}
+	varchars := &StringFieldData{Data: []string{"b", "a"}}
+	compressedStrings, err := CompressVarLenColumn(varchars, s.compressor)
+	s.Require().NoError(err)
+	jsons := &JSONFieldData{Data: [][]byte{[]byte(`{"row":2}`), []byte(`{"row":1}`)}}
+	compressedJSONs, err := CompressVarLenColumn(jsons, s.compressor)
+	s.Require().NoError(err)
+
+	data := &InsertData{Data: map[FieldID]FieldData{
+		RowIDField:     &Int64FieldData{Data: []int64{2, 1}},
+		TimestampField: &Int64FieldData{Data: []int64{1, 2}},
+		StringField:    NewLazyStringFieldData(varchars.RowNum(), compressedStrings, s.decompressor),
+		JSONField:      NewLazyJSONFieldData(jsons.RowNum(), compressedJSONs, s.decompressor),
+	}}
+	s.NoError(data.Validate(schema.GetSchema()))
+
+	codec := NewInsertCodecWithSchema(schema)
+	blobs, err := codec.Serialize(PartitionID, SegmentID, data)
+	s.NoError(err)
+	_, _, result, err := codec.Deserialize(blobs)
+	s.NoError(err)
+	s.Equal([]string{"a", "b"}, result.Data[StringField].(*StringFieldData).Data)
+	s.Equal([][]byte{[]byte(`{"row":1}`), []byte(`{"row":2}`)}, result.Data[JSONField].(*JSONFieldData).Data)
+
+	s.Run("decompress fail", func() {
+		s.decompressor.err = errors.New("mocked")
+		data.Data[StringField] = NewLazyStringFieldData(varchars.RowNum(), compressedStrings, s.decompressor)
+		_, err := codec.Serialize(PartitionID, SegmentID, data)
+		s.ErrorContains(err, "mocked")
+	})
+}
+
+func (s *LazyFieldDataSuite) TestSortByField() {
+	varchars := &StringFieldData{Data: []string{"b", "a"}}
+	compressed, err := CompressVarLenColumn(varchars, s.compressor)
+	s.Require().NoError(err)
+	jsons := &JSONFieldData{Data: [][]byte{[]byte(`{"row":2}`), []byte(`{"row":1}`)}}
+	compressedJSONs, err := CompressVarLenColumn(jsons, s.compressor)
+	s.Require().NoError(err)
+
+	data := &InsertData{Data: map[FieldID]FieldData{
+		RowIDField:  &Int64FieldData{Data: []int64{2, 1}},
+		StringField: NewLazyStringFieldData(varchars.RowNum(), compressed, s.decompressor),
+		JSONField:   NewLazyJSONFieldData(jsons.RowNum(), compressedJSONs, s.decompressor),
+	}}
+	s.Require().NoError(data.SortByField(RowIDField))
+	s.Equal([]string{"a", "b"}, data.Data[StringField].(*StringFieldData).Data)
+	s.Equal([][]byte{[]byte(`{"row":1}`), []byte(`{"row":2}`)}, data.Data[JSONField].(*JSONFieldData).Data)
+}
+
+func (s *LazyFieldDataSuite) TestDistinctTerms() {
+	varchars := &StringFieldData{Data: []string{"b", "a", "b"}}
+	compressed, err := CompressVarLenColumn(varchars, s.compressor)
+	s.Require().NoError(err)
+
+	data := &InsertData{Data: map[FieldID]FieldData{
+		StringField: NewLazyStringFieldData(varchars.RowNum(), compressed, s.decompressor),
+	}}
+	terms, counts, err := data.DistinctTerms(StringField, false)
+	s.NoError(err)
+	s.Equal([]string{"a", "b"}, terms)
+	s.Equal([]int{1, 2}, counts)
+
+	s.decompressor.err = errors.New("mock error")
+	data.Data[StringField] = NewLazyStringFieldData(varchars.RowNum(), compressed, s.decompressor)
+	_, _, err = data.DistinctTerms(StringField, false)
+	s.ErrorIs(err, s.decompressor.err)
+}
+
+func TestLazyFieldData(t *testing.T) {
+	suite.Run(t, new(LazyFieldDataSuite))
+}
@@ -102,6 +102,23 @@ func (lcm *LocalChunkManager) MultiWrite(ctx context.Context, contents map[strin
 	return el
 }
 
+// MultipartWrite copies the content of reader to local storage, it fails if reader does not provide size bytes.
+func (lcm *LocalChunkManager) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	if err := os.MkdirAll(path.Dir(filePath), os.ModePerm); err != nil {
+		return merr.WrapErrIoFailed(filePath, err)
+	}
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return merr.WrapErrIoFailed(filePath, err)
+	}
+	defer file.Close()
+	n, err := io.CopyN(file, reader, size)
+	if err != nil {
+		return merr.WrapErrIoFailed(filePath, errors.Wrapf(err, "%d of %d bytes written", n, size))
+	}
+	return file.Sync()
+}
+
 // Exist checks whether chunk is saved to local storage.
 func (lcm *LocalChunkManager) Exist(ctx context.Context, filePath string) (bool, error) {
 	_, err := os.Stat(filePath)
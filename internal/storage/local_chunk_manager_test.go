@@ -17,7 +17,9 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"math/rand"
 	"path"
 	"path/filepath"
 	"testing"
@@ -204,6 +206,27 @@ func TestLocalCM(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("test MultipartWrite", func(t *testing.T) {
+		testMultipartRoot := "test_multipart"
+
+		testCM := NewLocalChunkManager(RootPath(localPath))
+		defer testCM.RemoveWithPrefix(ctx, testCM.RootPath())
+
+		value := make([]byte, 8<<20)
+		rand.Read(value)
+		key := path.Join(localPath, testMultipartRoot, "key_1")
+		err := testCM.MultipartWrite(ctx, key, bytes.NewReader(value), int64(len(value)))
+		assert.NoError(t, err)
+
+		val, err := testCM.Read(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, value, val)
+
+		// reader provides less than size
+		err = testCM.MultipartWrite(ctx, key, bytes.NewReader(value[:10]), int64(len(value)))
+		assert.Error(t, err)
+	})
+
 	t.Run("test Remove", func(t *testing.T) {
 		testRemoveRoot := "test_remove"
 
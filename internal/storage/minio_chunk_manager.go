@@ -38,6 +38,9 @@ import (
 
 var CheckBucketRetryAttempts uint = 20
 
+// multipartPartSize is the part size of MultipartWrite.
+const multipartPartSize = 64 << 20
+
 // MinioChunkManager is responsible for read and write data stored in minio.
 type MinioChunkManager struct {
 	*minio.Client
@@ -147,6 +150,18 @@ func (mcm *MinioChunkManager) MultiWrite(ctx context.Context, kvs map[string][]b
 	return merr.Combine(errors...)
 }
 
+// MultipartWrite streams the content of reader to minio storage in parts of multipartPartSize.
+func (mcm *MinioChunkManager) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	_, err := mcm.putMinioObject(ctx, mcm.bucketName, filePath, reader, size, minio.PutObjectOptions{PartSize: multipartPartSize})
+	if err != nil {
+		log.Warn("failed to put object in parts", zap.String("bucket", mcm.bucketName), zap.String("path", filePath), zap.Int64("size", size), zap.Error(err))
+		return err
+	}
+
+	metrics.PersistentDataKvSize.WithLabelValues(metrics.DataPutLabel).Observe(float64(size))
+	return nil
+}
+
 // Exist checks whether chunk is saved to minio storage.
 func (mcm *MinioChunkManager) Exist(ctx context.Context, filePath string) (bool, error) {
 	_, err := mcm.statMinioObject(ctx, mcm.bucketName, filePath, minio.StatObjectOptions{})
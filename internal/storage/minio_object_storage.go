@@ -166,6 +166,13 @@ func (minioObjectStorage *MinioObjectStorage) PutObject(ctx context.Context, buc
 	return checkObjectStorageError(objectName, err)
 }
 
+func (minioObjectStorage *MinioObjectStorage) PutObjectInParts(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, partSize int64) error {
+	_, err := minioObjectStorage.Client.PutObject(ctx, bucketName, objectName, reader, objectSize, minio.PutObjectOptions{
+		PartSize: uint64(partSize),
+	})
+	return checkObjectStorageError(objectName, err)
+}
+
 func (minioObjectStorage *MinioObjectStorage) StatObject(ctx context.Context, bucketName, objectName string) (int64, error) {
 	info, err := minioObjectStorage.Client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
 	return info.Size, checkObjectStorageError(objectName, err)
@@ -143,6 +143,29 @@ func TestMinioObjectStorage(t *testing.T) {
 		}
 	})
 
+	t.Run("test put in parts", func(t *testing.T) {
+		testCM, err := newMinioObjectStorageWithConfig(ctx, &config)
+		require.NoError(t, err)
+		defer testCM.RemoveBucket(ctx, config.bucketName)
+
+		partSize := int64(5 << 20)
+		value := make([]byte, partSize*2+10)
+		for i := range value {
+			value[i] = byte(i)
+		}
+		err = testCM.PutObjectInParts(ctx, config.bucketName, "parts", bytes.NewReader(value), int64(len(value)), partSize)
+		require.NoError(t, err)
+
+		size, err := testCM.StatObject(ctx, config.bucketName, "parts")
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(value), size)
+		got, err := testCM.GetObject(ctx, config.bucketName, "parts", 0, size)
+		require.NoError(t, err)
+		content, err := io.ReadAll(got)
+		assert.NoError(t, err)
+		assert.Equal(t, value, content)
+	})
+
 	t.Run("test list", func(t *testing.T) {
 		testCM, err := newMinioObjectStorageWithConfig(ctx, &config)
 		assert.Equal(t, err, nil)
@@ -0,0 +1,317 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/common"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+	"github.com/milvus-io/milvus/pkg/util/typeutil"
+)
+
+var _ FieldData = (*NullableFieldData)(nil)
+
+// NullableFieldData wraps a FieldData with the validity bitmap of rows.
+// Only valid rows are stored in the wrapped FieldData, GetRow returns nil for null rows.
+type NullableFieldData struct {
+	FieldData
+	// validity is the bitmap of rows, least significant bit first, the bit of a valid row is set.
+	validity []uint64
+	// ranks records the number of valid rows before each word of validity,
+	// so that a row is located in the wrapped FieldData without scanning the bitmap.
+	ranks  []int
+	rowNum int
+}
+
+// NewNullableFieldData wraps data, all existing rows of which are valid.
+func NewNullableFieldData(data FieldData) *NullableFieldData {
+	nullable := &NullableFieldData{FieldData: data}
+	for i := 0; i < data.RowNum(); i++ {
+		nullable.appendValidity(true)
+	}
+	return nullable
+}
+
+func (data *NullableFieldData) appendValidity(valid bool) {
+	word, bit := data.rowNum/64, data.rowNum%64
+	if word == len(data.validity) {
+		data.ranks = append(data.ranks, data.validBefore(data.rowNum))
+		data.validity = append(data.validity, 0)
+	}
+	if valid {
+		data.validity[word] |= 1 << bit
+	}
+	data.rowNum++
+}
+
+// validBefore returns the number of valid rows before the i-th row, which is the offset of the row in wrapped FieldData.
+func (data *NullableFieldData) validBefore(i int) int {
+	word, bit := i/64, i%64
+	if word == len(data.validity) {
+		if word == 0 {
+			return 0
+		}
+		return data.ranks[word-1] + bits.OnesCount64(data.validity[word-1])
+	}
+	return data.ranks[word] + bits.OnesCount64(data.validity[word]&(1<<bit-1))
+}
+
+// IsValid returns whether the i-th row is not null.
+func (data *NullableFieldData) IsValid(i int) bool {
+	return data.validity[i/64]&(1<<(i%64)) != 0
+}
+
+// AppendNull appends a null row.
+func (data *NullableFieldData) AppendNull() {
+	data.appendValidity(false)
+}
+
+func (data *NullableFieldData) RowNum() int { return data.rowNum }
+
+func (data *NullableFieldData) GetRow(i int) any {
+	if !data.IsValid(i) {
+		return nil
+	}
+	return data.FieldData.GetRow(data.validBefore(i))
+}
+
+// AppendRow appends a null row if row is nil, otherwise appends row to the wrapped FieldData.
+func (data *NullableFieldData) AppendRow(row interface{}) error {
+	if row == nil {
+		data.AppendNull()
+		return nil
+	}
+	if err := data.FieldData.AppendRow(row); err != nil {
+		return err
+	}
+	data.appendValidity(true)
+	return nil
+}
+
+// Slice returns rows [start, end), the valid rows of which are a continuous range of the wrapped FieldData.
+func (data *NullableFieldData) Slice(start, end int) FieldData {
+	sliced := &NullableFieldData{FieldData: data.FieldData.Slice(data.validBefore(start), data.validBefore(end))}
+	for i := start; i < end; i++ {
+		sliced.appendValidity(data.IsValid(i))
+	}
+	return sliced
+}
+
+// AppendRows appends valid rows to the wrapped FieldData if it implements BulkAppender.
+func (data *NullableFieldData) AppendRows(rows interface{}) error {
+	appender, ok := data.FieldData.(BulkAppender)
+	if !ok {
+		return merr.WrapErrParameterInvalidMsg("bulk append to %T is not supported", data.FieldData)
+	}
+	before := data.FieldData.RowNum()
+	if err := appender.AppendRows(rows); err != nil {
+		return err
+	}
+	for offset := before; offset < data.FieldData.RowNum(); offset++ {
+		data.appendValidity(true)
+	}
+	return nil
+}
+
+// AppendNullableRow appends row if valid, otherwise appends a null row and row is ignored.
+func (data *NullableFieldData) AppendNullableRow(row interface{}, valid bool) error {
+	if !valid {
+		data.AppendNull()
+		return nil
+	}
+	return data.AppendRow(row)
+}
+
+// ValidData returns the validity of each row.
+func (data *NullableFieldData) ValidData() []bool {
+	valid := make([]bool, 0, data.rowNum)
+	for i := 0; i < data.rowNum; i++ {
+		valid = append(valid, data.IsValid(i))
+	}
+	return valid
+}
+
+// GetRowSize returns the size of the wrapped row, or 0 for null row. The validity bitmap is counted by the column only.
+func (data *NullableFieldData) GetRowSize(i int) int {
+	if !data.IsValid(i) {
+		return 0
+	}
+	return data.FieldData.GetRowSize(data.validBefore(i))
+}
+
+// GetMemorySize returns the size of wrapped FieldData plus the validity bitmap and its ranks.
+func (data *NullableFieldData) GetMemorySize() int {
+	return data.FieldData.GetMemorySize() + binary.Size(data.validity) + binary.Size(int64(0))*len(data.ranks)
+}
+
+// AppendRowWithValidity appends a row like Append, fields marked false in valid are appended as null
+// regardless of the provided value. Fields absent from valid are treated as valid.
+// The field is wrapped as NullableFieldData once a null is appended, which could not be serialized by InsertCodec.
+func (i *InsertData) AppendRowWithValidity(values map[FieldID]interface{}, valid map[FieldID]bool) error {
+	for fID := range valid {
+		if _, ok := i.Data[fID]; !ok {
+			return fmt.Errorf("Missing field when appending row, got %d", fID)
+		}
+	}
+	for fID, v := range values {
+		if isValid, ok := valid[fID]; ok && !isValid {
+			continue
+		}
+		field, ok := i.Data[fID]
+		if !ok {
+			return fmt.Errorf("Missing field when appending row, got %d", fID)
+		}
+		if err := field.AppendRow(v); err != nil {
+			return err
+		}
+	}
+	for fID, isValid := range valid {
+		if isValid {
+			continue
+		}
+		field, ok := i.Data[fID].(*NullableFieldData)
+		if !ok {
+			field = NewNullableFieldData(i.Data[fID])
+			i.Data[fID] = field
+		}
+		field.AppendNull()
+	}
+	return nil
+}
+
+// AppendPartial appends a row which may omit optional fields, omitted fields are filled with null
+// and recorded as absent, see FieldPresence. System fields and primary key are required.
+// Explicit nil values are appended as null but still treated as present.
+func (i *InsertData) AppendPartial(row map[FieldID]interface{}) error {
+	for fID := range row {
+		if _, ok := i.Data[fID]; !ok {
+			return fmt.Errorf("Missing field when appending row, got %d", fID)
+		}
+	}
+	for fID := range i.Data {
+		if _, ok := row[fID]; !ok && i.isRequiredField(fID) {
+			return merr.WrapErrParameterInvalidMsg("required field %d not provided", fID)
+		}
+	}
+
+	for fID, field := range i.Data {
+		v, ok := row[fID]
+		if ok && v != nil {
+			if err := field.AppendRow(v); err != nil {
+				return err
+			}
+			continue
+		}
+		nullable, isNullable := field.(*NullableFieldData)
+		if !isNullable {
+			nullable = NewNullableFieldData(field)
+			i.Data[fID] = nullable
+		}
+		if !ok {
+			if i.missing == nil {
+				i.missing = make(map[FieldID][]int)
+			}
+			i.missing[fID] = append(i.missing[fID], nullable.RowNum())
+		}
+		nullable.AppendNull()
+	}
+	return nil
+}
+
+// AppendWithDefaults appends a row like AppendPartial, except that fields of schema omitted in row
+// are filled with their DefaultValue if any. Omitted optional fields without default are appended as null,
+// omitted system fields and primary key are still an error.
+func (i *InsertData) AppendWithDefaults(row map[FieldID]interface{}, schema *schemapb.CollectionSchema) error {
+	if schema == nil {
+		return merr.WrapErrParameterInvalidMsg("nil schema")
+	}
+	filled := make(map[FieldID]interface{}, len(schema.GetFields()))
+	for fID, v := range row {
+		filled[fID] = v
+	}
+	for _, field := range schema.GetFields() {
+		if _, ok := row[field.GetFieldID()]; ok {
+			continue
+		}
+		if field.GetDefaultValue() == nil {
+			if common.IsSystemField(field.GetFieldID()) || field.GetIsPrimaryKey() {
+				return merr.WrapErrParameterInvalidMsg("required field %d not provided", field.GetFieldID())
+			}
+			continue
+		}
+		v, err := defaultValueOf(field)
+		if err != nil {
+			return err
+		}
+		filled[field.GetFieldID()] = v
+	}
+	return i.AppendPartial(filled)
+}
+
+// defaultValueOf returns the DefaultValue of field in the row form of its FieldData.
+func defaultValueOf(field *schemapb.FieldSchema) (interface{}, error) {
+	value := field.GetDefaultValue()
+	switch field.GetDataType() {
+	case schemapb.DataType_Bool:
+		return value.GetBoolData(), nil
+	case schemapb.DataType_Int8:
+		return int8(value.GetIntData()), nil
+	case schemapb.DataType_Int16:
+		return int16(value.GetIntData()), nil
+	case schemapb.DataType_Int32:
+		return value.GetIntData(), nil
+	case schemapb.DataType_Int64:
+		return value.GetLongData(), nil
+	case schemapb.DataType_Float:
+		return value.GetFloatData(), nil
+	case schemapb.DataType_Double:
+		return value.GetDoubleData(), nil
+	case schemapb.DataType_String, schemapb.DataType_VarChar:
+		return value.GetStringData(), nil
+	default:
+		return nil, merr.WrapErrParameterInvalidMsg("default value of data type %s not supported", field.GetDataType().String())
+	}
+}
+
+// FieldPresence returns whether each row of the field is present,
+// only rows omitted in AppendPartial are marked absent.
+func (i *InsertData) FieldPresence(fieldID FieldID) []bool {
+	field, ok := i.Data[fieldID]
+	if !ok {
+		return nil
+	}
+	presence := make([]bool, field.RowNum())
+	for idx := range presence {
+		presence[idx] = true
+	}
+	for _, offset := range i.missing[fieldID] {
+		presence[offset] = false
+	}
+	return presence
+}
+
+func (i *InsertData) isRequiredField(fieldID FieldID) bool {
+	if common.IsSystemField(fieldID) {
+		return true
+	}
+	field := typeutil.GetField(i.schema, fieldID)
+	return field != nil && field.GetIsPrimaryKey()
+}
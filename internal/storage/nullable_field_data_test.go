@@ -0,0 +1,264 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+func TestAppendRowWithValidity(t *testing.T) {
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		Int64Field:  &Int64FieldData{},
+		StringField: &StringFieldData{},
+		FloatField:  &FloatFieldData{},
+	}}
+
+	err := iData.AppendRowWithValidity(map[FieldID]interface{}{
+		Int64Field:  int64(1),
+		StringField: "a",
+		FloatField:  float32(1),
+	}, nil)
+	require.NoError(t, err)
+	// value is ignored for invalid field
+	err = iData.AppendRowWithValidity(map[FieldID]interface{}{
+		Int64Field:  int64(2),
+		StringField: "b",
+		FloatField:  float32(2),
+	}, map[FieldID]bool{StringField: false, FloatField: true})
+	require.NoError(t, err)
+	err = iData.AppendRowWithValidity(map[FieldID]interface{}{
+		Int64Field:  int64(3),
+		StringField: "c",
+	}, map[FieldID]bool{FloatField: false})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, iData.Data[Int64Field].RowNum())
+	assert.IsType(t, &Int64FieldData{}, iData.Data[Int64Field])
+	assert.Equal(t, int64(2), iData.Data[Int64Field].GetRow(1))
+
+	strings, ok := iData.Data[StringField].(*NullableFieldData)
+	require.True(t, ok)
+	assert.Equal(t, 3, strings.RowNum())
+	assert.Equal(t, "a", strings.GetRow(0))
+	assert.Nil(t, strings.GetRow(1))
+	assert.False(t, strings.IsValid(1))
+	assert.Equal(t, "c", strings.GetRow(2))
+
+	floats, ok := iData.Data[FloatField].(*NullableFieldData)
+	require.True(t, ok)
+	assert.Equal(t, float32(1), floats.GetRow(0))
+	assert.Equal(t, float32(2), floats.GetRow(1))
+	assert.Nil(t, floats.GetRow(2))
+	// one word of validity bitmap and its rank
+	assert.Equal(t, 2*4+8+8, floats.GetMemorySize())
+
+	// nil value of wrapped field is null
+	err = iData.Append(map[FieldID]interface{}{
+		Int64Field:  int64(4),
+		StringField: nil,
+		FloatField:  float32(4),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, strings.GetRow(3))
+	assert.Equal(t, float32(4), floats.GetRow(3))
+
+	err = iData.AppendRowWithValidity(map[FieldID]interface{}{}, map[FieldID]bool{999: false})
+	assert.Error(t, err)
+}
+
+func TestAppendPartial(t *testing.T) {
+	iData, err := NewInsertData(genTestCollectionMeta().Schema)
+	require.NoError(t, err)
+	base := func(i int64) map[FieldID]interface{} {
+		return map[FieldID]interface{}{
+			RowIDField:     i,
+			TimestampField: i,
+			Int64Field:     i,
+		}
+	}
+
+	row := base(1)
+	row[StringField] = "a"
+	row[FloatField] = float32(1)
+	require.NoError(t, iData.AppendPartial(row))
+	row = base(2)
+	row[StringField] = "b"
+	require.NoError(t, iData.AppendPartial(row))
+	row = base(3)
+	row[FloatField] = nil
+	require.NoError(t, iData.AppendPartial(row))
+
+	assert.Equal(t, 3, iData.GetRowNum())
+	for _, field := range iData.Data {
+		assert.Equal(t, 3, field.RowNum())
+	}
+	assert.Equal(t, []bool{true, true, true}, iData.FieldPresence(Int64Field))
+	assert.Equal(t, []bool{true, true, false}, iData.FieldPresence(StringField))
+	// explicit null is present
+	assert.Equal(t, []bool{true, false, true}, iData.FieldPresence(FloatField))
+	assert.Equal(t, []bool{false, false, false}, iData.FieldPresence(DoubleField))
+	assert.Nil(t, iData.FieldPresence(999))
+
+	assert.Equal(t, "b", iData.Data[StringField].GetRow(1))
+	assert.Nil(t, iData.Data[StringField].GetRow(2))
+	assert.Equal(t, float32(1), iData.Data[FloatField].GetRow(0))
+	assert.Nil(t, iData.Data[FloatField].GetRow(1))
+	assert.Nil(t, iData.Data[FloatField].GetRow(2))
+
+	// required fields could not be omitted
+	row = base(4)
+	delete(row, Int64Field)
+	assert.ErrorIs(t, iData.AppendPartial(row), merr.ErrParameterInvalid)
+	row = base(4)
+	row[999] = int64(1)
+	assert.Error(t, iData.AppendPartial(row))
+	assert.Equal(t, 3, iData.GetRowNum())
+}
+
+func TestAppendWithDefaults(t *testing.T) {
+	schema := genTestCollectionMeta().Schema
+	for _, field := range schema.GetFields() {
+		if field.GetFieldID() == Int32Field {
+			field.DefaultValue = &schemapb.ValueField{Data: &schemapb.ValueField_IntData{IntData: 7}}
+		}
+	}
+	iData, err := NewInsertData(schema)
+	require.NoError(t, err)
+	row := map[FieldID]interface{}{
+		RowIDField:     int64(1),
+		TimestampField: int64(1),
+		Int64Field:     int64(1),
+		Int32Field:     int32(3),
+	}
+	require.NoError(t, iData.AppendWithDefaults(row, schema))
+
+	// field with default
+	delete(row, Int32Field)
+	require.NoError(t, iData.AppendWithDefaults(row, schema))
+	assert.Equal(t, int32(3), iData.Data[Int32Field].GetRow(0))
+	assert.Equal(t, int32(7), iData.Data[Int32Field].GetRow(1))
+	assert.Equal(t, []bool{true, true}, iData.FieldPresence(Int32Field))
+
+	// nullable field without default
+	assert.Nil(t, iData.Data[StringField].GetRow(1))
+	assert.Equal(t, []bool{false, false}, iData.FieldPresence(StringField))
+	assert.Equal(t, 2, iData.GetRowNum())
+
+	// required field missing
+	delete(row, Int64Field)
+	assert.ErrorIs(t, iData.AppendWithDefaults(row, schema), merr.ErrParameterInvalid)
+	assert.ErrorIs(t, iData.AppendWithDefaults(row, nil), merr.ErrParameterInvalid)
+	assert.Equal(t, 2, iData.GetRowNum())
+}
+
+func TestAppendNullableRow(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     FieldData
+		values   []interface{}
+		rowSize  func(v interface{}) int
+		expected []interface{}
+	}{
+		{
+			name:     "int64",
+			data:     &Int64FieldData{},
+			values:   []interface{}{int64(1), int64(2), int64(3), int64(4)},
+			rowSize:  func(interface{}) int { return 8 },
+			expected: []interface{}{int64(1), nil, int64(3), nil},
+		},
+		{
+			name:     "string",
+			data:     &StringFieldData{},
+			values:   []interface{}{"a", "bb", "ccc", "dddd"},
+			rowSize:  func(v interface{}) int { return len(v.(string)) + 16 },
+			expected: []interface{}{"a", nil, "ccc", nil},
+		},
+		{
+			name:     "double",
+			data:     &DoubleFieldData{},
+			values:   []interface{}{float64(1), float64(2), float64(3), float64(4)},
+			rowSize:  func(interface{}) int { return 8 },
+			expected: []interface{}{float64(1), nil, float64(3), nil},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := NewNullableFieldData(c.data)
+			// one word of validity bitmap and its rank
+			memorySize := 8 + 8
+			for i, v := range c.values {
+				valid := i%2 == 0
+				require.NoError(t, data.AppendNullableRow(v, valid))
+				if valid {
+					memorySize += c.rowSize(v)
+				}
+			}
+
+			assert.Equal(t, len(c.values), data.RowNum())
+			assert.Equal(t, []bool{true, false, true, false}, data.ValidData())
+			for i, expected := range c.expected {
+				assert.Equal(t, expected, data.GetRow(i))
+			}
+			assert.Equal(t, memorySize, data.GetMemorySize())
+		})
+	}
+
+	data := NewNullableFieldData(&Int64FieldData{})
+	assert.Error(t, data.AppendNullableRow("a", true))
+	// value of null row is not validated
+	assert.NoError(t, data.AppendNullableRow("a", false))
+	assert.Equal(t, 1, data.RowNum())
+}
+
+func TestNullableFieldDataBitmap(t *testing.T) {
+	const rowNum = 200
+	data := NewNullableFieldData(&Int64FieldData{})
+	for i := 0; i < rowNum; i++ {
+		require.NoError(t, data.AppendNullableRow(int64(i), i%3 != 0))
+	}
+	assert.Equal(t, rowNum, data.RowNum())
+	// 4 words of bitmap and their ranks for 200 rows, no per row offset
+	assert.Equal(t, data.FieldData.GetMemorySize()+4*8+4*8, data.GetMemorySize())
+	for i := 0; i < rowNum; i++ {
+		if i%3 == 0 {
+			assert.Nil(t, data.GetRow(i))
+		} else {
+			assert.Equal(t, int64(i), data.GetRow(i))
+		}
+	}
+
+	// slices across words
+	for _, r := range [][2]int{{0, 0}, {0, 64}, {3, 70}, {63, 129}, {128, 200}, {0, 200}} {
+		sliced := data.Slice(r[0], r[1]).(*NullableFieldData)
+		require.Equal(t, r[1]-r[0], sliced.RowNum())
+		for i := r[0]; i < r[1]; i++ {
+			assert.Equal(t, data.GetRow(i), sliced.GetRow(i-r[0]))
+		}
+	}
+
+	clone := cloneFieldData(data).(*NullableFieldData)
+	require.NoError(t, clone.AppendNullableRow(int64(rowNum), true))
+	assert.Equal(t, rowNum, data.RowNum())
+	assert.Equal(t, int64(rowNum), clone.GetRow(rowNum))
+}
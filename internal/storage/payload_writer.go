@@ -46,6 +46,8 @@ type NativePayloadWriter struct {
 	flushedRows int
 	output      *bytes.Buffer
 	releaseOnce sync.Once
+	// uncompressed skips compression for small payload
+	uncompressed bool
 }
 
 func NewPayloadWriter(colType schemapb.DataType, dim ...int) (PayloadWriterInterface, error) {
@@ -472,6 +474,11 @@ func (w *NativePayloadWriter) FinishPayloadWriter() error {
 		parquet.WithCompression(compress.Codecs.Zstd),
 		parquet.WithCompressionLevel(3),
 	)
+	if w.uncompressed {
+		props = parquet.NewWriterProperties(
+			parquet.WithCompression(compress.Codecs.Uncompressed),
+		)
+	}
 	return pqarrow.WriteTable(table,
 		w.output,
 		1024*1024*1024,
@@ -480,6 +487,11 @@ func (w *NativePayloadWriter) FinishPayloadWriter() error {
 	)
 }
 
+// disableCompression makes the payload stored uncompressed, shall be called before FinishPayloadWriter.
+func (w *NativePayloadWriter) disableCompression() {
+	w.uncompressed = true
+}
+
 func (w *NativePayloadWriter) GetPayloadBufferFromWriter() ([]byte, error) {
 	data := w.output.Bytes()
 
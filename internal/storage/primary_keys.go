@@ -0,0 +1,125 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// PrimaryKeys is a column of primary keys of the same type,
+// which stores the raw values instead of boxing each key as PrimaryKey.
+type PrimaryKeys interface {
+	// Append appends pks, which shall be of the same type as the column.
+	Append(pks ...PrimaryKey) error
+	Get(idx int) PrimaryKey
+	Type() schemapb.DataType
+	Len() int
+	// Size returns the memory size of keys in bytes.
+	Size() int64
+}
+
+var (
+	_ PrimaryKeys = (*Int64PrimaryKeys)(nil)
+	_ PrimaryKeys = (*VarcharPrimaryKeys)(nil)
+)
+
+type Int64PrimaryKeys struct {
+	values []int64
+}
+
+func NewInt64PrimaryKeys(values ...int64) *Int64PrimaryKeys {
+	return &Int64PrimaryKeys{values: append([]int64(nil), values...)}
+}
+
+func (pks *Int64PrimaryKeys) Append(values ...PrimaryKey) error {
+	for _, pk := range values {
+		ipk, ok := pk.(*Int64PrimaryKey)
+		if !ok {
+			return merr.WrapErrParameterInvalid(schemapb.DataType_Int64.String(), pk.Type().String(), "primary key type not match")
+		}
+		pks.values = append(pks.values, ipk.Value)
+	}
+	return nil
+}
+
+func (pks *Int64PrimaryKeys) Get(idx int) PrimaryKey {
+	return NewInt64PrimaryKey(pks.values[idx])
+}
+
+// Values returns the raw keys, which shall not be modified.
+func (pks *Int64PrimaryKeys) Values() []int64 {
+	return pks.values
+}
+
+func (pks *Int64PrimaryKeys) Type() schemapb.DataType {
+	return schemapb.DataType_Int64
+}
+
+func (pks *Int64PrimaryKeys) Len() int {
+	return len(pks.values)
+}
+
+func (pks *Int64PrimaryKeys) Size() int64 {
+	return int64(len(pks.values) * 8)
+}
+
+type VarcharPrimaryKeys struct {
+	values []string
+	size   int64
+}
+
+func NewVarcharPrimaryKeys(values ...string) *VarcharPrimaryKeys {
+	pks := &VarcharPrimaryKeys{values: append([]string(nil), values...)}
+	for _, v := range values {
+		pks.size += NewVarCharPrimaryKey(v).Size()
+	}
+	return pks
+}
+
+func (pks *VarcharPrimaryKeys) Append(values ...PrimaryKey) error {
+	for _, pk := range values {
+		vpk, ok := pk.(*VarCharPrimaryKey)
+		if !ok {
+			return merr.WrapErrParameterInvalid(schemapb.DataType_VarChar.String(), pk.Type().String(), "primary key type not match")
+		}
+		pks.values = append(pks.values, vpk.Value)
+		pks.size += vpk.Size()
+	}
+	return nil
+}
+
+func (pks *VarcharPrimaryKeys) Get(idx int) PrimaryKey {
+	return NewVarCharPrimaryKey(pks.values[idx])
+}
+
+// Values returns the raw keys, which shall not be modified.
+func (pks *VarcharPrimaryKeys) Values() []string {
+	return pks.values
+}
+
+func (pks *VarcharPrimaryKeys) Type() schemapb.DataType {
+	return schemapb.DataType_VarChar
+}
+
+func (pks *VarcharPrimaryKeys) Len() int {
+	return len(pks.values)
+}
+
+func (pks *VarcharPrimaryKeys) Size() int64 {
+	return pks.size
+}
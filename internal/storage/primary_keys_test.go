@@ -0,0 +1,53 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+func TestPrimaryKeys(t *testing.T) {
+	t.Run("int64", func(t *testing.T) {
+		pks := NewInt64PrimaryKeys(1, 2)
+		assert.NoError(t, pks.Append(NewInt64PrimaryKey(3)))
+		assert.Equal(t, 3, pks.Len())
+		assert.Equal(t, []int64{1, 2, 3}, pks.Values())
+		assert.True(t, pks.Get(2).EQ(NewInt64PrimaryKey(3)))
+		assert.EqualValues(t, 24, pks.Size())
+		assert.Equal(t, schemapb.DataType_Int64, pks.Type())
+
+		assert.ErrorIs(t, pks.Append(NewVarCharPrimaryKey("a")), merr.ErrParameterInvalid)
+		assert.Equal(t, 3, pks.Len())
+	})
+
+	t.Run("varchar", func(t *testing.T) {
+		pks := NewVarcharPrimaryKeys("a")
+		assert.NoError(t, pks.Append(NewVarCharPrimaryKey("bc")))
+		assert.Equal(t, 2, pks.Len())
+		assert.Equal(t, []string{"a", "bc"}, pks.Values())
+		assert.True(t, pks.Get(1).EQ(NewVarCharPrimaryKey("bc")))
+		assert.Equal(t, NewVarCharPrimaryKey("a").Size()+NewVarCharPrimaryKey("bc").Size(), pks.Size())
+		assert.Equal(t, schemapb.DataType_VarChar, pks.Type())
+
+		assert.ErrorIs(t, pks.Append(NewInt64PrimaryKey(1)), merr.ErrParameterInvalid)
+	})
+}
@@ -0,0 +1,133 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+var _ FieldData = (*QuantizedVectorFieldData)(nil)
+
+// QuantizedVectorFieldData is a float vector column quantized into int8 values.
+// The original value is reconstructed approximately as float32(v)*Scale + Offset.
+type QuantizedVectorFieldData struct {
+	Data   []int8
+	Dim    int
+	Scale  float32
+	Offset float32
+}
+
+// QuantizeVectorField replaces the float vector column of fieldID with an int8 quantized column,
+// the scale and offset are shared by all rows and kept in the new column.
+// Quantized column shall be converted back with Dequantize before serialized by InsertCodec.
+func (i *InsertData) QuantizeVectorField(fieldID FieldID) (scale, offset float32, err error) {
+	fieldData, ok := i.Data[fieldID]
+	if !ok {
+		return 0, 0, merr.WrapErrParameterInvalidMsg("field %d not found", fieldID)
+	}
+	vectors, ok := fieldData.(*FloatVectorFieldData)
+	if !ok {
+		return 0, 0, merr.WrapErrParameterInvalidMsg("field %d is not float vector, type %T", fieldID, fieldData)
+	}
+
+	quantized := &QuantizedVectorFieldData{Dim: vectors.Dim, Scale: 1}
+	if len(vectors.Data) > 0 {
+		minVal, maxVal := vectors.Data[0], vectors.Data[0]
+		for _, v := range vectors.Data {
+			if v < minVal {
+				minVal = v
+			}
+			if v > maxVal {
+				maxVal = v
+			}
+		}
+		if maxVal > minVal {
+			quantized.Scale = (maxVal - minVal) / (math.MaxInt8 - math.MinInt8)
+		}
+		// minVal is mapped to math.MinInt8
+		quantized.Offset = minVal - math.MinInt8*quantized.Scale
+	}
+	quantized.Data = make([]int8, 0, len(vectors.Data))
+	for _, v := range vectors.Data {
+		quantized.Data = append(quantized.Data, quantized.quantize(v))
+	}
+
+	i.Data[fieldID] = quantized
+	return quantized.Scale, quantized.Offset, nil
+}
+
+func (data *QuantizedVectorFieldData) quantize(v float32) int8 {
+	q := math.Round(float64((v - data.Offset) / data.Scale))
+	return int8(math.Max(math.MinInt8, math.Min(math.MaxInt8, q)))
+}
+
+func (data *QuantizedVectorFieldData) dequantize(q []int8) []float32 {
+	v := make([]float32, 0, len(q))
+	for _, val := range q {
+		v = append(v, float32(val)*data.Scale+data.Offset)
+	}
+	return v
+}
+
+// Dequantize reconstructs the float vector column.
+func (data *QuantizedVectorFieldData) Dequantize() *FloatVectorFieldData {
+	return &FloatVectorFieldData{
+		Data: data.dequantize(data.Data),
+		Dim:  data.Dim,
+	}
+}
+
+func (data *QuantizedVectorFieldData) RowNum() int { return len(data.Data) / data.Dim }
+
+// GetRow returns the dequantized vector of the i-th row.
+func (data *QuantizedVectorFieldData) GetRow(i int) any {
+	return data.dequantize(data.Data[i*data.Dim : (i+1)*data.Dim])
+}
+
+// Slice returns rows [start, end) with the same scale and offset.
+func (data *QuantizedVectorFieldData) Slice(start, end int) FieldData {
+	return &QuantizedVectorFieldData{
+		Data:   data.Data[start*data.Dim : end*data.Dim : end*data.Dim],
+		Dim:    data.Dim,
+		Scale:  data.Scale,
+		Offset: data.Offset,
+	}
+}
+
+// AppendRow quantizes the float vector with the existing scale and offset,
+// values out of range are clamped.
+func (data *QuantizedVectorFieldData) AppendRow(row interface{}) error {
+	v, ok := row.([]float32)
+	if !ok || len(v) != data.Dim {
+		return merr.WrapErrParameterInvalid("[]float32", row, "Wrong row type")
+	}
+	for _, val := range v {
+		data.Data = append(data.Data, data.quantize(val))
+	}
+	return nil
+}
+
+// GetRowSize returns the size of quantized values of a row.
+func (data *QuantizedVectorFieldData) GetRowSize(i int) int { return data.Dim }
+
+// GetMemorySize returns the size of quantized values plus dim, scale and offset.
+func (data *QuantizedVectorFieldData) GetMemorySize() int {
+	return binary.Size(data.Data) + 12
+}
@@ -0,0 +1,75 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+func TestQuantizeVectorField(t *testing.T) {
+	const (
+		dim    = 8
+		rowNum = 100
+	)
+	vectors := make([]float32, 0, dim*rowNum)
+	for i := 0; i < dim*rowNum; i++ {
+		vectors = append(vectors, float32(math.Sin(float64(i)))*10)
+	}
+	iData := &InsertData{Data: map[FieldID]FieldData{
+		FloatVectorField: &FloatVectorFieldData{Data: vectors, Dim: dim},
+		Int64Field:       &Int64FieldData{Data: []int64{1}},
+	}}
+
+	scale, offset, err := iData.QuantizeVectorField(FloatVectorField)
+	require.NoError(t, err)
+	quantized, ok := iData.Data[FloatVectorField].(*QuantizedVectorFieldData)
+	require.True(t, ok)
+	assert.Equal(t, scale, quantized.Scale)
+	assert.Equal(t, offset, quantized.Offset)
+	assert.Equal(t, rowNum, quantized.RowNum())
+	assert.Equal(t, dim*rowNum+12, quantized.GetMemorySize())
+
+	// max error is half of the scale
+	tolerance := float64(scale)/2 + 1e-4
+	restored := quantized.Dequantize()
+	assert.Equal(t, dim, restored.Dim)
+	for i, v := range vectors {
+		assert.InDelta(t, v, restored.Data[i], tolerance)
+	}
+	row := quantized.GetRow(1).([]float32)
+	for j, v := range vectors[dim : 2*dim] {
+		assert.InDelta(t, v, row[j], tolerance)
+	}
+
+	err = quantized.AppendRow(vectors[:dim])
+	assert.NoError(t, err)
+	assert.Equal(t, rowNum+1, quantized.RowNum())
+	assert.Equal(t, quantized.Data[:dim], quantized.Data[rowNum*dim:])
+	err = quantized.AppendRow([]float32{1})
+	assert.Error(t, err)
+
+	_, _, err = iData.QuantizeVectorField(Int64Field)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+	_, _, err = iData.QuantizeVectorField(999)
+	assert.ErrorIs(t, err, merr.ErrParameterInvalid)
+}
@@ -48,6 +48,8 @@ const (
 type ObjectStorage interface {
 	GetObject(ctx context.Context, bucketName, objectName string, offset int64, size int64) (FileReader, error)
 	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64) error
+	// PutObjectInParts uploads the object in parts (blocks) of partSize, each part in a separate request.
+	PutObjectInParts(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, partSize int64) error
 	StatObject(ctx context.Context, bucketName, objectName string) (int64, error)
 	ListObjects(ctx context.Context, bucketName string, prefix string, recursive bool) ([]string, []time.Time, error)
 	RemoveObject(ctx context.Context, bucketName, objectName string) error
@@ -123,7 +125,7 @@ func (mcm *RemoteChunkManager) Size(ctx context.Context, filePath string) (int64
 
 // Write writes the data to minio storage.
 func (mcm *RemoteChunkManager) Write(ctx context.Context, filePath string, content []byte) error {
-	err := mcm.putObject(ctx, mcm.bucketName, filePath, bytes.NewReader(content), int64(len(content)))
+	err := mcm.putObject(ctx, mcm.bucketName, filePath, bytes.NewReader(content), int64(len(content)), 0)
 	if err != nil {
 		log.Warn("failed to put object", zap.String("bucket", mcm.bucketName), zap.String("path", filePath), zap.Error(err))
 		return err
@@ -146,6 +148,19 @@ func (mcm *RemoteChunkManager) MultiWrite(ctx context.Context, kvs map[string][]
 	return el
 }
 
+// MultipartWrite streams the content of reader to the object storage in parts of multipartPartSize,
+// as multipart upload for S3 compatible storages and block upload for Azure.
+func (mcm *RemoteChunkManager) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	err := mcm.putObject(ctx, mcm.bucketName, filePath, reader, size, multipartPartSize)
+	if err != nil {
+		log.Warn("failed to put object in parts", zap.String("bucket", mcm.bucketName), zap.String("path", filePath), zap.Int64("size", size), zap.Error(err))
+		return err
+	}
+
+	metrics.PersistentDataKvSize.WithLabelValues(metrics.DataPutLabel).Observe(float64(size))
+	return nil
+}
+
 // Exist checks whether chunk is saved to minio storage.
 func (mcm *RemoteChunkManager) Exist(ctx context.Context, filePath string) (bool, error) {
 	_, err := mcm.getObjectSize(ctx, mcm.bucketName, filePath)
@@ -326,10 +341,16 @@ func (mcm *RemoteChunkManager) getObject(ctx context.Context, bucketName, object
 	return reader, err
 }
 
-func (mcm *RemoteChunkManager) putObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64) error {
+// putObject uploads the object in parts of partSize if it's positive, in a single request otherwise.
+func (mcm *RemoteChunkManager) putObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, partSize int64) error {
 	start := timerecord.NewTimeRecorder("putObject")
 
-	err := mcm.client.PutObject(ctx, bucketName, objectName, reader, objectSize)
+	var err error
+	if partSize > 0 {
+		err = mcm.client.PutObjectInParts(ctx, bucketName, objectName, reader, objectSize, partSize)
+	} else {
+		err = mcm.client.PutObject(ctx, bucketName, objectName, reader, objectSize)
+	}
 	metrics.PersistentDataOpCounter.WithLabelValues(metrics.DataPutLabel, metrics.TotalLabel).Inc()
 	if err == nil {
 		metrics.PersistentDataRequestLatency.WithLabelValues(metrics.DataPutLabel).
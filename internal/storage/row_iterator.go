@@ -0,0 +1,221 @@
+// Licensed to the LF AI & Data foundation under one
+// or more contributor license agreements. See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership. The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/cockroachdb/errors"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/schemapb"
+	"github.com/milvus-io/milvus/pkg/util/merr"
+)
+
+// ErrInsertDataModified is the error that the InsertData is modified during iteration.
+var ErrInsertDataModified = errors.New("insert data modified during iteration")
+
+// RowIterator iterates the rows of InsertData, the typed accessors read the current row
+// from the underlying columns without boxing the values or copying the vectors.
+// Accessors shall be called only after Next returns true.
+type RowIterator struct {
+	data    *InsertData
+	columns map[FieldID]FieldData
+	// snapshot is taken at creation to detect modification
+	snapshot []columnSnapshot
+	rowNum   int
+	row      int
+	err      error
+}
+
+type columnSnapshot struct {
+	fieldID FieldID
+	data    FieldData
+	rowNum  int
+}
+
+// RowIterator returns an iterator over the rows of i, which shall not be modified until the iteration ends.
+// Fields shall have the same row number.
+func (i *InsertData) RowIterator() *RowIterator {
+	it := &RowIterator{
+		data:     i,
+		columns:  make(map[FieldID]FieldData, len(i.Data)),
+		snapshot: make([]columnSnapshot, 0, len(i.Data)),
+		rowNum:   -1,
+		row:      -1,
+	}
+	for fieldID, fieldData := range i.Data {
+		it.columns[fieldID] = fieldData
+		it.snapshot = append(it.snapshot, columnSnapshot{fieldID: fieldID, data: fieldData, rowNum: fieldData.RowNum()})
+		if it.rowNum >= 0 && fieldData.RowNum() != it.rowNum {
+			it.err = merr.WrapErrParameterInvalidMsg("row num of field %d is %d, expected %d", fieldID, fieldData.RowNum(), it.rowNum)
+		}
+		it.rowNum = fieldData.RowNum()
+	}
+	return it
+}
+
+// Next moves to the next row, returns false after the last row or once an error occurs, see Err.
+func (it *RowIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.modified() {
+		it.err = errors.Wrapf(ErrInsertDataModified, "at row %d", it.row)
+		return false
+	}
+	if it.row+1 >= it.rowNum {
+		return false
+	}
+	it.row++
+	return true
+}
+
+func (it *RowIterator) modified() bool {
+	if len(it.data.Data) != len(it.snapshot) {
+		return true
+	}
+	for _, column := range it.snapshot {
+		if it.data.Data[column.fieldID] != column.data || column.data.RowNum() != column.rowNum {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the error stopped the iteration, nil if the iteration finished normally.
+func (it *RowIterator) Err() error { return it.err }
+
+// Index returns the offset of the current row.
+func (it *RowIterator) Index() int { return it.row }
+
+// Get returns the value of fieldID in the current row like FieldData.GetRow, false if the field does not exist.
+func (it *RowIterator) Get(fieldID FieldID) (any, bool) {
+	fieldData, ok := it.columns[fieldID]
+	if !ok {
+		return nil, false
+	}
+	return fieldData.GetRow(it.row), true
+}
+
+// GetBool returns the value of fieldID in the current row, false if the field is not a bool field.
+func (it *RowIterator) GetBool(fieldID FieldID) (bool, bool) {
+	if d, ok := it.columns[fieldID].(*BoolFieldData); ok {
+		return d.Data[it.row], true
+	}
+	return false, false
+}
+
+// GetInt8 returns the value of fieldID in the current row, false if the field is not an int8 field.
+func (it *RowIterator) GetInt8(fieldID FieldID) (int8, bool) {
+	if d, ok := it.columns[fieldID].(*Int8FieldData); ok {
+		return d.Data[it.row], true
+	}
+	return 0, false
+}
+
+// GetInt16 returns the value of fieldID in the current row, false if the field is not an int16 field.
+func (it *RowIterator) GetInt16(fieldID FieldID) (int16, bool) {
+	if d, ok := it.columns[fieldID].(*Int16FieldData); ok {
+		return d.Data[it.row], true
+	}
+	return 0, false
+}
+
+// GetInt32 returns the value of fieldID in the current row, false if the field is not an int32 field.
+func (it *RowIterator) GetInt32(fieldID FieldID) (int32, bool) {
+	if d, ok := it.columns[fieldID].(*Int32FieldData); ok {
+		return d.Data[it.row], true
+	}
+	return 0, false
+}
+
+// GetInt64 returns the value of fieldID in the current row, false if the field is not an int64 field.
+func (it *RowIterator) GetInt64(fieldID FieldID) (int64, bool) {
+	if d, ok := it.columns[fieldID].(*Int64FieldData); ok {
+		return d.Data[it.row], true
+	}
+	return 0, false
+}
+
+// GetFloat returns the value of fieldID in the current row, false if the field is not a float field.
+func (it *RowIterator) GetFloat(fieldID FieldID) (float32, bool) {
+	if d, ok := it.columns[fieldID].(*FloatFieldData); ok {
+		return d.Data[it.row], true
+	}
+	return 0, false
+}
+
+// GetDouble returns the value of fieldID in the current row, false if the field is not a double field.
+func (it *RowIterator) GetDouble(fieldID FieldID) (float64, bool) {
+	if d, ok := it.columns[fieldID].(*DoubleFieldData); ok {
+		return d.Data[it.row], true
+	}
+	return 0, false
+}
+
+// GetString returns the value of fieldID in the current row, false if the field is not a string field.
+func (it *RowIterator) GetString(fieldID FieldID) (string, bool) {
+	if d, ok := it.columns[fieldID].(*StringFieldData); ok {
+		return d.Data[it.row], true
+	}
+	return "", false
+}
+
+// GetJSON returns the value of fieldID in the current row, false if the field is not a json field.
+// The returned bytes are shared with the column.
+func (it *RowIterator) GetJSON(fieldID FieldID) ([]byte, bool) {
+	if d, ok := it.columns[fieldID].(*JSONFieldData); ok {
+		return d.Data[it.row], true
+	}
+	return nil, false
+}
+
+// GetArray returns the value of fieldID in the current row, false if the field is not an array field.
+func (it *RowIterator) GetArray(fieldID FieldID) (*schemapb.ScalarField, bool) {
+	if d, ok := it.columns[fieldID].(*ArrayFieldData); ok {
+		return d.Data[it.row], true
+	}
+	return nil, false
+}
+
+// GetFloatVector returns the vector of fieldID in the current row, false if the field is not a float vector field.
+// The returned slice is shared with the column, appending to it never overwrites the column.
+func (it *RowIterator) GetFloatVector(fieldID FieldID) ([]float32, bool) {
+	if d, ok := it.columns[fieldID].(*FloatVectorFieldData); ok {
+		start, end := it.row*d.Dim, (it.row+1)*d.Dim
+		return d.Data[start:end:end], true
+	}
+	return nil, false
+}
+
+// GetBinaryVector returns the vector of fieldID in the current row, false if the field is not a binary vector field.
+// The returned slice is shared with the column, appending to it never overwrites the column.
+func (it *RowIterator) GetBinaryVector(fieldID FieldID) ([]byte, bool) {
+	if d, ok := it.columns[fieldID].(*BinaryVectorFieldData); ok {
+		start, end := it.row*d.Dim/8, (it.row+1)*d.Dim/8
+		return d.Data[start:end:end], true
+	}
+	return nil, false
+}
+
+// GetFloat16Vector returns the vector of fieldID in the current row, false if the field is not a float16 vector field.
+// The returned slice is shared with the column, appending to it never overwrites the column.
+func (it *RowIterator) GetFloat16Vector(fieldID FieldID) ([]byte, bool) {
+	if d, ok := it.columns[fieldID].(*Float16VectorFieldData); ok {
+		start, end := it.row*d.Dim*2, (it.row+1)*d.Dim*2
+		return d.Data[start:end:end], true
+	}
+	return nil, false
+}
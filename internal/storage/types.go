@@ -54,6 +54,9 @@ type ChunkManager interface {
 	Write(ctx context.Context, filePath string, content []byte) error
 	// MultiWrite writes multi @content to @filePath.
 	MultiWrite(ctx context.Context, contents map[string][]byte) error
+	// MultipartWrite streams @size bytes of @reader to @filePath in parts,
+	// so that large content is neither buffered as a whole nor uploaded in a single request.
+	MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error
 	// Exist returns true if @filePath exists.
 	Exist(ctx context.Context, filePath string) (bool, error)
 	// Read reads @filePath and returns content.
@@ -71,6 +71,9 @@ func (fg *TimeTickedFlowGraph) SetEdges(nodeName string, out []string) error {
 		}
 		maxQueueLength := outNode.node.MaxQueueLength()
 		outNode.inputChannel = make(chan []Msg, maxQueueLength)
+		if observer, ok := outNode.node.(inputQueueObserver); ok {
+			observer.SetInputQueue(outNode.inputChannel)
+		}
 		currentNode.downstream = outNode
 	}
 
@@ -196,6 +196,25 @@ func TestTimeTickedFlowGraph_AddNode(t *testing.T) {
 	assert.Equal(t, len(fg.nodeCtx), 2)
 }
 
+func TestTimeTickedFlowGraph_InputQueue(t *testing.T) {
+	fg, _, _, cancel, err := createExampleFlowGraph()
+	assert.NoError(t, err)
+	defer cancel()
+
+	b := fg.nodeCtx["NodeB"]
+	node := b.node.(*nodeB)
+	assert.Equal(t, 0, node.InputQueueLength())
+	for i := 0; i < 3; i++ {
+		b.inputChannel <- []Msg{&numMsg{num: float64(i)}}
+	}
+	assert.Equal(t, 3, node.InputQueueLength())
+	<-b.inputChannel
+	assert.Equal(t, 2, node.InputQueueLength())
+
+	// input node has no upstream
+	assert.Equal(t, 0, fg.nodeCtx["NodeA"].node.(*nodeA).InputQueueLength())
+}
+
 func TestTimeTickedFlowGraph_Start(t *testing.T) {
 	fg, inputChan, outputChan, cancel, err := createExampleFlowGraph()
 	assert.NoError(t, err)
@@ -51,6 +51,13 @@ type Node interface {
 type BaseNode struct {
 	maxQueueLength int32
 	maxParallelism int32
+	// inputQueue is the input channel of the node, set when the node is linked to its upstream
+	inputQueue chan []Msg
+}
+
+// inputQueueObserver is implemented by the nodes observing the length of their input queue, e.g. BaseNode.
+type inputQueueObserver interface {
+	SetInputQueue(queue chan []Msg)
 }
 
 // manage nodeCtx
@@ -218,6 +225,16 @@ func (node *BaseNode) SetMaxParallelism(n int32) {
 	node.maxParallelism = n
 }
 
+// SetInputQueue is used to set the input channel of the node, so that the node could observe its length
+func (node *BaseNode) SetInputQueue(queue chan []Msg) {
+	node.inputQueue = queue
+}
+
+// InputQueueLength returns the number of messages waiting in the input queue, 0 if the node has no upstream
+func (node *BaseNode) InputQueueLength() int {
+	return len(node.inputQueue)
+}
+
 // IsInputNode returns whether Node is InputNode, BaseNode is not InputNode by default
 func (node *BaseNode) IsInputNode() bool {
 	return false
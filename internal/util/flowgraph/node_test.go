@@ -139,5 +139,11 @@ func TestBaseNode(t *testing.T) {
 
 	assert.Equal(t, false, node.IsInputNode())
 
+	assert.Equal(t, 0, node.InputQueueLength())
+	queue := make(chan []Msg, val)
+	node.SetInputQueue(queue)
+	queue <- []Msg{}
+	assert.Equal(t, 1, node.InputQueueLength())
+
 	node.Close()
 }
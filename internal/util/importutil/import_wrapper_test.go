@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"math"
 	"os"
 	"path"
@@ -78,6 +79,10 @@ func (mc *MockChunkManager) MultiWrite(ctx context.Context, contents map[string]
 	return nil
 }
 
+func (mc *MockChunkManager) MultipartWrite(ctx context.Context, filePath string, reader io.Reader, size int64) error {
+	return nil
+}
+
 func (mc *MockChunkManager) Exist(ctx context.Context, filePath string) (bool, error) {
 	return true, nil
 }
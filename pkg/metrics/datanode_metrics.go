@@ -221,6 +221,81 @@ var (
 			nodeIDLabelName,
 			channelNameLabelName,
 		})
+
+	// DataNodeSyncTaskCount counts the finished sync tasks by origin and result.
+	DataNodeSyncTaskCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.DataNodeRole,
+			Name:      "sync_task_count",
+			Help:      "count of finished sync tasks",
+		}, []string{
+			nodeIDLabelName,
+			taskOriginLabelName,
+			statusLabelName,
+		})
+
+	// DataNodeSyncTaskNum records the number of tracked sync tasks by state.
+	DataNodeSyncTaskNum = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.DataNodeRole,
+			Name:      "sync_task_num",
+			Help:      "number of pending and running sync tasks",
+		}, []string{
+			nodeIDLabelName,
+			syncTaskStateLabelName,
+		})
+
+	// DataNodeSyncOldestCheckpoint records the physical time in milliseconds of the oldest checkpoint
+	// among the tracked sync tasks of each channel.
+	DataNodeSyncOldestCheckpoint = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.DataNodeRole,
+			Name:      "sync_oldest_checkpoint",
+			Help:      "physical time of the oldest checkpoint of tracked sync tasks in milliseconds",
+		}, []string{
+			nodeIDLabelName,
+			channelNameLabelName,
+		})
+
+	// DataNodeUpdateChannelCheckpointCount counts the channel checkpoint updates by result.
+	DataNodeUpdateChannelCheckpointCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.DataNodeRole,
+			Name:      "update_channel_checkpoint_count",
+			Help:      "count of channel checkpoint updates",
+		}, []string{
+			nodeIDLabelName,
+			channelNameLabelName,
+			statusLabelName,
+		})
+
+	// DataNodeFlowGraphBatchTimeRange records the width of the latest time range processed by flow graph.
+	DataNodeFlowGraphBatchTimeRange = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.DataNodeRole,
+			Name:      "flowgraph_batch_time_range",
+			Help:      "width of the time range of latest flow graph batch in milliseconds",
+		}, []string{
+			nodeIDLabelName,
+			channelNameLabelName,
+		})
+
+	// DataNodeFlowGraphQueueSaturation records the occupancy of the flow graph input queue, 1 means back-pressured.
+	DataNodeFlowGraphQueueSaturation = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: typeutil.DataNodeRole,
+			Name:      "flowgraph_queue_saturation",
+			Help:      "ratio of the input queue length to the max queue length of flow graph",
+		}, []string{
+			nodeIDLabelName,
+			channelNameLabelName,
+		})
 )
 
 // RegisterDataNode registers DataNode metrics
@@ -243,6 +318,12 @@ func RegisterDataNode(registry *prometheus.Registry) {
 	registry.MustRegister(DataNodeMsgDispatcherTtLag)
 	registry.MustRegister(DataNodeCompactionLatencyInQueue)
 	registry.MustRegister(DataNodeFlowGraphBufferDataSize)
+	registry.MustRegister(DataNodeUpdateChannelCheckpointCount)
+	registry.MustRegister(DataNodeSyncTaskCount)
+	registry.MustRegister(DataNodeSyncTaskNum)
+	registry.MustRegister(DataNodeSyncOldestCheckpoint)
+	registry.MustRegister(DataNodeFlowGraphBatchTimeRange)
+	registry.MustRegister(DataNodeFlowGraphQueueSaturation)
 }
 
 func CleanupDataNodeCollectionMetrics(nodeID int64, collectionID int64, channel string) {
@@ -277,3 +358,15 @@ func CleanupDataNodeCollectionMetrics(nodeID int64, collectionID int64, channel
 		collectionIDLabelName: fmt.Sprint(collectionID),
 	})
 }
+
+// CleanupDataNodeChannelMetrics removes the metrics labeled by the virtual channel,
+// it shall be called once the channel is released from the datanode.
+func CleanupDataNodeChannelMetrics(nodeID int64, channel string) {
+	labels := prometheus.Labels{
+		nodeIDLabelName:      fmt.Sprint(nodeID),
+		channelNameLabelName: channel,
+	}
+	DataNodeUpdateChannelCheckpointCount.DeletePartialMatch(labels)
+	DataNodeFlowGraphBatchTimeRange.Delete(labels)
+	DataNodeFlowGraphQueueSaturation.Delete(labels)
+}
@@ -47,6 +47,9 @@ const (
 	FailedIndexTaskLabel     = "failed"
 	RecycledIndexTaskLabel   = "recycled"
 
+	PendingSyncTaskLabel = "pending"
+	RunningSyncTaskLabel = "running"
+
 	// Note: below must matchcommonpb.SegmentState_name fields.
 	SealedSegmentLabel   = "Sealed"
 	GrowingSegmentLabel  = "Growing"
@@ -71,6 +74,8 @@ const (
 	collectionIDLabelName    = "collection_id"
 	partitionIDLabelName     = "partition_id"
 	channelNameLabelName     = "channel_name"
+	taskOriginLabelName      = "task_origin"
+	syncTaskStateLabelName   = "sync_task_state"
 	functionLabelName        = "function_name"
 	queryTypeLabelName       = "query_type"
 	collectionName           = "collection_name"
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,3 +51,22 @@ func TestGetRegisterer(t *testing.T) {
 	assert.NotNil(t, register)
 	assert.Equal(t, r, register)
 }
+
+func TestCleanupDataNodeChannelMetrics(t *testing.T) {
+	channel, other := "by-dev-rootcoord-dml_0_100v0", "by-dev-rootcoord-dml_1_100v0"
+	for _, ch := range []string{channel, other} {
+		DataNodeUpdateChannelCheckpointCount.WithLabelValues("1", ch, SuccessLabel).Inc()
+		DataNodeUpdateChannelCheckpointCount.WithLabelValues("1", ch, FailLabel).Inc()
+		DataNodeFlowGraphBatchTimeRange.WithLabelValues("1", ch).Set(100)
+		DataNodeFlowGraphQueueSaturation.WithLabelValues("1", ch).Set(0.5)
+	}
+
+	CleanupDataNodeChannelMetrics(1, channel)
+	assert.Equal(t, 2, testutil.CollectAndCount(DataNodeUpdateChannelCheckpointCount))
+	assert.Equal(t, 1, testutil.CollectAndCount(DataNodeFlowGraphBatchTimeRange))
+	assert.Equal(t, 1, testutil.CollectAndCount(DataNodeFlowGraphQueueSaturation))
+	CleanupDataNodeChannelMetrics(1, other)
+	assert.Equal(t, 0, testutil.CollectAndCount(DataNodeUpdateChannelCheckpointCount))
+	assert.Equal(t, 0, testutil.CollectAndCount(DataNodeFlowGraphBatchTimeRange))
+	assert.Equal(t, 0, testutil.CollectAndCount(DataNodeFlowGraphQueueSaturation))
+}
@@ -2591,12 +2591,25 @@ type dataNodeConfig struct {
 	FlushDeleteBufferBytes ParamItem `refreshable:"true"`
 	BinLogMaxSize          ParamItem `refreshable:"true"`
 	SyncPeriod             ParamItem `refreshable:"true"`
+	ScheduledFlushInterval ParamItem `refreshable:"false"`
 
 	// watchEvent
 	WatchEventTicklerInterval ParamItem `refreshable:"false"`
 
 	// io concurrency to add segment
 	IOConcurrency ParamItem `refreshable:"false"`
+	// blobs larger than it are uploaded in parts
+	MultipartUploadThreshold ParamItem `refreshable:"false"`
+	// compression of sync delta and stats logs
+	SyncBlobCodec ParamItem `refreshable:"false"`
+	// upload checksum sidecar of sync blobs
+	SyncBlobChecksum ParamItem `refreshable:"false"`
+	// max number of columns serialized concurrently by a sync task
+	SyncSerializeParallelism ParamItem `refreshable:"false"`
+	// share the result of in-flight sync task with its re-submissions
+	SyncTaskDedup ParamItem `refreshable:"false"`
+	// timeout of waiting for the in-flight sync tasks when datanode stops
+	SyncDrainTimeout ParamItem `refreshable:"true"`
 
 	// Concurrency to handle compaction file read
 	FileReadConcurrency ParamItem `refreshable:"false"`
@@ -2622,6 +2635,7 @@ type dataNodeConfig struct {
 	ChannelWorkPoolSize ParamItem `refreshable:"true"`
 
 	UpdateChannelCheckpointMaxParallel ParamItem `refreshable:"true"`
+	CloseFlushTimeout                  ParamItem `refreshable:"true"`
 }
 
 func (p *dataNodeConfig) init(base *BaseTable) {
@@ -2754,6 +2768,14 @@ func (p *dataNodeConfig) init(base *BaseTable) {
 	}
 	p.SyncPeriod.Init(base.mgr)
 
+	p.ScheduledFlushInterval = ParamItem{
+		Key:          "dataNode.segment.scheduledFlushInterval",
+		Version:      "2.4.0",
+		DefaultValue: "0",
+		Doc:          "The interval in seconds to flush buffers older than syncPeriod without other triggers, 0 means disabled.",
+	}
+	p.ScheduledFlushInterval.Init(base.mgr)
+
 	p.WatchEventTicklerInterval = ParamItem{
 		Key:          "datanode.segment.watchEventTicklerInterval",
 		Version:      "2.2.3",
@@ -2768,6 +2790,54 @@ func (p *dataNodeConfig) init(base *BaseTable) {
 	}
 	p.IOConcurrency.Init(base.mgr)
 
+	p.MultipartUploadThreshold = ParamItem{
+		Key:          "dataNode.dataSync.multipartUploadThreshold",
+		Version:      "2.4.0",
+		DefaultValue: "0",
+		Doc:          "The size in bytes above which a sync blob is uploaded in parts, 0 means disabled.",
+	}
+	p.MultipartUploadThreshold.Init(base.mgr)
+
+	p.SyncBlobCodec = ParamItem{
+		Key:          "dataNode.dataSync.blobCodec",
+		Version:      "2.4.0",
+		DefaultValue: "none",
+		Doc:          "The compression of uploaded delta and stats logs, none, zstd or gzip. Insert binlogs are never compressed.",
+	}
+	p.SyncBlobCodec.Init(base.mgr)
+
+	p.SyncBlobChecksum = ParamItem{
+		Key:          "dataNode.dataSync.blobChecksum",
+		Version:      "2.4.0",
+		DefaultValue: "false",
+		Doc:          "Whether to upload the CRC32C of each sync blob as a sidecar object, which is verified when the blob is read by any node.",
+	}
+	p.SyncBlobChecksum.Init(base.mgr)
+
+	p.SyncSerializeParallelism = ParamItem{
+		Key:          "dataNode.dataSync.serializeParallelism",
+		Version:      "2.4.0",
+		DefaultValue: "1",
+		Doc:          "The max number of columns serialized concurrently by a sync task, 1 means sequential serialization.",
+	}
+	p.SyncSerializeParallelism.Init(base.mgr)
+
+	p.SyncTaskDedup = ParamItem{
+		Key:          "dataNode.dataSync.taskDedup",
+		Version:      "2.4.0",
+		DefaultValue: "false",
+		Doc:          "Whether a sync task re-submitted with the same segment and checkpoint as an in-flight one shares its result instead of running again.",
+	}
+	p.SyncTaskDedup.Init(base.mgr)
+
+	p.SyncDrainTimeout = ParamItem{
+		Key:          "dataNode.dataSync.drainTimeout",
+		Version:      "2.4.0",
+		DefaultValue: "30",
+		Doc:          "Timeout in seconds to wait for the in-flight sync tasks to finish when datanode stops.",
+	}
+	p.SyncDrainTimeout.Init(base.mgr)
+
 	p.FileReadConcurrency = ParamItem{
 		Key:          "dataNode.multiRead.concurrency",
 		Version:      "2.0.0",
@@ -2838,6 +2908,15 @@ func (p *dataNodeConfig) init(base *BaseTable) {
 		DefaultValue: "1000",
 	}
 	p.UpdateChannelCheckpointMaxParallel.Init(base.mgr)
+
+	p.CloseFlushTimeout = ParamItem{
+		Key:          "datanode.channel.closeFlushTimeout",
+		Version:      "2.4.0",
+		PanicIfEmpty: false,
+		DefaultValue: "10000",
+		Doc:          "Timeout in milliseconds to wait for the final channel checkpoint persisted when the flowgraph closes",
+	}
+	p.CloseFlushTimeout.Init(base.mgr)
 }
 
 // /////////////////////////////////////////////////////////////////////////////
@@ -391,6 +391,13 @@ func TestComponentParam(t *testing.T) {
 		period := &Params.SyncPeriod
 		t.Logf("SyncPeriod: %v", period)
 		assert.Equal(t, 10*time.Minute, Params.SyncPeriod.GetAsDuration(time.Second))
+		assert.Equal(t, time.Duration(0), Params.ScheduledFlushInterval.GetAsDuration(time.Second))
+		assert.Equal(t, int64(0), Params.MultipartUploadThreshold.GetAsInt64())
+		assert.Equal(t, "none", Params.SyncBlobCodec.GetValue())
+		assert.False(t, Params.SyncBlobChecksum.GetAsBool())
+		assert.Equal(t, 1, Params.SyncSerializeParallelism.GetAsInt())
+		assert.False(t, Params.SyncTaskDedup.GetAsBool())
+		assert.Equal(t, 30*time.Second, Params.SyncDrainTimeout.GetAsDuration(time.Second))
 
 		bulkinsertTimeout := &Params.BulkInsertTimeoutSeconds
 		t.Logf("BulkInsertTimeoutSeconds: %v", bulkinsertTimeout)
@@ -403,6 +410,8 @@ func TestComponentParam(t *testing.T) {
 		updateChannelCheckpointMaxParallel := Params.UpdateChannelCheckpointMaxParallel.GetAsInt()
 		t.Logf("updateChannelCheckpointMaxParallel: %d", updateChannelCheckpointMaxParallel)
 		assert.Equal(t, 1000, Params.UpdateChannelCheckpointMaxParallel.GetAsInt())
+
+		assert.Equal(t, 10*time.Second, Params.CloseFlushTimeout.GetAsDuration(time.Millisecond))
 	})
 
 	t.Run("test indexNodeConfig", func(t *testing.T) {